@@ -0,0 +1,52 @@
+// kernel/api/audit.go
+package handlers
+
+import (
+	"net/http"
+
+	"neuroedge/kernel/core"
+)
+
+// auditCommand records a dispatched /execute command in the kernel audit
+// log. Non-200 outcomes count as blocked, with the response's error as reason.
+func auditCommand(requestID string, cmd kernelCommand, resp kernelResponse, status int) {
+	blocked := ""
+	if status != http.StatusOK {
+		blocked = resp.Stderr
+	}
+	auditCommandOutcome(requestID, cmd, status == http.StatusOK && resp.Success, blocked)
+}
+
+// auditCommandOutcome records a command with an explicit outcome, for
+// commands refused before they were dispatched. The action is taken from
+// the redacted payload so a redacted "code" or "command" field doesn't leak
+// through it.
+func auditCommandOutcome(requestID string, cmd kernelCommand, success bool, blockedReason string) {
+	log := core.Audit()
+	payload := log.Redact(cmd.Payload)
+	entry := core.AuditEntry{
+		RequestID:     requestID,
+		Type:          normalizeType(cmd.Type),
+		Action:        auditAction(payload),
+		Success:       success,
+		BlockedReason: blockedReason,
+		Payload:       payload,
+	}
+	log.Record(entry)
+}
+
+// auditAction is the action extractAction would find, or "" when none.
+func auditAction(payload map[string]interface{}) string {
+	action, _ := extractAction(payload)
+	return action
+}
+
+// AuditHandler returns recent audit entries, newest first (?limit=, default 100).
+func AuditHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r.URL.Query().Get("limit"), 100)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid limit: must be a non-negative integer")
+		return
+	}
+	writeJSON(w, core.Audit().Recent(limit))
+}
@@ -2,32 +2,201 @@
 package handlers
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
+// API key scopes, each including the ones before it.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var scopeRank = map[string]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+type authContextKey struct{}
+
+// authInfo is what withAPIKeyAuth learned about the caller.
+type authInfo struct {
+	KeyID string
+	Scope string
+}
+
+// ScopeFromContext returns the scope of the API key that authenticated the
+// request, or "" when the request was not authenticated.
+func ScopeFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(authContextKey{}).(authInfo)
+	return info.Scope
+}
+
+// hashedKey is one entry from NEUROEDGE_API_KEYS_FILE.
+type hashedKey struct {
+	ID    string
+	Scope string
+	Hash  []byte
+}
+
+// hashAPIKey is the stored form of a key: sha256 over "keyID:key", so the
+// key ID salts the hash. Equivalent to `printf '%s:%s' ID KEY | sha256sum`.
+func hashAPIKey(id, key string) []byte {
+	sum := sha256.Sum256([]byte(id + ":" + key))
+	return sum[:]
+}
+
+var (
+	keyFileMu      sync.Mutex
+	keyFilePath    string
+	keyFileModTime time.Time
+	keyFileEntries []hashedKey
+)
+
+// fileAPIKeys loads NEUROEDGE_API_KEYS_FILE, one "keyID:scope:sha256hex" per
+// line with blank lines and # comments ignored. The parsed file is cached
+// until its modification time changes, so keys can be rotated in place.
+func fileAPIKeys() []hashedKey {
+	path := strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEYS_FILE"))
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("⚠️ API keys file: %v", err)
+		return nil
+	}
+
+	keyFileMu.Lock()
+	defer keyFileMu.Unlock()
+	if path == keyFilePath && info.ModTime().Equal(keyFileModTime) {
+		return keyFileEntries
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Printf("⚠️ API keys file: %v", err)
+		return nil
+	}
+	defer f.Close()
+
+	var entries []hashedKey
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Split(text, ":")
+		if len(parts) != 3 {
+			log.Printf("⚠️ API keys file line %d: want keyID:scope:sha256", line)
+			continue
+		}
+		id, scope := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		hash, err := hex.DecodeString(strings.TrimSpace(parts[2]))
+		if id == "" || scopeRank[scope] == 0 || err != nil || len(hash) != sha256.Size {
+			log.Printf("⚠️ API keys file line %d: invalid entry for key %q", line, id)
+			continue
+		}
+		entries = append(entries, hashedKey{ID: id, Scope: scope, Hash: hash})
+	}
+	keyFilePath, keyFileModTime, keyFileEntries = path, info.ModTime(), entries
+	return entries
+}
+
+// authConfigured reports whether any API key, plain or hashed, is set up.
+func authConfigured() bool {
+	return len(configuredAPIKeys()) > 0 || len(fileAPIKeys()) > 0
+}
+
+// resolveAPIKey checks got against the hashed keys file and the plaintext
+// env keys, which carry admin scope for backward compatibility. Every
+// candidate is compared so timing doesn't reveal which one matched.
+func resolveAPIKey(got string) (authInfo, bool) {
+	var info authInfo
+	found := 0
+	for _, k := range fileAPIKeys() {
+		if subtle.ConstantTimeCompare(hashAPIKey(k.ID, got), k.Hash) == 1 {
+			info, found = authInfo{KeyID: k.ID, Scope: k.Scope}, 1
+		}
+	}
+	if found == 0 && apiKeyMatches(got, configuredAPIKeys()) {
+		sum := sha256.Sum256([]byte(got))
+		info, found = authInfo{KeyID: "env-" + hex.EncodeToString(sum[:4]), Scope: ScopeAdmin}, 1
+	}
+	return info, found == 1
+}
+
+// configuredAPIKeys returns NEUROEDGE_API_KEY plus any keys in the
+// comma-separated NEUROEDGE_API_KEYS, so keys can be rotated without a cutover.
+func configuredAPIKeys() []string {
+	keys := []string{}
+	if k := strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEY")); k != "" {
+		keys = append(keys, k)
+	}
+	for _, k := range strings.Split(os.Getenv("NEUROEDGE_API_KEYS"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// apiKeyMatches compares got against every configured key in constant time.
+func apiKeyMatches(got string, keys []string) bool {
+	matched := 0
+	for _, k := range keys {
+		matched |= subtle.ConstantTimeCompare([]byte(got), []byte(k))
+	}
+	return matched == 1
+}
+
+// presentedAPIKey returns the key from X-API-Key or an Authorization bearer
+// token, without checking it.
+func presentedAPIKey(r *http.Request) string {
+	if got := strings.TrimSpace(r.Header.Get("X-API-Key")); got != "" {
+		return got
+	}
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return ""
+}
+
 func withAPIKeyAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		expected := strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEY"))
-		if expected == "" {
-			http.Error(w, "server auth not configured", http.StatusServiceUnavailable)
+		if !authConfigured() {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "server auth not configured")
 			return
 		}
 
-		got := strings.TrimSpace(r.Header.Get("X-API-Key"))
-		if got == "" {
-			auth := strings.TrimSpace(r.Header.Get("Authorization"))
-			if strings.HasPrefix(auth, "Bearer ") {
-				got = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
-			}
+		got := presentedAPIKey(r)
+		info, ok := resolveAPIKey(got)
+		if got == "" || !ok {
+			writeError(w, r, ErrCodeUnauthorized, http.StatusUnauthorized)
+			return
 		}
 
-		if got == "" || got != expected {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		next(w, r.WithContext(context.WithValue(r.Context(), authContextKey{}, info)))
+	}
+}
+
+// requireScope rejects requests whose key scope ranks below scope. It must
+// run inside withAPIKeyAuth.
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if scopeRank[ScopeFromContext(r.Context())] < scopeRank[scope] {
+			writeErrorf(w, r, ErrCodeForbidden, http.StatusForbidden, "insufficient scope: %s required", scope)
 			return
 		}
-
 		next(w, r)
 	}
 }
@@ -0,0 +1,149 @@
+// kernel/api/batch.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/core"
+)
+
+// ExecuteBatchHandler runs an array of commands and returns their responses
+// in input order. Each entry is checked, dispatched and audited like a
+// synchronous /execute, and its action must also pass the agent guard
+// (ethics and cognition); one that is refused or fails gets success false
+// and its own stderr without affecting the others. Batches larger than
+// NEUROEDGE_BATCH_MAX (default 20) are rejected with 400.
+//
+// NEUROEDGE_BATCH_WORKERS (default 4) commands run at once. The request's
+// own concurrency slot covers the first worker; the others each take an
+// extra slot per command and wait for one while the limiter is full, so a
+// batch never runs more commands than the limiter allows.
+func ExecuteBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var cmds []kernelCommand
+	if !decodeJSONBody(w, r, &cmds) {
+		return
+	}
+	if len(cmds) == 0 {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "batch is empty")
+		return
+	}
+	if limit := readIntEnv("NEUROEDGE_BATCH_MAX", 20); len(cmds) > limit {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "batch has %d commands, limit is %d", len(cmds), limit)
+		return
+	}
+
+	requestID := requestIDFor(w, r)
+	stamp := time.Now().UnixNano()
+	for i := range cmds {
+		if strings.TrimSpace(cmds[i].ID) == "" {
+			cmds[i].ID = fmt.Sprintf("kernel-%d-%d", stamp, i)
+		}
+		if cmds[i].Payload == nil {
+			cmds[i].Payload = map[string]interface{}{}
+		}
+	}
+
+	workers := readIntEnv("NEUROEDGE_BATCH_WORKERS", 4)
+	if workers > len(cmds) {
+		workers = len(cmds)
+	}
+	limiter := ensureConcurrency()
+	reserve := priorityReserve(r, int64(readIntEnv("NEUROEDGE_RESERVED_SLOTS", 0)))
+
+	out := make([]kernelResponse, len(cmds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func(ownSlot bool) {
+			defer wg.Done()
+			for i := range indexes {
+				if !ownSlot && !acquireSlot(r.Context(), limiter, reserve) {
+					out[i] = batchFailure(cmds[i], "request cancelled")
+					continue
+				}
+				out[i] = runBatchCommand(r.Context(), requestID, cmds[i])
+				if !ownSlot {
+					limiter.release()
+				}
+			}
+		}(n == 0)
+	}
+	for i := range cmds {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	writeJSON(w, out)
+}
+
+// acquireSlot waits for a limiter slot until ctx ends.
+func acquireSlot(ctx context.Context, limiter *slotLimiter, reserve int64) bool {
+	for {
+		ok, freed := limiter.acquireOrNotify(reserve)
+		if ok {
+			return true
+		}
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// runBatchCommand is executeCommand for one batch entry or WebSocket frame,
+// reporting every outcome in the returned response instead of the HTTP
+// status.
+func runBatchCommand(ctx context.Context, requestID string, cmd kernelCommand) kernelResponse {
+	refuse := func(reason string) kernelResponse {
+		auditCommandOutcome(requestID, cmd, false, reason)
+		return batchFailure(cmd, reason)
+	}
+	if err := validateCommand(cmd); err != nil {
+		return refuse(err.Error())
+	}
+	if err := checkEngineAllowed(cmd); err != nil {
+		return refuse(err.Error())
+	}
+	action, err := extractAction(cmd.Payload)
+	if err != nil {
+		return refuse(err.Error())
+	}
+	if ok, reason := core.CheckTask(commandAgent(cmd), action); !ok {
+		return refuse("blocked by agent guard: " + reason)
+	}
+	if orchestratorFallback() != fallbackOff {
+		if _, down := orchestratorDown(); down {
+			return refuse(circuitOpenMessage)
+		}
+	}
+
+	resp, status := dispatchCommand(ctx, cmd, action)
+	auditCommand(requestID, cmd, resp, status)
+	return resp
+}
+
+// commandAgent names the agent a command runs for in the guard: its
+// metadata.agent, or "api" for commands that don't say.
+func commandAgent(cmd kernelCommand) string {
+	if agent, _ := cmd.Metadata["agent"].(string); strings.TrimSpace(agent) != "" {
+		return strings.TrimSpace(agent)
+	}
+	return "api"
+}
+
+func batchFailure(cmd kernelCommand, reason string) kernelResponse {
+	return kernelResponse{
+		ID:        cmd.ID,
+		Success:   false,
+		Stderr:    reason,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
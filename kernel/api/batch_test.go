@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"neuroedge/kernel/core"
+)
+
+func TestRunBatchCommandAppliesAgentGuard(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_FALLBACK", "off")
+	t.Setenv("NEUROEDGE_ETHICS_DENY_PATTERNS", "wipe the cluster")
+	useOrchestratorClient(t, &fakeStreamer{})
+
+	blocked := runBatchCommand(context.Background(), "req-batch-guard", kernelCommand{
+		ID:       "batch-guard-blocked",
+		Type:     "chat",
+		Payload:  map[string]interface{}{"message": "please wipe the cluster"},
+		Metadata: map[string]interface{}{"agent": "planner"},
+	})
+	if blocked.Success || !strings.Contains(blocked.Stderr, "blocked by agent guard: ethics") {
+		t.Fatalf("blocked entry = %+v, want a guard refusal in stderr", blocked)
+	}
+
+	allowed := runBatchCommand(context.Background(), "req-batch-guard", kernelCommand{
+		ID:      "batch-guard-allowed",
+		Type:    "chat",
+		Payload: map[string]interface{}{"message": "summarise the logs"},
+	})
+	if !allowed.Success {
+		t.Fatalf("allowed entry = %+v, want success", allowed)
+	}
+
+	var found bool
+	for _, e := range core.Audit().Recent(50) {
+		if e.RequestID == "req-batch-guard" && !e.Success {
+			found = true
+			if e.BlockedReason != blocked.Stderr {
+				t.Fatalf("audited reason = %q, want %q", e.BlockedReason, blocked.Stderr)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("guard refusal was not audited")
+	}
+}
+
+func TestCommandAgent(t *testing.T) {
+	tests := []struct {
+		metadata map[string]interface{}
+		want     string
+	}{
+		{metadata: nil, want: "api"},
+		{metadata: map[string]interface{}{"agent": "  "}, want: "api"},
+		{metadata: map[string]interface{}{"agent": 7}, want: "api"},
+		{metadata: map[string]interface{}{"agent": " planner "}, want: "planner"},
+	}
+	for _, tt := range tests {
+		if got := commandAgent(kernelCommand{Metadata: tt.metadata}); got != tt.want {
+			t.Errorf("commandAgent(%v) = %q, want %q", tt.metadata, got, tt.want)
+		}
+	}
+}
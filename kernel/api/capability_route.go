@@ -0,0 +1,118 @@
+// kernel/api/capability_route.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"neuroedge/kernel/discovery"
+	"neuroedge/kernel/mesh"
+)
+
+type capabilityRouteRequest struct {
+	Capability string `json:"capability"`
+	Message    string `json:"message"`
+}
+
+// CapabilityRoute is the /kernel/route response.
+type CapabilityRoute struct {
+	NodeID     string `json:"node_id"`
+	Capability string `json:"capability"`
+	Delivered  bool   `json:"delivered"`
+	// Outcome is what the node's inbox did with the message, with ?deliver=true.
+	Outcome mesh.MessageOutcome `json:"outcome,omitempty"`
+}
+
+var (
+	capabilityRoutingOnce sync.Once
+	capabilityRouting     *mesh.Routing
+)
+
+// ensureCapabilityRouting is the rotation used to pick among registered
+// nodes when the mesh is disabled.
+func ensureCapabilityRouting() *mesh.Routing {
+	capabilityRoutingOnce.Do(func() {
+		capabilityRouting = mesh.NewRouting()
+	})
+	return capabilityRouting
+}
+
+// CapabilityRouteHandler resolves which node should handle a capability:
+// one of the active nodes advertising it, registered through
+// /kernel/nodes/register or known to the mesh, picked by the mesh's
+// weighted rotation. 404 means no such node is active. With ?deliver=true
+// the message is also routed into the node's mesh inbox, which needs the
+// mesh and considers mesh nodes only.
+func CapabilityRouteHandler(w http.ResponseWriter, r *http.Request) {
+	var req capabilityRouteRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.Capability = strings.TrimSpace(req.Capability)
+	if req.Capability == "" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "capability required")
+		return
+	}
+	deliver := r.URL.Query().Get("deliver") == "true"
+
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+
+	if deliver {
+		if m == nil {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "mesh not enabled")
+			return
+		}
+		node, outcome := m.Delivery.DeliverByCapability(m.Registry.ActiveNodes(), req.Capability, req.Message)
+		if node == nil {
+			writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "no active node with capability %q", req.Capability)
+			return
+		}
+		writeJSON(w, CapabilityRoute{NodeID: node.ID, Capability: req.Capability, Delivered: outcome.Stored(), Outcome: outcome})
+		return
+	}
+
+	candidates, err := capabilityCandidates(m, req.Capability)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "%v", err)
+		return
+	}
+	routing := ensureCapabilityRouting()
+	if m != nil {
+		routing = m.Routing
+	}
+	node := routing.SelectBalanced(candidates)
+	if node == nil {
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "no active node with capability %q", req.Capability)
+		return
+	}
+	writeJSON(w, CapabilityRoute{NodeID: node.ID, Capability: req.Capability})
+}
+
+// capabilityCandidates returns the active mesh nodes advertising capability
+// plus the registered ones, the mesh's own node winning when both know an ID.
+func capabilityCandidates(m *mesh.MeshManager, capability string) ([]*mesh.Node, error) {
+	registered, err := discovery.NodesWithCapability(capability)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*mesh.Node
+	seen := map[string]bool{}
+	if m != nil {
+		for _, node := range mesh.WithCapability(m.Registry.ActiveNodes(), capability) {
+			candidates = append(candidates, node)
+			seen[node.ID] = true
+		}
+	}
+	for _, kn := range registered {
+		if seen[kn.ID] {
+			continue
+		}
+		node := mesh.NewNode(kn.ID, kn.Address)
+		node.Capabilities = kn.Capabilities
+		candidates = append(candidates, node)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,19 @@
+// kernel/api/cognition.go
+package handlers
+
+import (
+	"net/http"
+
+	"neuroedge/kernel/core"
+)
+
+// CognitionHistoryHandler returns the guard's recent cognition decisions,
+// newest first (?limit=, default 100), for reviewing borderline rejections.
+func CognitionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r.URL.Query().Get("limit"), 100)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid limit: must be a non-negative integer")
+		return
+	}
+	writeJSON(w, core.CognitionHistory(limit))
+}
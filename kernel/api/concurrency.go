@@ -0,0 +1,210 @@
+// kernel/api/concurrency.go
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// slotLimiter is a resizable counting semaphore. Resizing only moves the
+// ceiling: when the limit shrinks below the current inflight count, requests
+// already running are left to finish and new ones are rejected until inflight
+// drops under the new limit. Nothing is cancelled or re-queued.
+type slotLimiter struct {
+	mu       sync.Mutex
+	limit    int64
+	inflight int64
+	// freed is closed and replaced whenever a slot may have opened up, waking
+	// every queued request to retry.
+	freed chan struct{}
+}
+
+func newSlotLimiter(limit int) *slotLimiter {
+	return &slotLimiter{limit: int64(limit), freed: make(chan struct{})}
+}
+
+func (l *slotLimiter) tryAcquire() bool {
+	return l.tryAcquireWithReserve(0)
+}
+
+// tryAcquireWithReserve takes a slot only if doing so leaves at least reserve
+// slots free, keeping headroom for higher-priority callers.
+func (l *slotLimiter) tryAcquireWithReserve(reserve int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight >= l.limit-reserve {
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+// acquireOrNotify is tryAcquireWithReserve that, on failure, also returns a
+// channel closed the next time a slot may free up. Taking both under one
+// lock means a release between the attempt and the wait is never missed.
+func (l *slotLimiter) acquireOrNotify(reserve int64) (bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight >= l.limit-reserve {
+		return false, l.freed
+	}
+	l.inflight++
+	return true, nil
+}
+
+func (l *slotLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight > 0 {
+		l.inflight--
+	}
+	l.notifyLocked()
+}
+
+func (l *slotLimiter) setLimit(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = int64(n)
+	l.notifyLocked()
+}
+
+func (l *slotLimiter) notifyLocked() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+func (l *slotLimiter) snapshot() ConcurrencySnapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencySnapshot{Current: l.inflight, Limit: l.limit}
+}
+
+// SetConcurrencyLimit changes the inflight ceiling without a restart.
+// See slotLimiter for how in-flight requests are treated during a shrink.
+func SetConcurrencyLimit(n int) error {
+	if n <= 0 {
+		return errors.New("concurrency limit must be positive")
+	}
+	ensureConcurrency().setLimit(n)
+	return nil
+}
+
+// ConcurrencyHandler reads (GET) or sets (POST {"limit": n}) the concurrency limit
+func ConcurrencyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var body struct {
+			Limit int `json:"limit"`
+		}
+		if !decodeJSONBody(w, r, &body) {
+			return
+		}
+		if err := SetConcurrencyLimit(body.Limit); err != nil {
+			writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "%v", err)
+			return
+		}
+	}
+	writeJSON(w, getConcurrencySnapshot())
+}
+
+// requestPriority reports whether r is high priority: health probes always
+// are, otherwise the X-Priority header decides, falling back to
+// metadata.priority in a JSON command body. Only callers that may claim
+// priority (see mayClaimPriority) are asked; for anyone else the header is
+// ignored and the body is not read.
+func requestPriority(r *http.Request) bool {
+	if shouldSkipRequestLog(r.URL.Path) {
+		return true
+	}
+	if !mayClaimPriority(r) {
+		return false
+	}
+	if p := strings.TrimSpace(r.Header.Get("X-Priority")); p != "" {
+		return isHighPriority(p)
+	}
+	if r.Method != http.MethodPost || r.Body == nil || r.ContentLength == 0 {
+		return false
+	}
+	// The body is already bounded by withBodySizeLimit; buffer it so the
+	// handler can still decode it.
+	raw, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return false
+	}
+	var cmd struct {
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if json.Unmarshal(raw, &cmd) != nil {
+		return false
+	}
+	switch p := cmd.Metadata["priority"].(type) {
+	case string:
+		return isHighPriority(p)
+	case float64:
+		return isHighPriority(strconv.Itoa(int(p)))
+	}
+	return false
+}
+
+// mayClaimPriority reports whether r was authenticated by withAPIKeyAuth
+// with an admin key or a key ID listed in the comma-separated
+// NEUROEDGE_PRIORITY_KEYS.
+func mayClaimPriority(r *http.Request) bool {
+	info, ok := r.Context().Value(authContextKey{}).(authInfo)
+	if !ok {
+		return false
+	}
+	if info.Scope == ScopeAdmin {
+		return true
+	}
+	for _, id := range strings.Split(os.Getenv("NEUROEDGE_PRIORITY_KEYS"), ",") {
+		if id = strings.TrimSpace(id); id != "" && id == info.KeyID {
+			return true
+		}
+	}
+	return false
+}
+
+// isHighPriority accepts "high"/"critical" or a numeric priority of 8 and above.
+func isHighPriority(p string) bool {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case "high", "critical", "urgent":
+		return true
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(p))
+	return err == nil && n >= 8
+}
+
+// priorityReserve is how many slots r must leave free: none for
+// high-priority requests, reserved for everything else.
+func priorityReserve(r *http.Request, reserved int64) int64 {
+	if reserved > 0 && requestPriority(r) {
+		return 0
+	}
+	return reserved
+}
+
+// withPriorityConcurrency sheds low-priority load first. The last
+// NEUROEDGE_RESERVED_SLOTS slots are only handed to high-priority requests,
+// so control-plane traffic keeps flowing while ordinary requests get 503.
+// On the unauthenticated public routes only health probes are high priority.
+func withPriorityConcurrency(next http.HandlerFunc) http.HandlerFunc {
+	limiter := ensureConcurrency()
+	reserved := int64(readIntEnv("NEUROEDGE_RESERVED_SLOTS", 0))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.tryAcquireWithReserve(priorityReserve(r, reserved)) {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, ErrCodeOverloaded, http.StatusServiceUnavailable)
+			return
+		}
+		defer limiter.release()
+		next(w, r)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readSpy records whether anything read the request body.
+type readSpy struct {
+	io.Reader
+	read bool
+}
+
+func (s *readSpy) Read(p []byte) (int, error) {
+	s.read = true
+	return s.Reader.Read(p)
+}
+
+func priorityRequest(info *authInfo, header, body string) (*http.Request, *readSpy) {
+	spy := &readSpy{Reader: strings.NewReader(body)}
+	r := httptest.NewRequest(http.MethodPost, "/v1/execute", spy)
+	if header != "" {
+		r.Header.Set("X-Priority", header)
+	}
+	if info != nil {
+		r = r.WithContext(context.WithValue(r.Context(), authContextKey{}, *info))
+	}
+	return r, spy
+}
+
+func TestRequestPriorityIgnoresUnauthenticatedClaims(t *testing.T) {
+	r, spy := priorityRequest(nil, "high", `{"metadata":{"priority":"critical"}}`)
+	if requestPriority(r) {
+		t.Fatal("unauthenticated X-Priority was honoured")
+	}
+
+	r, spy = priorityRequest(nil, "", `{"metadata":{"priority":"critical"}}`)
+	if requestPriority(r) {
+		t.Fatal("unauthenticated metadata.priority was honoured")
+	}
+	if spy.read {
+		t.Fatal("body was read for an unauthenticated request")
+	}
+}
+
+func TestRequestPriorityByScope(t *testing.T) {
+	admin := &authInfo{KeyID: "ops", Scope: ScopeAdmin}
+	writer := &authInfo{KeyID: "app", Scope: ScopeWrite}
+
+	tests := []struct {
+		name      string
+		info      *authInfo
+		allowlist string
+		header    string
+		body      string
+		want      bool
+		// bodyUnread: a caller that may not claim priority has its body left alone.
+		bodyUnread bool
+	}{
+		{name: "admin header", info: admin, header: "high", want: true},
+		{name: "admin low header", info: admin, header: "low", want: false},
+		{name: "admin body", info: admin, body: `{"metadata":{"priority":9}}`, want: true},
+		{name: "write key header", info: writer, header: "high", want: false},
+		{name: "write key body", info: writer, body: `{"metadata":{"priority":"high"}}`, want: false, bodyUnread: true},
+		{name: "allowlisted write key", info: writer, allowlist: "other, app", header: "urgent", want: true},
+		{name: "allowlisted write key body", info: writer, allowlist: "app", body: `{"metadata":{"priority":"high"}}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEUROEDGE_PRIORITY_KEYS", tt.allowlist)
+			r, spy := priorityRequest(tt.info, tt.header, tt.body)
+			if got := requestPriority(r); got != tt.want {
+				t.Fatalf("requestPriority = %v, want %v", got, tt.want)
+			}
+			if tt.bodyUnread && spy.read {
+				t.Fatal("body was read for a caller that may not claim priority")
+			}
+			if tt.body != "" {
+				rest, _ := io.ReadAll(r.Body)
+				if string(rest) != tt.body {
+					t.Fatalf("handler sees body %q, want %q", rest, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestPriorityHealthProbes(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	if !requestPriority(r) {
+		t.Fatal("health probe is not high priority")
+	}
+}
@@ -0,0 +1,90 @@
+// kernel/api/errors.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable error identifier. Clients branch on
+// the code; the message is for humans and may change.
+type ErrorCode string
+
+const (
+	ErrCodeInvalidJSON     ErrorCode = "INVALID_JSON"
+	ErrCodeBodyTooLarge    ErrorCode = "BODY_TOO_LARGE"
+	ErrCodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	ErrCodeUnauthorized    ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden       ErrorCode = "FORBIDDEN"
+	ErrCodeNotFound        ErrorCode = "NOT_FOUND"
+	ErrCodeConflict        ErrorCode = "CONFLICT"
+	ErrCodeRateLimited     ErrorCode = "RATE_LIMITED"
+	ErrCodeOverloaded      ErrorCode = "OVERLOADED"
+	ErrCodeTimeout         ErrorCode = "TIMEOUT"
+	ErrCodeUnavailable     ErrorCode = "UNAVAILABLE"
+	ErrCodeUpstream        ErrorCode = "UPSTREAM_ERROR"
+	ErrCodeNotImplemented  ErrorCode = "NOT_IMPLEMENTED"
+	ErrCodeInternal        ErrorCode = "INTERNAL"
+
+	// Codes below are lowercase as published to clients; don't normalize them.
+
+	// ErrCodeOrchestratorUnavailable means the orchestrator is temporarily
+	// down (its breaker is open), not that the request was wrong.
+	ErrCodeOrchestratorUnavailable ErrorCode = "orchestrator_unavailable"
+	// ErrCodeEngineNotAllowed means payload.engine is not in
+	// NEUROEDGE_ALLOWED_ENGINES.
+	ErrCodeEngineNotAllowed ErrorCode = "engine_not_allowed"
+	// ErrCodeMaintenance means the kernel is in maintenance mode and refuses
+	// mutating requests until an operator turns it off.
+	ErrCodeMaintenance ErrorCode = "maintenance"
+)
+
+var defaultErrorMessages = map[ErrorCode]string{
+	ErrCodeInvalidJSON:     "invalid json",
+	ErrCodeBodyTooLarge:    "request body too large",
+	ErrCodeInvalidArgument: "invalid argument",
+	ErrCodeUnauthorized:    "unauthorized",
+	ErrCodeForbidden:       "forbidden",
+	ErrCodeNotFound:        "not found",
+	ErrCodeConflict:        "conflict",
+	ErrCodeRateLimited:     "rate limit exceeded",
+	ErrCodeOverloaded:      "service overloaded",
+	ErrCodeTimeout:         "request timed out",
+	ErrCodeUnavailable:     "service unavailable",
+	ErrCodeUpstream:        "orchestrator error",
+	ErrCodeNotImplemented:  "not implemented",
+	ErrCodeInternal:        "internal server error",
+
+	ErrCodeOrchestratorUnavailable: "orchestrator temporarily unavailable",
+	ErrCodeEngineNotAllowed:        "engine not allowed",
+	ErrCodeMaintenance:             "kernel in maintenance mode",
+}
+
+type errorBody struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+// writeError answers with the JSON error envelope and the code's default
+// message.
+func writeError(w http.ResponseWriter, r *http.Request, code ErrorCode, status int) {
+	writeErrorf(w, r, code, status, "%s", defaultErrorMessages[code])
+}
+
+// writeErrorf is writeError with a specific message.
+func writeErrorf(w http.ResponseWriter, r *http.Request, code ErrorCode, status int, format string, args ...interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorEnvelope{Error: errorBody{
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		RequestID: requestIDFor(w, r),
+	}})
+}
@@ -0,0 +1,110 @@
+// kernel/api/events.go
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"neuroedge/kernel/core"
+	"neuroedge/kernel/types"
+)
+
+// eventStreamBuffer is how many events a slow /kernel/events/stream client
+// may fall behind before further events are dropped for it.
+const eventStreamBuffer = 256
+
+// streamedEvent is the SSE data of one relayed types.Event.
+type streamedEvent struct {
+	ID     string      `json:"id,omitempty"`
+	Name   string      `json:"name"`
+	Source string      `json:"source"`
+	Data   interface{} `json:"data"`
+}
+
+// EventsStreamHandler relays events published on the kernel EventBus as
+// Server-Sent Events named after the event. ?topic= takes an event name or
+// a "prefix*" pattern (see types.EventBus); without it every event is sent.
+// Data is redacted like the access log. Publishers never wait on the
+// client: once eventStreamBuffer events are pending, newer ones are dropped
+// and a "dropped" event reports how many.
+func EventsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	topic := strings.TrimSpace(r.URL.Query().Get("topic"))
+	if topic == "" {
+		topic = "*"
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	events := make(chan types.Event, eventStreamBuffer)
+	var pendingDrops atomic.Int64
+	dropNotify := make(chan struct{}, 1)
+	sub := core.GlobalEventBus.Subscribe(topic, func(evt types.Event) {
+		select {
+		case events <- evt:
+		default:
+			pendingDrops.Add(1)
+			select {
+			case dropNotify <- struct{}{}:
+			default:
+			}
+		}
+	})
+	defer core.GlobalEventBus.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	var dropped int64
+	keepAlive := time.NewTicker(nodeWatchKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = w.Write([]byte(": keep-alive\n\n"))
+		case <-dropNotify:
+			n := pendingDrops.Swap(0)
+			dropped += n
+			writeSSE(w, "dropped", fmt.Sprintf(`{"count":%d,"total":%d}`, n, dropped))
+		case evt := <-events:
+			writeSSE(w, sseEventName(evt.Name), string(marshalStreamedEvent(evt)))
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// marshalStreamedEvent encodes evt with its data redacted. Data that can't
+// be encoded as JSON is sent as its %v string.
+func marshalStreamedEvent(evt types.Event) []byte {
+	out := streamedEvent{ID: evt.ID, Name: evt.Name, Source: evt.Source, Data: evt.Data}
+	if raw, err := json.Marshal(evt.Data); err != nil {
+		out.Data = fmt.Sprintf("%v", evt.Data)
+	} else {
+		var v interface{}
+		if json.Unmarshal(raw, &v) == nil {
+			out.Data = core.LogRedactor().RedactValue(v)
+		}
+	}
+	data, _ := json.Marshal(out)
+	return data
+}
+
+// sseEventName keeps an event name from breaking SSE framing.
+func sseEventName(name string) string {
+	name = strings.NewReplacer("\r", " ", "\n", " ").Replace(name)
+	if name == "" {
+		return "message"
+	}
+	return name
+}
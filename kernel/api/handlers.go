@@ -1,49 +1,322 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"neuroedge/kernel/core"
 	"neuroedge/kernel/discovery"
+	"neuroedge/kernel/engines"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
 	"neuroedge/kernel/types"
 )
 
-// HealthHandler returns JSON of all component health
+var (
+	optimizerMu      sync.RWMutex
+	computeOptimizer *engines.NeuroComputeOptimizer
+	scalerEngine     *engines.ScalerEngine
+
+	orchestratorMu     sync.RWMutex
+	orchestratorClient pb.OrchestratorClient
+)
+
+// SetOrchestratorClient injects the client ExecuteHandler forwards commands to.
+// Called once from main; the connection is shared across requests.
+func SetOrchestratorClient(c pb.OrchestratorClient) {
+	orchestratorMu.Lock()
+	defer orchestratorMu.Unlock()
+	orchestratorClient = c
+}
+
+func getOrchestratorClient() pb.OrchestratorClient {
+	orchestratorMu.RLock()
+	defer orchestratorMu.RUnlock()
+	return orchestratorClient
+}
+
+// backendSubmitter is implemented by multi-backend clients that can report
+// which orchestrator replica served a task.
+type backendSubmitter interface {
+	SubmitTaskBackend(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, string, error)
+}
+
+// orchestratorStatus summarizes the orchestrator client for /health/details.
+// Clients that don't report a mode or breaker state just show as configured.
+func orchestratorStatus() map[string]any {
+	client := getOrchestratorClient()
+	status := map[string]any{"configured": client != nil}
+	if m, ok := client.(interface{ Mode() string }); ok {
+		status["mode"] = m.Mode()
+	}
+	if b, ok := client.(interface{ BreakerState() string }); ok {
+		status["breaker"] = b.BreakerState()
+	}
+	if p, ok := client.(interface{ Backends() []core.BackendStatus }); ok {
+		status["backends"] = p.Backends()
+	}
+	return status
+}
+
+const orchestratorPingTimeout = 3 * time.Second
+
+// PingOrchestrator checks the orchestrator client can reach its service.
+// Clients without a Ping method are only required to be configured.
+func PingOrchestrator(ctx context.Context) error {
+	client := getOrchestratorClient()
+	if client == nil {
+		return errors.New("no orchestrator client configured")
+	}
+	p, ok := client.(interface{ Ping(context.Context) error })
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, orchestratorPingTimeout)
+	defer cancel()
+	return p.Ping(ctx)
+}
+
+// RegisterComputeOptimizer exposes the running optimizer to the API.
+// Called from main after engines are registered.
+func RegisterComputeOptimizer(o *engines.NeuroComputeOptimizer) {
+	optimizerMu.Lock()
+	defer optimizerMu.Unlock()
+	computeOptimizer = o
+}
+
+// RegisterScalerEngine exposes the running scaler to the API.
+// Called from main after engines are registered.
+func RegisterScalerEngine(s *engines.ScalerEngine) {
+	optimizerMu.Lock()
+	defer optimizerMu.Unlock()
+	scalerEngine = s
+}
+
+// HealthHandler returns JSON of all component health. The worst-of overall
+// status goes in the X-Kernel-Status header so the array body stays as it
+// was. Unhealthy replies 503 so load balancers drain the kernel; degraded
+// and unknown (no check run yet) still reply 200.
+//
+// ?fields=summary trims the reply to the overall status, the healthy flag
+// and the names of unhealthy components, for high-frequency probes. ?component= returns
+// that component alone, with 503 if it is unhealthy and 404 if there is no
+// such component.
 func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	fields := strings.TrimSpace(q.Get("fields"))
+	component := strings.TrimSpace(q.Get("component"))
+	if fields != "" && fields != "summary" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid fields: only \"summary\" is supported")
+		return
+	}
+	if fields != "" && component != "" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "fields and component cannot be combined")
+		return
+	}
+
 	hm := core.GlobalHealthManager
 	statuses := hm.StatusesSnapshot() // Thread-safe snapshot
 
 	health := []types.KernelHealth{}
+	states := make([]string, 0, len(statuses))
 	for _, s := range statuses {
 		errStr := ""
 		if s.LastError != nil {
 			errStr = s.LastError.Error()
 		}
 		health = append(health, types.KernelHealth{
-			Component: s.Name,
-			Healthy:   s.Healthy,
-			LastCheck: s.LastCheck,
-			Error:     errStr,
+			Component:      s.Name,
+			Status:         s.Status,
+			Healthy:        s.Healthy,
+			LastCheck:      s.LastCheck,
+			LastDurationMs: float64(s.LastDuration.Microseconds()) / 1000,
+			Error:          errStr,
 		})
+		states = append(states, s.Status)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Component < health[j].Component })
+	overall := core.RollupHealth(states)
+	w.Header().Set("X-Kernel-Status", overall)
+
+	if component != "" {
+		for _, h := range health {
+			if h.Component == component {
+				writeHealth(w, h.Status == core.HealthUnhealthy, h)
+				return
+			}
+		}
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "unknown health component %q", component)
+		return
+	}
+
+	unhealthy := overall == core.HealthUnhealthy
+	if fields == "summary" {
+		summary := types.KernelHealthSummary{Status: overall, Healthy: !unhealthy, Unhealthy: []string{}}
+		for _, h := range health {
+			if h.Status == core.HealthUnhealthy {
+				summary.Unhealthy = append(summary.Unhealthy, h.Component)
+			}
+		}
+		writeHealth(w, unhealthy, summary)
+		return
 	}
+	writeHealth(w, unhealthy, health)
+}
+
+// writeHealth writes v, with 503 when unhealthy.
+func writeHealth(w http.ResponseWriter, unhealthy bool, v interface{}) {
+	if unhealthy {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(v)
+		return
+	}
+	writeJSON(w, v)
+}
 
-	writeJSON(w, health)
+// listEnvelope wraps paginated or filtered list responses.
+type listEnvelope struct {
+	Items  interface{} `json:"items"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
 }
 
-// NodesHandler returns all nodes (kernel, agents, engines)
+// NodesHandler returns nodes (kernel, agents, engines), filtered by ?status=
+// and paginated with ?limit= and ?offset=
 func NodesHandler(w http.ResponseWriter, r *http.Request) {
-	nodes := discovery.GetNodes()
-	writeJSON(w, nodes)
+	q := r.URL.Query()
+	limit, err := queryInt(q.Get("limit"), 0)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid limit: must be a non-negative integer")
+		return
+	}
+	offset, err := queryInt(q.Get("offset"), 0)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid offset: must be a non-negative integer")
+		return
+	}
+	status := strings.ToLower(strings.TrimSpace(q.Get("status")))
+	if status != "" && status != "active" && status != "inactive" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid status: must be active or inactive")
+		return
+	}
+
+	nodes := []types.KernelNode{}
+	for _, n := range discovery.GetNodes() {
+		if status == "" || n.Status == status {
+			nodes = append(nodes, n)
+		}
+	}
+
+	total := len(nodes)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	writeJSON(w, listEnvelope{Items: nodes[offset:end], Total: total, Offset: offset})
 }
 
-// CapabilitiesHandler returns all registered agents & engines
+// CapabilitiesHandler returns registered agents & engines, filtered by ?name= substring
 func CapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
 	capabilities := discovery.GetCapabilities()
-	writeJSON(w, capabilities)
+	needle := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("name")))
+	if needle != "" {
+		capabilities.Agents = filterNames(capabilities.Agents, needle)
+		capabilities.Engines = filterNames(capabilities.Engines, needle)
+		for capability := range capabilities.Nodes {
+			if !strings.Contains(strings.ToLower(capability), needle) {
+				delete(capabilities.Nodes, capability)
+			}
+		}
+	}
+	writeJSON(w, listEnvelope{
+		Items:  capabilities,
+		Total:  len(capabilities.Agents) + len(capabilities.Engines),
+		Offset: 0,
+	})
+}
+
+func filterNames(names []string, needle string) []string {
+	out := []string{}
+	for _, n := range names {
+		if strings.Contains(strings.ToLower(n), needle) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// queryInt parses an optional non-negative integer query value.
+func queryInt(raw string, fallback int) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value %q", raw)
+	}
+	return n, nil
+}
+
+// OptimizerRecentHandler returns the optimizer's most recent recommendations (?n=, default 50)
+func OptimizerRecentHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if raw := strings.TrimSpace(r.URL.Query().Get("n")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid n")
+			return
+		}
+		limit = n
+	}
+
+	optimizerMu.RLock()
+	o := computeOptimizer
+	optimizerMu.RUnlock()
+
+	recent := []map[string]interface{}{}
+	if o != nil {
+		recent = o.LastN(limit)
+	}
+	writeJSON(w, recent)
+}
+
+// ScalerRecentHandler returns the scaling actions the scaler applied most
+// recently (?n=, default 50), oldest first.
+func ScalerRecentHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if raw := strings.TrimSpace(r.URL.Query().Get("n")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid n")
+			return
+		}
+		limit = n
+	}
+
+	optimizerMu.RLock()
+	s := scalerEngine
+	optimizerMu.RUnlock()
+
+	recent := []engines.ScalingAction{}
+	if s != nil {
+		recent = s.LastN(limit)
+	}
+	writeJSON(w, recent)
 }
 
 type kernelCommand struct {
@@ -63,13 +336,26 @@ type kernelResponse struct {
 }
 
 // ExecuteHandler accepts orchestrator commands and returns a normalized response.
+// A repeat of the same X-Request-ID (or command id) within
+// NEUROEDGE_IDEMPOTENCY_TTL gets the first response back instead of running
+// the command again.
 func ExecuteHandler(w http.ResponseWriter, r *http.Request) {
 	var cmd kernelCommand
-	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &cmd) {
 		return
 	}
 
+	key := idempotencyKey(r, cmd)
+	if key == "" {
+		executeCommand(w, r, cmd)
+		return
+	}
+	serveIdempotent(w, r, key, commandFingerprint(r, cmd), func(w http.ResponseWriter) {
+		executeCommand(w, r, cmd)
+	})
+}
+
+func executeCommand(w http.ResponseWriter, r *http.Request, cmd kernelCommand) {
 	if strings.TrimSpace(cmd.ID) == "" {
 		cmd.ID = fmt.Sprintf("kernel-%d", time.Now().UnixNano())
 	}
@@ -77,28 +363,243 @@ func ExecuteHandler(w http.ResponseWriter, r *http.Request) {
 		cmd.Payload = map[string]interface{}{}
 	}
 
-	action := extractFirstString(cmd.Payload, "code", "command", "message")
-	if strings.TrimSpace(action) == "" {
+	requestID := requestIDFor(w, r)
+	if err := validateCommand(cmd); err != nil {
+		auditCommandOutcome(requestID, cmd, false, err.Error())
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if err := checkEngineAllowed(cmd); err != nil {
+		auditCommandOutcome(requestID, cmd, false, err.Error())
+		writeErrorf(w, r, ErrCodeEngineNotAllowed, http.StatusForbidden, "%v", err)
+		return
+	}
+	action, err := extractAction(cmd.Payload)
+	if err != nil {
+		auditCommandOutcome(requestID, cmd, false, err.Error())
 		writeJSON(w, kernelResponse{
 			ID:        cmd.ID,
 			Success:   false,
-			Stderr:    "empty payload action",
+			Stderr:    err.Error(),
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 		})
 		return
 	}
 
-	writeJSON(w, kernelResponse{
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+	fallback := orchestratorFallback()
+	// Fail fast with a retryable 503 while the breaker is open, unless the
+	// command can wait in an async job for the orchestrator to come back.
+	if fallback != fallbackOff && !(async && fallback == fallbackQueue) {
+		if wait, down := orchestratorDown(); down {
+			auditCommandOutcome(requestID, cmd, false, circuitOpenMessage)
+			writeOrchestratorUnavailable(w, r, wait)
+			return
+		}
+	}
+
+	if async {
+		j := submitAsyncJob(cmd, action, requestID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", apiVersionPrefix(r)+"/kernel/jobs/"+j.ID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(j)
+		return
+	}
+
+	resp, status := dispatchCommand(r.Context(), cmd, action)
+	auditCommand(requestID, cmd, resp, status)
+	if fallback != fallbackOff && circuitOpen(resp, status) {
+		wait, _ := orchestratorDown()
+		writeOrchestratorUnavailable(w, r, wait)
+		return
+	}
+	if status != http.StatusOK {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// Orchestrator fallback modes, set by NEUROEDGE_ORCHESTRATOR_FALLBACK.
+const (
+	// fallbackOff answers an open breaker like any other orchestrator
+	// failure: a KernelResponse with success false.
+	fallbackOff = "off"
+	// fallbackReject answers with an orchestrator_unavailable error and
+	// Retry-After instead.
+	fallbackReject = "reject"
+	// fallbackQueue rejects like fallbackReject, but ?async=true commands
+	// are accepted and their jobs wait for the breaker to let calls through
+	// again, up to NEUROEDGE_JOB_TIMEOUT_SEC.
+	fallbackQueue = "queue"
+)
+
+const circuitOpenMessage = "orchestrator unavailable: circuit breaker open"
+
+// orchestratorFallback reads NEUROEDGE_ORCHESTRATOR_FALLBACK per request,
+// defaulting to fallbackOff.
+func orchestratorFallback() string {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_FALLBACK"))); mode {
+	case fallbackReject, fallbackQueue:
+		return mode
+	default:
+		return fallbackOff
+	}
+}
+
+// orchestratorDown reports whether the orchestrator client's breaker is
+// open, and how long until it lets a probe through.
+func orchestratorDown() (time.Duration, bool) {
+	b, ok := getOrchestratorClient().(interface{ BreakerRetryAfter() time.Duration })
+	if !ok {
+		return 0, false
+	}
+	wait := b.BreakerRetryAfter()
+	return wait, wait > 0
+}
+
+// circuitOpen reports whether dispatchCommand failed because the breaker
+// was open, e.g. when it opened between orchestratorDown and the call.
+func circuitOpen(resp kernelResponse, status int) bool {
+	return status == http.StatusServiceUnavailable && resp.Stderr == circuitOpenMessage
+}
+
+// writeOrchestratorUnavailable answers 503 orchestrator_unavailable with a
+// Retry-After of the breaker's remaining cooldown, at least one second.
+func writeOrchestratorUnavailable(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	secs := int((wait + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	writeErrorf(w, r, ErrCodeOrchestratorUnavailable, http.StatusServiceUnavailable, "orchestrator temporarily unavailable, retry in %ds", secs)
+}
+
+// statusClientClosedRequest is the nginx convention for a request the
+// client abandoned before the response; it only reaches logs and audit.
+const statusClientClosedRequest = 499
+
+// dispatchCommand forwards a validated command to the orchestrator and
+// normalizes the result. The returned status is the HTTP code to reply with.
+func dispatchCommand(ctx context.Context, cmd kernelCommand, action string) (kernelResponse, int) {
+	client := getOrchestratorClient()
+	if client == nil {
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    "orchestrator not configured",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, http.StatusServiceUnavailable
+	}
+
+	taskReq, err := buildTaskRequest(cmd)
+	if err != nil {
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    "payload not serializable",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, http.StatusBadRequest
+	}
+	engine := taskReq.EngineName
+
+	started := time.Now()
+	var backend string
+	var taskResp *pb.TaskResponse
+	if bs, ok := client.(backendSubmitter); ok {
+		taskResp, backend, err = bs.SubmitTaskBackend(ctx, taskReq)
+	} else {
+		taskResp, err = client.SubmitTask(ctx, taskReq)
+	}
+	observeOrchestratorLatency(time.Since(started))
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The client went away; the upstream call was abandoned with it.
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    "request cancelled",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Data: map[string]interface{}{
+				"type":      normalizeType(cmd.Type),
+				"engine":    engine,
+				"status":    "cancelled",
+				"component": "kernel-api",
+			},
+		}, statusClientClosedRequest
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    "orchestrator call timed out",
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, http.StatusServiceUnavailable
+	}
+	if errors.Is(err, core.ErrCircuitOpen) {
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    circuitOpenMessage,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, http.StatusServiceUnavailable
+	}
+	if err != nil {
+		return kernelResponse{
+			ID:        cmd.ID,
+			Success:   false,
+			Stderr:    fmt.Sprintf("orchestrator error: %v", err),
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}, http.StatusBadGateway
+	}
+
+	data := map[string]interface{}{
+		"type":      normalizeType(cmd.Type),
+		"engine":    engine,
+		"received":  action,
+		"status":    taskResp.Status,
+		"output":    decodeOutput(taskResp.OutputData),
+		"component": "kernel-api",
+	}
+	if backend != "" {
+		data["backend"] = backend
+	}
+	resp := kernelResponse{
 		ID:        cmd.ID,
-		Success:   true,
-		Stdout:    fmt.Sprintf("kernel accepted %s: %s", normalizeType(cmd.Type), action),
+		Success:   taskResp.Status != "failed",
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Data: map[string]interface{}{
-			"type":      normalizeType(cmd.Type),
-			"received":  action,
-			"component": "kernel-api",
-		},
-	})
+		Data:      data,
+	}
+	if resp.Success {
+		resp.Stdout = taskResp.OutputData
+	} else {
+		resp.Stderr = taskResp.OutputData
+	}
+	return resp, http.StatusOK
+}
+
+// buildTaskRequest maps a kernel command onto an orchestrator task.
+func buildTaskRequest(cmd kernelCommand) (*pb.TaskRequest, error) {
+	input, err := json.Marshal(cmd.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.TaskRequest{
+		EngineName: taskEngine(cmd),
+		TaskId:     cmd.ID,
+		InputData:  string(input),
+	}, nil
+}
+
+// decodeOutput returns orchestrator output as structured JSON when it is JSON,
+// and as the raw string otherwise.
+func decodeOutput(raw string) interface{} {
+	if json.Valid([]byte(raw)) {
+		return json.RawMessage(raw)
+	}
+	return raw
 }
 
 // ChatCommandHandler is a compatibility alias for chat-style requests.
@@ -106,18 +607,55 @@ func ChatCommandHandler(w http.ResponseWriter, r *http.Request) {
 	ExecuteHandler(w, r)
 }
 
-// EventIngestHandler accepts orchestrator bridge events.
+// eventCounter keeps IDs of events ingested in the same nanosecond apart.
+var eventCounter uint64
+
+// EventIngestHandler accepts orchestrator bridge events and publishes them on
+// the kernel EventBus. The "type" field names the event; the remaining fields
+// become its data. The reply carries the event's generated ID and the number
+// of subscribers it was delivered to, so the bridge can spot events nobody
+// listens for.
 func EventIngestHandler(w http.ResponseWriter, r *http.Request) {
 	var payload map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &payload) {
+		return
+	}
+
+	name, _ := payload["type"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "event type required")
 		return
 	}
+	source := "orchestrator-bridge"
+	if s, ok := payload["source"].(string); ok && strings.TrimSpace(s) != "" {
+		source = strings.TrimSpace(s)
+	}
+
+	data := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		if k != "type" {
+			data[k] = v
+		}
+	}
+	id := fmt.Sprintf("evt-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&eventCounter, 1))
+	delivered := core.GlobalEventBus.Publish(types.Event{
+		ID:     id,
+		Name:   name,
+		Data:   data,
+		Source: source,
+	})
+	if delivered == 0 {
+		log.Printf("⚠️ Event %s (%s) from %s has no subscribers", id, name, source)
+	}
 
 	writeJSON(w, map[string]interface{}{
-		"status":    "accepted",
-		"component": "kernel-api",
-		"time":      time.Now().UTC().Format(time.RFC3339),
+		"status":      "accepted",
+		"id":          id,
+		"event":       name,
+		"subscribers": delivered,
+		"component":   "kernel-api",
+		"time":        time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
@@ -134,15 +672,193 @@ func extractFirstString(payload map[string]interface{}, keys ...string) string {
 	return ""
 }
 
+// defaultActionPaths are where extractAction looks when
+// NEUROEDGE_ACTION_PATHS is unset, in order.
+var defaultActionPaths = []string{"code", "command", "message", "input.text"}
+
+// actionPaths reads NEUROEDGE_ACTION_PATHS, a comma-separated list of dot
+// paths into the payload such as "input.text" or "messages.0.content";
+// numeric segments index arrays. Read per request like strictCommands.
+func actionPaths() []string {
+	raw := strings.TrimSpace(os.Getenv("NEUROEDGE_ACTION_PATHS"))
+	if raw == "" {
+		return defaultActionPaths
+	}
+	var paths []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// extractAction finds the text to run in a command payload: the first
+// non-blank string at one of actionPaths, otherwise the last non-blank
+// content in a chat-style "messages" array. The error lists every place
+// that was checked.
+func extractAction(payload map[string]interface{}) (string, error) {
+	paths := actionPaths()
+	for _, path := range paths {
+		if s, ok := lookupPath(payload, path).(string); ok && strings.TrimSpace(s) != "" {
+			return s, nil
+		}
+	}
+	if s := lastMessageContent(payload["messages"]); s != "" {
+		return s, nil
+	}
+	return "", fmt.Errorf("empty payload action: no text at %s or messages[].content", strings.Join(paths, ", "))
+}
+
+// lookupPath walks a dot path through nested objects and arrays, returning
+// nil when any segment is missing.
+func lookupPath(v interface{}, path string) interface{} {
+	for _, seg := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[seg]
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// lastMessageContent returns the last non-blank content in an OpenAI-style
+// messages array. Content may be a string or a list of parts, whose "text"
+// fields are joined with newlines.
+func lastMessageContent(raw interface{}) string {
+	messages, _ := raw.([]interface{})
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg, _ := messages[i].(map[string]interface{})
+		var text string
+		switch content := msg["content"].(type) {
+		case string:
+			text = content
+		case []interface{}:
+			var parts []string
+			for _, part := range content {
+				p, _ := part.(map[string]interface{})
+				if t, ok := p["text"].(string); ok && strings.TrimSpace(t) != "" {
+					parts = append(parts, t)
+				}
+			}
+			text = strings.Join(parts, "\n")
+		}
+		if strings.TrimSpace(text) != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// allowedCommandTypes are the command types the kernel understands; in
+// lenient mode anything else is run as "execute".
+var allowedCommandTypes = []string{"chat", "execute", "ai_inference"}
+
+// requiredPayloadFields lists the payload fields strict mode requires per
+// command type.
+var requiredPayloadFields = map[string][]string{
+	"ai_inference": {"engine"},
+}
+
+// strictCommands reports whether NEUROEDGE_STRICT_COMMANDS is set. Read per
+// request so it can be flipped without a restart.
+func strictCommands() bool {
+	strict, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("NEUROEDGE_STRICT_COMMANDS")))
+	return strict
+}
+
+// validateCommand rejects unknown command types and commands missing their
+// required payload fields when strict mode is on. An empty type is allowed
+// and runs as "execute". In lenient mode it always returns nil.
+func validateCommand(cmd kernelCommand) error {
+	if !strictCommands() {
+		return nil
+	}
+	commandType := strings.TrimSpace(cmd.Type)
+	if commandType == "" {
+		return nil
+	}
+	known := false
+	for _, t := range allowedCommandTypes {
+		if commandType == t {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown command type %q; allowed: %s", commandType, strings.Join(allowedCommandTypes, ", "))
+	}
+	for _, field := range requiredPayloadFields[commandType] {
+		if strings.TrimSpace(extractFirstString(cmd.Payload, field)) == "" {
+			return fmt.Errorf("%s commands require payload.%s", commandType, field)
+		}
+	}
+	return nil
+}
+
+// errEngineNotAllowed is returned by checkEngineAllowed.
+var errEngineNotAllowed = errors.New("engine not allowed")
+
+// taskEngine is the engine a command is submitted to: the trimmed
+// payload.engine, falling back to the normalized command type.
+func taskEngine(cmd kernelCommand) string {
+	if engine := strings.TrimSpace(extractFirstString(cmd.Payload, "engine")); engine != "" {
+		return engine
+	}
+	return normalizeType(cmd.Type)
+}
+
+// checkEngineAllowed enforces NEUROEDGE_ALLOWED_ENGINES (comma-separated,
+// read per request like strictCommands) against taskEngine, so a command that
+// names no engine is held to the list through its type's engine. An empty
+// list allows every engine.
+func checkEngineAllowed(cmd kernelCommand) error {
+	raw := strings.TrimSpace(os.Getenv("NEUROEDGE_ALLOWED_ENGINES"))
+	if raw == "" {
+		return nil
+	}
+	engine := taskEngine(cmd)
+	for _, allowed := range strings.Split(raw, ",") {
+		if strings.TrimSpace(allowed) == engine {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", errEngineNotAllowed, engine)
+}
+
 func normalizeType(commandType string) string {
-	switch strings.TrimSpace(commandType) {
+	switch t := strings.TrimSpace(commandType); t {
 	case "chat", "execute", "ai_inference":
-		return commandType
+		return t
 	default:
 		return "execute"
 	}
 }
 
+// decodeJSONBody decodes the request body into v, answering 413 when the body
+// exceeded withBodySizeLimit and 400 for malformed JSON.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, ErrCodeBodyTooLarge, http.StatusRequestEntityTooLarge)
+		return false
+	}
+	writeError(w, r, ErrCodeInvalidJSON, http.StatusBadRequest)
+	return false
+}
+
 // Helper to write JSON responses
 func writeJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
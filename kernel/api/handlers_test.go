@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"neuroedge/kernel/core"
+	"neuroedge/kernel/types"
+)
+
+// useHealthManager swaps in hm as the global health manager for one test.
+func useHealthManager(t *testing.T, hm *core.HealthManager) {
+	t.Helper()
+	prev := core.GlobalHealthManager
+	core.GlobalHealthManager = hm
+	t.Cleanup(func() { core.GlobalHealthManager = prev })
+}
+
+func TestHealthHandlerKeepsArrayBody(t *testing.T) {
+	hm := core.NewHealthManager()
+	hm.Register("orchestrator", func() error { return nil })
+	useHealthManager(t, hm)
+
+	// Before the first check the component is unknown, not unhealthy.
+	rec := httptest.NewRecorder()
+	HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/kernel/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 before the first check", rec.Code)
+	}
+	if got := rec.Header().Get("X-Kernel-Status"); got != core.HealthUnknown {
+		t.Fatalf("X-Kernel-Status = %q, want %q", got, core.HealthUnknown)
+	}
+	var health []types.KernelHealth
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatalf("body is not a JSON array: %v: %s", err, rec.Body)
+	}
+	if len(health) != 1 || health[0].Status != core.HealthUnknown {
+		t.Fatalf("health = %+v", health)
+	}
+}
+
+func TestHealthHandlerUnhealthy(t *testing.T) {
+	hm := core.NewHealthManager()
+	hm.Register("orchestrator", func() error { return errors.New("unreachable") })
+	hm.StartMonitoring()
+	t.Cleanup(hm.StopMonitoring)
+	useHealthManager(t, hm)
+
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 100; i++ {
+		rec = httptest.NewRecorder()
+		HealthHandler(rec, httptest.NewRequest(http.MethodGet, "/v1/kernel/health?fields=summary", nil))
+		if rec.Code != http.StatusOK {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	var summary types.KernelHealthSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Status != core.HealthUnhealthy || summary.Healthy || len(summary.Unhealthy) != 1 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if got := rec.Header().Get("X-Kernel-Status"); got != core.HealthUnhealthy {
+		t.Fatalf("X-Kernel-Status = %q, want %q", got, core.HealthUnhealthy)
+	}
+}
+
+func TestValidateCommandStrictMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmd       kernelCommand
+		strictErr string // "" means accepted in strict mode
+	}{
+		{name: "chat", cmd: kernelCommand{Type: "chat"}},
+		{name: "execute", cmd: kernelCommand{Type: "execute", Payload: map[string]interface{}{"command": "ls"}}},
+		{name: "empty type runs as execute", cmd: kernelCommand{}},
+		{name: "ai_inference with engine", cmd: kernelCommand{Type: "ai_inference", Payload: map[string]interface{}{"engine": "vision"}}},
+		{name: "unknown type", cmd: kernelCommand{Type: "shell"}, strictErr: "allowed: chat, execute, ai_inference"},
+		{name: "ai_inference without engine", cmd: kernelCommand{Type: "ai_inference", Payload: map[string]interface{}{}}, strictErr: "require payload.engine"},
+		{name: "ai_inference with blank engine", cmd: kernelCommand{Type: "ai_inference", Payload: map[string]interface{}{"engine": " "}}, strictErr: "require payload.engine"},
+	}
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(tt.name+"/strict="+strconv.FormatBool(strict), func(t *testing.T) {
+				t.Setenv("NEUROEDGE_STRICT_COMMANDS", strconv.FormatBool(strict))
+				err := validateCommand(tt.cmd)
+				if !strict || tt.strictErr == "" {
+					if err != nil {
+						t.Fatalf("rejected: %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.strictErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.strictErr)
+				}
+			})
+		}
+	}
+}
+
+func TestExecuteHandlerRejectsInStrictMode(t *testing.T) {
+	t.Setenv("NEUROEDGE_STRICT_COMMANDS", "true")
+	for _, body := range []string{
+		`{"id":"c1","type":"shell","payload":{}}`,
+		`{"id":"c2","type":"ai_inference","payload":{"prompt":"hi"}}`,
+	} {
+		rec := httptest.NewRecorder()
+		ExecuteHandler(rec, httptest.NewRequest(http.MethodPost, "/v1/execute", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", body, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), string(ErrCodeInvalidArgument)) {
+			t.Fatalf("%s: body = %s, want %s", body, rec.Body, ErrCodeInvalidArgument)
+		}
+	}
+}
+
+func TestExecuteHandlerOversizedCommand(t *testing.T) {
+	t.Setenv("NEUROEDGE_MAX_BODY_BYTES", "64")
+	body := `{"id":"big","type":"chat","payload":{"message":"` + strings.Repeat("x", 128) + `"}}`
+	for _, strict := range []string{"false", "true"} {
+		t.Setenv("NEUROEDGE_STRICT_COMMANDS", strict)
+		h := withBodySizeLimit(ExecuteHandler)
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/v1/execute", strings.NewReader(body)))
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("strict=%s: status = %d, want 413", strict, rec.Code)
+		}
+	}
+}
+
+func TestCheckEngineAllowedUsesSubmittedEngine(t *testing.T) {
+	t.Setenv("NEUROEDGE_ALLOWED_ENGINES", "chat, vision")
+
+	tests := []struct {
+		name, cmdType string
+		payload       map[string]interface{}
+		wantEngine    string
+		wantAllowed   bool
+	}{
+		{name: "listed engine", cmdType: "execute", payload: map[string]interface{}{"engine": "vision"}, wantEngine: "vision", wantAllowed: true},
+		{name: "engine is trimmed", cmdType: "execute", payload: map[string]interface{}{"engine": " chat "}, wantEngine: "chat", wantAllowed: true},
+		{name: "unlisted engine", cmdType: "chat", payload: map[string]interface{}{"engine": "shell"}, wantEngine: "shell"},
+		{name: "type fallback listed", cmdType: " chat ", payload: map[string]interface{}{}, wantEngine: "chat", wantAllowed: true},
+		{name: "type fallback unlisted", cmdType: "execute", payload: map[string]interface{}{}, wantEngine: "execute"},
+		{name: "blank engine falls back", cmdType: "ai_inference", payload: map[string]interface{}{"engine": "  "}, wantEngine: "ai_inference"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := kernelCommand{ID: "engine-1", Type: tt.cmdType, Payload: tt.payload}
+			req, err := buildTaskRequest(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.EngineName != tt.wantEngine {
+				t.Fatalf("EngineName = %q, want %q", req.EngineName, tt.wantEngine)
+			}
+			err = checkEngineAllowed(cmd)
+			if tt.wantAllowed && err != nil {
+				t.Fatalf("checkEngineAllowed = %v, want nil", err)
+			}
+			if !tt.wantAllowed && !errors.Is(err, errEngineNotAllowed) {
+				t.Fatalf("checkEngineAllowed = %v, want errEngineNotAllowed", err)
+			}
+		})
+	}
+}
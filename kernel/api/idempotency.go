@@ -0,0 +1,226 @@
+// kernel/api/idempotency.go
+package handlers
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idempotencyTTL reads NEUROEDGE_IDEMPOTENCY_TTL as a Go duration ("10m") or
+// whole seconds ("600"), defaulting to 10 minutes. "0" disables dedup.
+func idempotencyTTL() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("NEUROEDGE_IDEMPOTENCY_TTL"))
+	if raw == "" {
+		return 10 * time.Minute
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+		return d
+	}
+	if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	return 10 * time.Minute
+}
+
+// cachedResponse is what a replay writes back: status, body and the headers
+// ExecuteHandler sets itself.
+type cachedResponse struct {
+	status      int
+	contentType string
+	location    string
+	body        []byte
+}
+
+type idempotencyEntry struct {
+	key         string
+	fingerprint [sha256.Size]byte
+	expires     time.Time
+	// done closes when the first request finishes; resp stays nil if its
+	// result was not cacheable and a waiting retry should execute itself.
+	done chan struct{}
+	resp *cachedResponse
+	elem *list.Element
+}
+
+// idempotencyCache remembers recent /execute responses by idempotency key.
+// It holds at most max entries; the oldest go first once it is full.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	order   *list.List
+	max     int
+}
+
+var executeIdempotency = &idempotencyCache{
+	entries: map[string]*idempotencyEntry{},
+	order:   list.New(),
+	max:     readIntEnv("NEUROEDGE_IDEMPOTENCY_MAX_ENTRIES", 10000),
+}
+
+// begin claims key for the caller or returns the entry already holding it.
+// leader is true when the caller must execute and then call finish. conflict
+// is true when key is held by a request with a different body.
+func (c *idempotencyCache) begin(key string, fingerprint [sha256.Size]byte, ttl time.Duration) (entry *idempotencyEntry, leader, conflict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+		return e, false, e.fingerprint != fingerprint
+	} else if ok {
+		c.removeLocked(e)
+	}
+
+	for c.order.Len() > 0 && c.order.Len() >= c.max {
+		c.removeLocked(c.order.Front().Value.(*idempotencyEntry))
+	}
+	e := &idempotencyEntry{
+		key:         key,
+		fingerprint: fingerprint,
+		expires:     now.Add(ttl),
+		done:        make(chan struct{}),
+	}
+	e.elem = c.order.PushBack(e)
+	c.entries[key] = e
+	return e, true, false
+}
+
+// finish publishes the leader's response to waiting retries. A nil resp
+// forgets the key so the next retry executes again.
+func (c *idempotencyCache) finish(e *idempotencyEntry, resp *cachedResponse) {
+	c.mu.Lock()
+	e.resp = resp
+	if resp == nil {
+		c.removeLocked(e)
+	}
+	c.mu.Unlock()
+	close(e.done)
+}
+
+// removeLocked drops e if it is still the entry for its key. Caller must
+// hold c.mu.
+func (c *idempotencyCache) removeLocked(e *idempotencyEntry) {
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+	if e.elem != nil {
+		c.order.Remove(e.elem)
+		e.elem = nil
+	}
+}
+
+// idempotencyKey is the client's X-Request-ID, or the command id when the
+// header is absent, scoped to the authenticated API key so two clients can't
+// read each other's results. An empty key means no dedup.
+func idempotencyKey(r *http.Request, cmd kernelCommand) string {
+	key := strings.TrimSpace(r.Header.Get("X-Request-ID"))
+	if key == "" {
+		key = strings.TrimSpace(cmd.ID)
+	}
+	if key == "" {
+		return ""
+	}
+	info, _ := r.Context().Value(authContextKey{}).(authInfo)
+	return info.KeyID + "|" + key
+}
+
+// commandFingerprint hashes what makes two requests the same call: the
+// decoded command and whether it runs async.
+func commandFingerprint(r *http.Request, cmd kernelCommand) [sha256.Size]byte {
+	body, _ := json.Marshal(cmd)
+	body = append(body, r.URL.Query().Get("async")...)
+	return sha256.Sum256(body)
+}
+
+// captureWriter tees a response into a buffer so it can be cached.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if cw.status == 0 {
+		cw.status = code
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *captureWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
+
+// serveIdempotent runs execute at most once per key within the TTL. Retries
+// with the same body get the first response replayed, waiting for it if it
+// is still running; a different body under the same key is a 409. Responses
+// of 5xx are not cached, so retrying after an orchestrator outage works,
+// and neither are requests the client abandoned.
+func serveIdempotent(w http.ResponseWriter, r *http.Request, key string, fingerprint [sha256.Size]byte, execute func(http.ResponseWriter)) {
+	ttl := idempotencyTTL()
+	if ttl == 0 {
+		execute(w)
+		return
+	}
+
+	for {
+		entry, leader, conflict := executeIdempotency.begin(key, fingerprint, ttl)
+		if conflict {
+			writeErrorf(w, r, ErrCodeConflict, http.StatusConflict, "idempotency key reused with a different request body")
+			return
+		}
+		if leader {
+			runIdempotent(w, entry, execute)
+			return
+		}
+		select {
+		case <-entry.done:
+		case <-r.Context().Done():
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "request cancelled while waiting for the original")
+			return
+		}
+		if resp := entry.resp; resp != nil {
+			if resp.contentType != "" {
+				w.Header().Set("Content-Type", resp.contentType)
+			}
+			if resp.location != "" {
+				w.Header().Set("Location", resp.location)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(resp.status)
+			w.Write(resp.body)
+			return
+		}
+		// The original failed in a way we don't cache; try to become leader.
+	}
+}
+
+func runIdempotent(w http.ResponseWriter, entry *idempotencyEntry, execute func(http.ResponseWriter)) {
+	cw := &captureWriter{ResponseWriter: w}
+	var resp *cachedResponse
+	// Deferred so a panicking handler still releases waiting retries.
+	defer func() { executeIdempotency.finish(entry, resp) }()
+
+	execute(cw)
+	// An abandoned request has no result to replay; a retry must run again.
+	if cw.status == 0 || cw.status >= 500 || cw.status == statusClientClosedRequest {
+		return
+	}
+	resp = &cachedResponse{
+		status:      cw.status,
+		contentType: w.Header().Get("Content-Type"),
+		location:    w.Header().Get("Location"),
+		body:        cw.body.Bytes(),
+	}
+}
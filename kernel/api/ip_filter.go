@@ -0,0 +1,100 @@
+// kernel/api/ip_filter.go
+package handlers
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// parsePrefixes reads a comma-separated list of CIDRs (bare IPs are treated as
+// single-host prefixes). Invalid entries are logged and skipped.
+func parsePrefixes(key string) []netip.Prefix {
+	out := []netip.Prefix{}
+	for _, raw := range strings.Split(os.Getenv(key), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "/") {
+			addr, err := netip.ParseAddr(raw)
+			if err != nil {
+				log.Printf("⚠️ %s: ignoring invalid entry %q: %v", key, raw, err)
+				continue
+			}
+			out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(raw)
+		if err != nil {
+			log.Printf("⚠️ %s: ignoring invalid entry %q: %v", key, raw, err)
+			continue
+		}
+		out = append(out, p.Masked())
+	}
+	return out
+}
+
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustProxy reads NEUROEDGE_TRUST_PROXY: whether X-Forwarded-For names the
+// caller because a proxy we trust sets it.
+func trustProxy() bool {
+	trusted, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("NEUROEDGE_TRUST_PROXY")))
+	return trusted
+}
+
+// filterClientIP resolves the caller's address for IP filtering. X-Forwarded-For
+// is only honored when NEUROEDGE_TRUST_PROXY is set, since clients can forge it.
+func filterClientIP(r *http.Request, trustProxy bool) (netip.Addr, bool) {
+	raw := ""
+	if trustProxy {
+		if xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); xff != "" {
+			raw = strings.TrimSpace(strings.Split(xff, ",")[0])
+		}
+	}
+	if raw == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		raw = host
+	}
+	addr, err := netip.ParseAddr(raw)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// withIPFilter enforces NEUROEDGE_IP_ALLOW / NEUROEDGE_IP_DENY. Deny wins on
+// overlap; an empty allow list admits any address not denied.
+func withIPFilter(next http.HandlerFunc) http.HandlerFunc {
+	allow := parsePrefixes("NEUROEDGE_IP_ALLOW")
+	deny := parsePrefixes("NEUROEDGE_IP_DENY")
+	trusted := trustProxy()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(allow) == 0 && len(deny) == 0 {
+			next(w, r)
+			return
+		}
+		addr, ok := filterClientIP(r, trusted)
+		if !ok || prefixesContain(deny, addr) || (len(allow) > 0 && !prefixesContain(allow, addr)) {
+			writeError(w, r, ErrCodeForbidden, http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
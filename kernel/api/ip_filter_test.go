@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithIPFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		allow      string
+		deny       string
+		trustProxy string
+		remote     string
+		xff        string
+		want       int
+	}{
+		{name: "no lists", remote: "203.0.113.7:1234", want: http.StatusOK},
+		{name: "ipv4 in allowed cidr", allow: "10.0.0.0/8", remote: "10.1.2.3:1234", want: http.StatusOK},
+		{name: "ipv4 outside allowed cidr", allow: "10.0.0.0/8", remote: "11.0.0.1:1234", want: http.StatusForbidden},
+		{name: "ipv4 bare address", allow: "192.0.2.1", remote: "192.0.2.1:1234", want: http.StatusOK},
+		{name: "ipv4 next to bare address", allow: "192.0.2.1", remote: "192.0.2.2:1234", want: http.StatusForbidden},
+		{name: "ipv4-mapped ipv6 client", allow: "10.0.0.0/8", remote: "[::ffff:10.1.2.3]:1234", want: http.StatusOK},
+		{name: "ipv6 in allowed cidr", allow: "2001:db8::/32", remote: "[2001:db8:1::5]:1234", want: http.StatusOK},
+		{name: "ipv6 outside allowed cidr", allow: "2001:db8::/32", remote: "[2001:db9::1]:1234", want: http.StatusForbidden},
+		{name: "ipv6 bare address", allow: "::1", remote: "[::1]:1234", want: http.StatusOK},
+		{name: "ipv4 client against ipv6 allow list", allow: "2001:db8::/32", remote: "10.0.0.1:1234", want: http.StatusForbidden},
+		{name: "deny only", deny: "198.51.100.0/24", remote: "198.51.100.9:1234", want: http.StatusForbidden},
+		{name: "deny only, other address", deny: "198.51.100.0/24", remote: "198.51.101.9:1234", want: http.StatusOK},
+		{name: "deny wins over allow, ipv4", allow: "10.0.0.0/8", deny: "10.0.5.0/24", remote: "10.0.5.1:1234", want: http.StatusForbidden},
+		{name: "allow outside the deny, ipv4", allow: "10.0.0.0/8", deny: "10.0.5.0/24", remote: "10.0.6.1:1234", want: http.StatusOK},
+		{name: "deny wins over allow, ipv6", allow: "2001:db8::/32", deny: "2001:db8:bad::/48", remote: "[2001:db8:bad::1]:1234", want: http.StatusForbidden},
+		{name: "invalid entries skipped", allow: "nonsense, 10.0.0.0/8, 300.1.1.1", remote: "10.0.0.1:1234", want: http.StatusOK},
+		{name: "forwarded ignored without trust", allow: "10.0.0.0/8", remote: "203.0.113.7:1234", xff: "10.0.0.1", want: http.StatusForbidden},
+		{name: "forwarded honoured with trust", allow: "10.0.0.0/8", trustProxy: "true", remote: "203.0.113.7:1234", xff: "10.0.0.1, 203.0.113.7", want: http.StatusOK},
+		{name: "forwarded ipv6 denied with trust", deny: "2001:db8::/32", trustProxy: "true", remote: "10.0.0.1:1234", xff: "2001:db8::9", want: http.StatusForbidden},
+		{name: "unparseable client", allow: "10.0.0.0/8", remote: "garbage", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEUROEDGE_IP_ALLOW", tt.allow)
+			t.Setenv("NEUROEDGE_IP_DENY", tt.deny)
+			t.Setenv("NEUROEDGE_TRUST_PROXY", tt.trustProxy)
+			h := withIPFilter(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/kernel/health", nil)
+			req.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			rec := httptest.NewRecorder()
+			h(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
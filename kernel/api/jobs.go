@@ -0,0 +1,291 @@
+// kernel/api/jobs.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"neuroedge/kernel/core"
+)
+
+const (
+	jobPending   = "pending"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+type job struct {
+	ID        string          `json:"id"`
+	Status    string          `json:"status"`
+	Response  *kernelResponse `json:"response,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	// Progress describes what a pending job is waiting on, if anything.
+	Progress string `json:"progress,omitempty"`
+
+	taskID string
+	cancel context.CancelFunc
+	// changed is closed and replaced on every update to wake JobStreamHandler.
+	changed chan struct{}
+}
+
+// touchLocked stamps an update and wakes stream watchers. Caller must hold
+// jobsMu.
+func (j *job) touchLocked() {
+	j.UpdatedAt = time.Now()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+var (
+	jobsMu     sync.Mutex
+	jobs       = map[string]*job{}
+	jobCounter uint64
+)
+
+// submitAsyncJob records a pending job and runs the command in the background.
+// The command is audited under requestID once it finishes.
+func submitAsyncJob(cmd kernelCommand, action, requestID string) *job {
+	now := time.Now()
+	timeout := time.Duration(readIntEnv("NEUROEDGE_JOB_TIMEOUT_SEC", 120)) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	j := &job{
+		ID:        fmt.Sprintf("job-%d-%d", now.UnixNano(), atomic.AddUint64(&jobCounter, 1)),
+		Status:    jobPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		taskID:    cmd.ID,
+		cancel:    cancel,
+		changed:   make(chan struct{}),
+	}
+
+	jobsMu.Lock()
+	cleanupJobs(now)
+	jobs[j.ID] = j
+	snapshot := *j
+	jobsMu.Unlock()
+
+	go func() {
+		defer cancel()
+		resp, status := dispatchQueued(ctx, cmd, action, func(msg string) { setJobProgress(j.ID, msg) })
+		auditCommand(requestID, cmd, resp, status)
+		finishJob(j.ID, resp, status)
+	}()
+
+	return &snapshot
+}
+
+// dispatchQueued is dispatchCommand that, in the queue fallback mode,
+// keeps the job pending while the orchestrator breaker is open and retries
+// once it lets a probe through, until ctx ends. Each wait is reported
+// through progress.
+func dispatchQueued(ctx context.Context, cmd kernelCommand, action string, progress func(string)) (kernelResponse, int) {
+	for {
+		resp, status := dispatchCommand(ctx, cmd, action)
+		if orchestratorFallback() != fallbackQueue || !circuitOpen(resp, status) {
+			return resp, status
+		}
+		wait, _ := orchestratorDown()
+		if wait < time.Second {
+			wait = time.Second
+		}
+		progress(fmt.Sprintf("orchestrator unavailable, retrying in %s", wait.Round(time.Second)))
+		select {
+		case <-ctx.Done():
+			return resp, status
+		case <-time.After(wait):
+		}
+	}
+}
+
+func finishJob(id string, resp kernelResponse, status int) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	j, ok := jobs[id]
+	if !ok || j.Status == jobCancelled {
+		return
+	}
+	j.Response = &resp
+	j.Progress = ""
+	if status == http.StatusOK && resp.Success {
+		j.Status = jobDone
+	} else {
+		j.Status = jobFailed
+	}
+	j.touchLocked()
+}
+
+// setJobProgress records what a pending job is waiting on.
+func setJobProgress(id, msg string) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if j, ok := jobs[id]; ok && j.Status == jobPending && j.Progress != msg {
+		j.Progress = msg
+		j.touchLocked()
+	}
+}
+
+// cleanupJobs drops finished jobs past retention. Caller must hold jobsMu.
+func cleanupJobs(now time.Time) {
+	retention := time.Duration(readIntEnv("NEUROEDGE_JOB_RETENTION_SEC", 600)) * time.Second
+	for id, j := range jobs {
+		if j.Status != jobPending && now.Sub(j.UpdatedAt) > retention {
+			delete(jobs, id)
+		}
+	}
+}
+
+// JobStatusHandler returns the state of an async job and, once finished, its response.
+func JobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	jobsMu.Lock()
+	cleanupJobs(time.Now())
+	j, ok := jobs[id]
+	var snapshot job
+	if ok {
+		snapshot = *j
+	}
+	jobsMu.Unlock()
+
+	if !ok {
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, snapshot)
+}
+
+// JobCancelHandler cancels a pending async job: it cancels the job's
+// context, which stops its background dispatch, marks it cancelled and then
+// asks the orchestrator to stop the task. The upstream cancel is best
+// effort, since the orchestrator may not support it or may never have seen
+// the task (e.g. one still held in the fallback queue); its failure is
+// logged, not returned. Cancelling a finished job changes nothing and
+// returns it as is.
+func JobCancelHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	jobsMu.Lock()
+	j, ok := jobs[id]
+	var snapshot job
+	cancelled := false
+	if ok {
+		if j.Status == jobPending {
+			j.Status = jobCancelled
+			j.Progress = ""
+			j.touchLocked()
+			j.cancel()
+			cancelled = true
+		}
+		snapshot = *j
+	}
+	jobsMu.Unlock()
+
+	if !ok {
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "job not found")
+		return
+	}
+	if cancelled {
+		cancelUpstreamTask(r.Context(), snapshot.taskID)
+	}
+	writeJSON(w, snapshot)
+}
+
+// cancelUpstreamTask asks the orchestrator to stop taskID, if the client
+// supports cancellation, and logs why it couldn't.
+func cancelUpstreamTask(ctx context.Context, taskID string) {
+	c, ok := getOrchestratorClient().(interface {
+		CancelTask(context.Context, string) error
+	})
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, orchestratorPingTimeout)
+	defer cancel()
+	err := c.CancelTask(ctx, taskID)
+	switch {
+	case errors.Is(err, core.ErrTaskNotCancellable):
+		log.Printf("⚠️ cancel task %s: orchestrator reports it finished or unknown", taskID)
+	case err != nil:
+		log.Printf("⚠️ cancel task %s upstream: %v", taskID, err)
+	}
+}
+
+// JobStreamHandler streams an async job as Server-Sent Events: the current
+// state first, then every change, each as the job's JSON. Events are named
+// after the status ("pending", "done", "failed", "cancelled"), or
+// "progress" when a pending job reports what it is waiting on. The stream
+// ends after the job finishes. Jobs dispatch with a single orchestrator
+// call, so there is no partial output to relay before the final response.
+func JobStreamHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	jobsMu.Lock()
+	_, ok := jobs[id]
+	jobsMu.Unlock()
+	if !ok {
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "job not found")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(nodeWatchKeepAlive)
+	defer keepAlive.Stop()
+	var last job
+	for {
+		jobsMu.Lock()
+		j, ok := jobs[id]
+		var snapshot job
+		var changed chan struct{}
+		if ok {
+			snapshot, changed = *j, j.changed
+		}
+		jobsMu.Unlock()
+		if !ok {
+			// Dropped by retention while we weren't looking.
+			return
+		}
+
+		if !snapshot.UpdatedAt.Equal(last.UpdatedAt) || last.Status == "" {
+			event := snapshot.Status
+			if last.Status == snapshot.Status && snapshot.Progress != "" {
+				event = "progress"
+			}
+			data, _ := json.Marshal(snapshot)
+			writeSSE(w, event, string(data))
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			last = snapshot
+		}
+		if snapshot.Status != jobPending {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = w.Write([]byte(": keep-alive\n\n"))
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-changed:
+		}
+	}
+}
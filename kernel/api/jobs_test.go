@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"neuroedge/kernel/core"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// uncancellableClient blocks every task until its context ends and answers
+// every cancel with ErrTaskNotCancellable, like an orchestrator without a
+// /cancel route.
+type uncancellableClient struct {
+	dispatchDone chan struct{}
+	cancelCalls  chan string
+}
+
+func (c *uncancellableClient) SubmitTask(ctx context.Context, _ *pb.TaskRequest) (*pb.TaskResponse, error) {
+	<-ctx.Done()
+	close(c.dispatchDone)
+	return nil, ctx.Err()
+}
+
+func (c *uncancellableClient) CancelTask(_ context.Context, taskID string) error {
+	c.cancelCalls <- taskID
+	return core.ErrTaskNotCancellable
+}
+
+func TestJobCancelHandlerCancelsLocallyWhenUpstreamCannot(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_FALLBACK", "off")
+	c := &uncancellableClient{dispatchDone: make(chan struct{}), cancelCalls: make(chan string, 1)}
+	useOrchestratorClient(t, c)
+
+	j := submitAsyncJob(kernelCommand{ID: "job-cancel-1", Type: "chat", Payload: map[string]interface{}{"message": "hi"}}, "chat", "req-job-cancel-1")
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/v1/kernel/jobs/"+j.ID, nil), map[string]string{"id": j.ID})
+	rec := httptest.NewRecorder()
+	JobCancelHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var got job
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != jobCancelled {
+		t.Fatalf("job status = %q, want %q", got.Status, jobCancelled)
+	}
+	if taskID := <-c.cancelCalls; taskID != "job-cancel-1" {
+		t.Fatalf("upstream cancel for %q, want job-cancel-1", taskID)
+	}
+	select {
+	case <-c.dispatchDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("local orchestrator call kept running after cancel")
+	}
+
+	// Cancelling again returns the finished job without another upstream call.
+	rec = httptest.NewRecorder()
+	JobCancelHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second cancel status = %d", rec.Code)
+	}
+	select {
+	case id := <-c.cancelCalls:
+		t.Fatalf("second cancel reached upstream for %s", id)
+	default:
+	}
+}
@@ -0,0 +1,49 @@
+// kernel/api/limits.go
+package handlers
+
+import (
+	"net/http"
+	"time"
+)
+
+// LimitsSnapshot is the /kernel/limits response.
+type LimitsSnapshot struct {
+	Concurrency struct {
+		ConcurrencySnapshot
+		Reserved int           `json:"reserved"`
+		Queue    QueueSnapshot `json:"queue"`
+	} `json:"concurrency"`
+	RateLimit struct {
+		Rate       float64             `json:"rate_per_second"`
+		Burst      int                 `json:"burst"`
+		Tracked    int                 `json:"tracked"`
+		Identities []RateLimitIdentity `json:"identities"`
+	} `json:"rate_limit"`
+}
+
+// LimitsHandler shows what the concurrency and rate limiters are doing: the
+// inflight count against the limit and the backpressure queue, the configured rate and burst, and the
+// ?top= (default 20) identities with the fewest tokens left. It is the first
+// stop when clients report 429 or 503 storms.
+func LimitsHandler(w http.ResponseWriter, r *http.Request) {
+	top, err := queryInt(r.URL.Query().Get("top"), 20)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid top: must be a non-negative integer")
+		return
+	}
+
+	var out LimitsSnapshot
+	out.Concurrency.ConcurrencySnapshot = getConcurrencySnapshot()
+	out.Concurrency.Reserved = readIntEnv("NEUROEDGE_RESERVED_SLOTS", 0)
+	out.Concurrency.Queue = ensureQueue().snapshot()
+
+	rate, burst := currentRateLimit()
+	out.RateLimit.Rate, out.RateLimit.Burst = rate, burst
+	all := rateLimitIdentities(time.Now(), rate, burst, 0)
+	out.RateLimit.Tracked = len(all)
+	if top > 0 && len(all) > top {
+		all = all[:top]
+	}
+	out.RateLimit.Identities = all
+	writeJSON(w, out)
+}
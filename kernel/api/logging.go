@@ -2,14 +2,37 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"neuroedge/kernel/core"
 )
 
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
+	// body captures the response for NEUROEDGE_LOG_BODIES; nil otherwise.
+	body *cappedBuffer
+}
+
+// Write captures the response body when body logging is on.
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.body != nil {
+		r.body.Write(p)
+	}
+	return r.ResponseWriter.Write(p)
 }
 
 func (r *statusRecorder) WriteHeader(code int) {
@@ -17,18 +40,121 @@ func (r *statusRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// Flush lets streaming handlers flush through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades take over the connection through the recorder.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// maxLoggedBody bounds how much of each body NEUROEDGE_LOG_BODIES keeps.
+const maxLoggedBody = 64 << 10
+
+// cappedBuffer keeps the first maxLoggedBody bytes written to it.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(p []byte) {
+	if room := maxLoggedBody - c.buf.Len(); len(p) > room {
+		p = p[:room]
+		c.truncated = true
+	}
+	c.buf.Write(p)
+}
+
+// teeBody copies what the handler reads from the request body into capture.
+type teeBody struct {
+	io.ReadCloser
+	capture *cappedBuffer
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.capture.Write(p[:n])
+	return n, err
+}
+
+// logBodies reports whether NEUROEDGE_LOG_BODIES asks for request and
+// response bodies in the access log.
+func logBodies() bool {
+	on, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("NEUROEDGE_LOG_BODIES")))
+	return on
+}
+
+// captureBodies starts capturing r's body and rec's response for logging.
+func captureBodies(r *http.Request, rec *statusRecorder) *cappedBuffer {
+	req := &cappedBuffer{}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = &teeBody{ReadCloser: r.Body, capture: req}
+	}
+	rec.body = &cappedBuffer{}
+	return req
+}
+
+// loggableBody decodes a captured JSON body and masks sensitive keys with
+// core.LogRedactor. Bodies that were cut off or aren't JSON are summarized
+// rather than logged raw, since they can't be redacted.
+// A gzip-encoded body (withGzip sits inside the logger) is decompressed first.
+func loggableBody(c *cappedBuffer, encoding string) interface{} {
+	if c == nil || c.buf.Len() == 0 {
+		return nil
+	}
+	if c.truncated {
+		return fmt.Sprintf("[%d+ bytes, not logged]", c.buf.Len())
+	}
+	raw := c.buf.Bytes()
+	if strings.EqualFold(encoding, "gzip") {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Sprintf("[%d bytes gzip, not logged]", len(raw))
+		}
+		raw, err = io.ReadAll(io.LimitReader(zr, maxLoggedBody+1))
+		if err != nil || len(raw) > maxLoggedBody {
+			return fmt.Sprintf("[%d bytes gzip, not logged]", c.buf.Len())
+		}
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Sprintf("[%d bytes non-JSON, not logged]", len(raw))
+	}
+	return core.LogRedactor().RedactValue(v)
+}
+
 func withRequestLogging(next http.HandlerFunc) http.HandlerFunc {
+	bodies := logBodies()
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		var reqBody *cappedBuffer
+		if bodies {
+			reqBody = captureBodies(r, rec)
+		}
 		next(rec, r)
+		requestStats().record(routePattern(r), rec.status, start)
 
 		// Avoid noisy routine health polling logs unless there is an error.
 		if shouldSkipRequestLog(r.URL.Path) && rec.status < http.StatusBadRequest {
 			return
 		}
 
-		log.Printf(
+		line := fmt.Sprintf(
 			"method=%s path=%s status=%d duration=%s ip=%s request_id=%s",
 			r.Method,
 			r.URL.Path,
@@ -37,12 +163,86 @@ func withRequestLogging(next http.HandlerFunc) http.HandlerFunc {
 			clientIP(r),
 			rec.Header().Get("X-Request-ID"),
 		)
+		if bodies {
+			for _, b := range []struct {
+				name string
+				body interface{}
+			}{{"request_body", loggableBody(reqBody, r.Header.Get("Content-Encoding"))}, {"response_body", loggableBody(rec.body, rec.Header().Get("Content-Encoding"))}} {
+				if b.body == nil {
+					continue
+				}
+				raw, _ := json.Marshal(b.body)
+				line += " " + b.name + "=" + string(raw)
+			}
+		}
+		log.Print(line)
+	}
+}
+
+// requestLogEntry is one structured access-log line.
+type requestLogEntry struct {
+	TS         string  `json:"ts"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	RequestID  string  `json:"request_id"`
+	RemoteIP   string  `json:"remote_ip"`
+	// Bodies are only set with NEUROEDGE_LOG_BODIES, already redacted.
+	RequestBody  interface{} `json:"request_body,omitempty"`
+	ResponseBody interface{} `json:"response_body,omitempty"`
+}
+
+// withStructuredLogging emits one JSON object per request for log pipelines.
+func withStructuredLogging(next http.HandlerFunc) http.HandlerFunc {
+	bodies := logBodies()
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		var reqBody *cappedBuffer
+		if bodies {
+			reqBody = captureBodies(r, rec)
+		}
+		next(rec, r)
+		requestStats().record(routePattern(r), rec.status, start)
+
+		if shouldSkipRequestLog(r.URL.Path) && rec.status < http.StatusBadRequest {
+			return
+		}
+
+		entry := requestLogEntry{
+			TS:         start.UTC().Format(time.RFC3339Nano),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Status:     rec.status,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			RequestID:  rec.Header().Get("X-Request-ID"),
+			RemoteIP:   clientIP(r),
+		}
+		if bodies {
+			entry.RequestBody = loggableBody(reqBody, r.Header.Get("Content-Encoding"))
+			entry.ResponseBody = loggableBody(rec.body, rec.Header().Get("Content-Encoding"))
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		_, _ = log.Writer().Write(append(line, '\n'))
+	}
+}
+
+// withLogging picks the access-log format from NEUROEDGE_LOG_FORMAT
+// ("json" for structured, anything else for the human-readable default).
+func withLogging(next http.HandlerFunc) http.HandlerFunc {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("NEUROEDGE_LOG_FORMAT")), "json") {
+		return withStructuredLogging(next)
 	}
+	return withRequestLogging(next)
 }
 
 func shouldSkipRequestLog(path string) bool {
 	switch path {
-	case "/health", "/healthz", "/readyz":
+	case "/health", "/healthz", "/readyz", "/metrics":
 		return true
 	default:
 		return false
@@ -0,0 +1,102 @@
+// kernel/api/maintenance.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaintenanceStatus is the /kernel/maintenance response.
+type MaintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// maintenance is process-local: each replica is toggled on its own and a
+// restart turns it off.
+var maintenance struct {
+	mu     sync.RWMutex
+	status MaintenanceStatus
+}
+
+// Maintenance reports whether maintenance mode is on, and since when.
+func Maintenance() MaintenanceStatus {
+	maintenance.mu.RLock()
+	defer maintenance.mu.RUnlock()
+	return maintenance.status
+}
+
+// SetMaintenance turns maintenance mode on or off. Turning it on again
+// keeps the original start time but takes the new reason.
+func SetMaintenance(enabled bool, reason string) MaintenanceStatus {
+	maintenance.mu.Lock()
+	defer maintenance.mu.Unlock()
+	prev := maintenance.status
+	switch {
+	case !enabled:
+		maintenance.status = MaintenanceStatus{}
+	case prev.Enabled:
+		maintenance.status.Reason = reason
+	default:
+		now := time.Now().UTC()
+		maintenance.status = MaintenanceStatus{Enabled: true, Reason: reason, Since: &now}
+	}
+	switch {
+	case enabled && reason != "":
+		fmt.Printf("🚧 Maintenance mode on (was %s): %s\n", onOff(prev.Enabled), reason)
+	case enabled:
+		fmt.Printf("🚧 Maintenance mode on (was %s)\n", onOff(prev.Enabled))
+	default:
+		fmt.Printf("✅ Maintenance mode off (was %s)\n", onOff(prev.Enabled))
+	}
+	return maintenance.status
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// rejectDuringMaintenance answers 503 maintenance instead of calling next
+// while maintenance mode is on. It wraps the mutating command routes;
+// reads, health and liveness stay unwrapped.
+func rejectDuringMaintenance(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if status := Maintenance(); status.Enabled {
+			if status.Reason != "" {
+				writeErrorf(w, r, ErrCodeMaintenance, http.StatusServiceUnavailable, "kernel in maintenance mode: %s", status.Reason)
+				return
+			}
+			writeError(w, r, ErrCodeMaintenance, http.StatusServiceUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// MaintenanceHandler reports maintenance mode on GET. POST
+// {"enabled": bool, "reason": "..."} toggles it and returns the new state.
+func MaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var body struct {
+			Enabled *bool  `json:"enabled"`
+			Reason  string `json:"reason"`
+		}
+		if !decodeJSONBody(w, r, &body) {
+			return
+		}
+		if body.Enabled == nil {
+			writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "enabled required")
+			return
+		}
+		writeJSON(w, SetMaintenance(*body.Enabled, strings.TrimSpace(body.Reason)))
+		return
+	}
+	writeJSON(w, Maintenance())
+}
@@ -0,0 +1,200 @@
+// kernel/api/mesh.go
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/core"
+	"neuroedge/kernel/mesh"
+)
+
+var (
+	meshMu      sync.RWMutex
+	meshManager *mesh.MeshManager
+)
+
+// RegisterMeshManager makes m's history available to MeshExportHandler.
+func RegisterMeshManager(m *mesh.MeshManager) {
+	meshMu.Lock()
+	defer meshMu.Unlock()
+	meshManager = m
+}
+
+// MeshExportHandler dumps the mesh message and route histories as
+// {"messages": [...], "routes": [...]} for incident bundles. The arrays use
+// the format Messaging.ImportHistory and Routing.ImportHistory read back.
+func MeshExportHandler(w http.ResponseWriter, r *http.Request) {
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+	if m == nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "mesh not enabled")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, `{"messages":`)
+	if err := m.Messaging.ExportHistory(w); err != nil {
+		return
+	}
+	io.WriteString(w, `,"routes":`)
+	if err := m.Routing.ExportHistory(w); err != nil {
+		return
+	}
+	io.WriteString(w, "}\n")
+}
+
+// MeshRotationHandler reports each node's weight and health in the balanced
+// routing rotation.
+func MeshRotationHandler(w http.ResponseWriter, r *http.Request) {
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+	if m == nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "mesh not enabled")
+		return
+	}
+	writeJSON(w, m.Routing.Rotation())
+}
+
+// MeshTopology is the mesh graph with the window its edges cover.
+type MeshTopology struct {
+	Since *time.Time `json:"since,omitempty"`
+	mesh.Topology
+}
+
+// MeshTopologyHandler returns the mesh as vertices and edges for graph
+// views; see MeshManager.Topology. ?since= bounds the route history used
+// for edges, either as an RFC 3339 time or as a Go duration back from now
+// ("15m"); without it all retained history is used.
+func MeshTopologyHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			since = t
+		} else {
+			writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid since: want an RFC 3339 time or a positive duration")
+			return
+		}
+	}
+
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+	if m == nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "mesh not enabled")
+		return
+	}
+
+	out := MeshTopology{Topology: m.Topology(core.CurrentConfig().KernelID, since)}
+	if !since.IsZero() {
+		out.Since = &since
+	}
+	writeJSON(w, out)
+}
+
+// MeshInbox is the /kernel/mesh/inbox response.
+type MeshInbox struct {
+	Inbox  map[string]int `json:"inbox"`
+	Outbox map[string]int `json:"outbox"`
+	// Node holds one node's pending messages when ?node= is given.
+	Node *MeshNodeMessages `json:"node,omitempty"`
+}
+
+// MeshNodeMessages is one node's pending inbox, decrypted, and outbox as
+// stored (encrypted and signed when those are on).
+type MeshNodeMessages struct {
+	ID     string   `json:"id"`
+	Inbox  []string `json:"inbox"`
+	Outbox []string `json:"outbox"`
+}
+
+// MeshInboxHandler reports the pending inbox and outbox depth of every node
+// that has one, so a stall can be spotted without knowing node IDs up front.
+// ?node= also dumps that node's pending messages.
+func MeshInboxHandler(w http.ResponseWriter, r *http.Request) {
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+	if m == nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "mesh not enabled")
+		return
+	}
+
+	out := MeshInbox{
+		Inbox:  m.Messaging.InboxSizes(),
+		Outbox: m.Messaging.OutboxSizes(),
+	}
+	if nodeID := strings.TrimSpace(r.URL.Query().Get("node")); nodeID != "" {
+		out.Node = &MeshNodeMessages{
+			ID:     nodeID,
+			Inbox:  m.Messaging.ReadInbox(nodeID),
+			Outbox: m.Messaging.ReadOutbox(nodeID),
+		}
+	}
+	writeJSON(w, out)
+}
+
+// MeshHistoryUsage is how full the mesh message and route histories are.
+// Rejected counts the messages refused for their content, which are kept
+// out of the history.
+type MeshHistoryUsage struct {
+	Messages mesh.HistoryUsage              `json:"messages"`
+	Routes   mesh.HistoryUsage              `json:"routes"`
+	Rejected map[mesh.MessageOutcome]uint64 `json:"rejected,omitempty"`
+}
+
+// meshHistoryUsage reports the history buffers, or false without a mesh.
+func meshHistoryUsage() (MeshHistoryUsage, bool) {
+	meshMu.RLock()
+	m := meshManager
+	meshMu.RUnlock()
+	if m == nil {
+		return MeshHistoryUsage{}, false
+	}
+	return MeshHistoryUsage{
+		Messages: m.Messaging.HistoryUsage(),
+		Routes:   m.Routing.HistoryUsage(),
+		Rejected: m.Messaging.Rejections(),
+	}, true
+}
+
+// MeshHistoryHealthCheck returns a check for core.HealthManager that is
+// degraded while a mesh history buffer sits at capacity and drops records,
+// i.e. dropped more since the previous check. A full buffer that has gone
+// quiet is healthy: nothing is being lost. Each returned check keeps its
+// own baseline, so register it once.
+func MeshHistoryHealthCheck() func() error {
+	var (
+		mu          sync.Mutex
+		lastMessage uint64
+		lastRoute   uint64
+	)
+	return func() error {
+		usage, ok := meshHistoryUsage()
+		if !ok {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var problems []string
+		if n := usage.Messages.Dropped - lastMessage; n > 0 {
+			problems = append(problems, fmt.Sprintf("message history at capacity %d dropped %d records", usage.Messages.Capacity, n))
+		}
+		if n := usage.Routes.Dropped - lastRoute; n > 0 {
+			problems = append(problems, fmt.Sprintf("route history at capacity %d dropped %d records", usage.Routes.Capacity, n))
+		}
+		lastMessage, lastRoute = usage.Messages.Dropped, usage.Routes.Dropped
+		if len(problems) > 0 {
+			return core.Degraded(fmt.Errorf("%s since last check", strings.Join(problems, "; ")))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+// kernel/api/metrics.go
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"neuroedge/kernel/core"
+)
+
+// Metrics live in the standard client_golang registry, next to its Go
+// runtime and process collectors. Request and latency metrics are updated
+// as they happen; the rest are read from their owners at scrape time by
+// kernelCollector.
+
+// Upper bounds in seconds for orchestrator call latency.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "neuroedge_http_requests_total",
+		Help: "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	orchestratorLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "neuroedge_orchestrator_call_duration_seconds",
+		Help:    "Latency of orchestrator SubmitTask calls.",
+		Buckets: latencyBuckets,
+	})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, orchestratorLatency, kernelCollector{})
+}
+
+func observeRequest(route, method string, status int) {
+	httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+}
+
+func observeOrchestratorLatency(d time.Duration) {
+	orchestratorLatency.Observe(d.Seconds())
+}
+
+var (
+	inflightDesc = prometheus.NewDesc("neuroedge_inflight_requests",
+		"Requests currently holding a concurrency slot.", nil, nil)
+	inflightLimitDesc = prometheus.NewDesc("neuroedge_inflight_limit",
+		"Configured concurrency limit.", nil, nil)
+	queueWaitingDesc = prometheus.NewDesc("neuroedge_queue_waiting",
+		"Requests waiting for a concurrency slot.", nil, nil)
+	queueOutcomesDesc = prometheus.NewDesc("neuroedge_queue_outcomes_total",
+		"Queued requests by how their wait ended.", []string{"outcome"}, nil)
+	guardBlocksDesc = prometheus.NewDesc("neuroedge_guard_blocks_total",
+		"Tasks blocked by the agent guard, by stage.", []string{"stage"}, nil)
+	eventQueueDesc = prometheus.NewDesc("neuroedge_eventbus_queue_depth",
+		"Events waiting in each subscriber queue (async bus only).", []string{"subscription", "topic"}, nil)
+)
+
+// kernelCollector reports state owned elsewhere in the kernel (limiter,
+// queue, guard, event bus) as of each scrape.
+type kernelCollector struct{}
+
+func (kernelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inflightDesc
+	ch <- inflightLimitDesc
+	ch <- queueWaitingDesc
+	ch <- queueOutcomesDesc
+	ch <- guardBlocksDesc
+	ch <- eventQueueDesc
+}
+
+func (kernelCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := getConcurrencySnapshot()
+	ch <- prometheus.MustNewConstMetric(inflightDesc, prometheus.GaugeValue, float64(snapshot.Current))
+	ch <- prometheus.MustNewConstMetric(inflightLimitDesc, prometheus.GaugeValue, float64(snapshot.Limit))
+
+	qs := ensureQueue().snapshot()
+	ch <- prometheus.MustNewConstMetric(queueWaitingDesc, prometheus.GaugeValue, float64(qs.Waiting))
+	for outcome, n := range map[string]uint64{"admitted": qs.Admitted, "timed_out": qs.TimedOut, "cancelled": qs.Cancelled, "full": qs.Full} {
+		ch <- prometheus.MustNewConstMetric(queueOutcomesDesc, prometheus.CounterValue, float64(n), outcome)
+	}
+
+	for stage, n := range core.GuardBlockCounts() {
+		ch <- prometheus.MustNewConstMetric(guardBlocksDesc, prometheus.CounterValue, float64(n), stage)
+	}
+
+	for _, q := range core.GlobalEventBus.QueueDepths() {
+		ch <- prometheus.MustNewConstMetric(eventQueueDesc, prometheus.GaugeValue, float64(q.Depth), fmt.Sprint(q.ID), q.Topic)
+	}
+}
+
+var metricsHandler = promhttp.Handler()
+
+// MetricsHandler serves the registry for Prometheus. When
+// NEUROEDGE_METRICS_KEY is set, scrapers must present it as a bearer token.
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if key := strings.TrimSpace(os.Getenv("NEUROEDGE_METRICS_KEY")); key != "" {
+		got := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(got), []byte(key)) != 1 {
+			writeError(w, r, ErrCodeUnauthorized, http.StatusUnauthorized)
+			return
+		}
+	}
+	metricsHandler.ServeHTTP(w, r)
+}
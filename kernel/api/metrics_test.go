@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	sampleLine = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(?:\{(.*)\})? (\S+)$`)
+	labelPair  = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\\n]|\\[\\"n])*)"`)
+)
+
+type metricSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parseExposition checks body against the Prometheus text format rules the
+// handler relies on and returns its samples.
+func parseExposition(t *testing.T, body string) []metricSample {
+	t.Helper()
+	types := map[string]string{}
+	var samples []metricSample
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.HasPrefix(line, "# ") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 4 || (fields[1] != "HELP" && fields[1] != "TYPE") {
+				t.Fatalf("line %d: malformed comment %q", n, line)
+			}
+			if fields[1] == "TYPE" {
+				types[fields[2]] = fields[3]
+			}
+			continue
+		}
+		m := sampleLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("line %d: malformed sample %q", n, line)
+		}
+		family := m[1]
+		if types[family] == "" {
+			family = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(family, "_bucket"), "_sum"), "_count")
+		}
+		if types[family] == "" {
+			t.Fatalf("line %d: sample %s has no preceding TYPE", n, m[1])
+		}
+
+		labels := map[string]string{}
+		for rest := m[2]; rest != ""; {
+			lm := labelPair.FindStringSubmatch(rest)
+			if lm == nil {
+				t.Fatalf("line %d: malformed labels %q", n, m[2])
+			}
+			labels[lm[1]] = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n").Replace(lm[2])
+			rest = strings.TrimPrefix(rest[len(lm[0]):], ",")
+		}
+		value, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			t.Fatalf("line %d: bad value %q", n, m[3])
+		}
+		samples = append(samples, metricSample{name: m[1], labels: labels, value: value})
+	}
+	return samples
+}
+
+func TestMetricsHandlerExpositionFormat(t *testing.T) {
+	t.Setenv("NEUROEDGE_METRICS_KEY", "")
+	route := "/v1/odd \"route\"\\\n\tname"
+	for i := 0; i < 3; i++ {
+		observeRequest(route, http.MethodGet, http.StatusTeapot)
+	}
+	observeOrchestratorLatency(200 * time.Millisecond)
+	observeOrchestratorLatency(3 * time.Second)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	samples := parseExposition(t, rec.Body.String())
+
+	var foundRoute, foundRuntime bool
+	var buckets []float64
+	var count float64
+	for _, s := range samples {
+		switch s.name {
+		case "neuroedge_http_requests_total":
+			if s.labels["route"] == route {
+				foundRoute = true
+				if s.labels["status"] != "418" || s.value < 3 {
+					t.Fatalf("odd route sample = %+v", s)
+				}
+			}
+		case "neuroedge_orchestrator_call_duration_seconds_bucket":
+			buckets = append(buckets, s.value)
+		case "neuroedge_orchestrator_call_duration_seconds_count":
+			count = s.value
+		case "go_goroutines":
+			foundRuntime = true
+		}
+	}
+	if !foundRoute {
+		t.Fatalf("route label did not round-trip through escaping:\n%s", rec.Body.String())
+	}
+	if !foundRuntime {
+		t.Fatal("Go runtime metrics from the default registry are missing")
+	}
+	if len(buckets) != len(latencyBuckets)+1 {
+		t.Fatalf("got %d buckets, want %d", len(buckets), len(latencyBuckets)+1)
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] < buckets[i-1] {
+			t.Fatalf("buckets not cumulative: %v", buckets)
+		}
+	}
+	if last := buckets[len(buckets)-1]; last != count || count < 2 {
+		t.Fatalf("+Inf bucket = %v, count = %v", last, count)
+	}
+}
+
+func TestMetricsHandlerRequiresKey(t *testing.T) {
+	t.Setenv("NEUROEDGE_METRICS_KEY", "scrape")
+	tests := []struct {
+		auth string
+		want int
+	}{
+		{auth: "", want: http.StatusUnauthorized},
+		{auth: "Bearer wrong", want: http.StatusUnauthorized},
+		{auth: "Bearer scrape", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		MetricsHandler(rec, r)
+		if rec.Code != tt.want {
+			t.Errorf("Authorization %q: status %d, want %d", tt.auth, rec.Code, tt.want)
+		}
+	}
+}
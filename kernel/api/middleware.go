@@ -2,7 +2,7 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -11,20 +11,30 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
-	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"neuroedge/kernel/core"
+	"neuroedge/kernel/tracing"
 )
 
 var reqCounter uint64
 var (
-	concurrencyOnce   sync.Once
-	concurrencyTokens chan struct{}
-	concurrencyLimit  int64
-	currentInflight   int64
+	concurrencyOnce sync.Once
+	concurrency     *slotLimiter
 )
 
 type ConcurrencySnapshot struct {
 	Current int64 `json:"current"`
 	Limit   int64 `json:"limit"`
+	// Routes holds the NEUROEDGE_ROUTE_LIMITS limiters, keyed by route
+	// without the /v1 prefix.
+	Routes map[string]ConcurrencySnapshot `json:"routes,omitempty"`
 }
 
 func withSecurityHeaders(next http.HandlerFunc) http.HandlerFunc {
@@ -37,11 +47,54 @@ func withSecurityHeaders(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// corsOrigins parses NEUROEDGE_CORS_ORIGINS. An empty result means wildcard.
+func corsOrigins() map[string]bool {
+	allowed := map[string]bool{}
+	for _, o := range strings.Split(os.Getenv("NEUROEDGE_CORS_ORIGINS"), ",") {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			allowed[o] = true
+		}
+	}
+	return allowed
+}
+
+var (
+	corsOnce    sync.Once
+	corsCurrent atomic.Pointer[map[string]bool]
+)
+
+// currentCORSOrigins is corsOrigins as of startup or the last config reload.
+func currentCORSOrigins() map[string]bool {
+	corsOnce.Do(func() {
+		load := func() {
+			allowed := corsOrigins()
+			corsCurrent.Store(&allowed)
+		}
+		load()
+		core.OnConfigChange(func(*core.Config) { load() })
+	})
+	return *corsCurrent.Load()
+}
+
+// originAllowed reports whether a browser origin may call the API.
+func originAllowed(allowed map[string]bool, origin string) bool {
+	return len(allowed) == 0 || allowed[strings.TrimRight(origin, "/")]
+}
+
 func withCORS(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		allowed := currentCORSOrigins()
+		if len(allowed) == 0 {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); origin != "" && originAllowed(allowed, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID, X-Request-Timestamp, X-Request-Signature")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -50,31 +103,106 @@ func withCORS(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withRequestID keeps the caller's X-Request-ID or generates one in the
+// NEUROEDGE_REQUEST_ID_FORMAT format; see requestIDGenerator.
 func withRequestID(next http.HandlerFunc) http.HandlerFunc {
+	newID := requestIDGenerator()
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestID := strings.TrimSpace(r.Header.Get("X-Request-ID"))
 		if requestID == "" {
-			n := atomic.AddUint64(&reqCounter, 1)
-			requestID = fmt.Sprintf("req-%d-%d", time.Now().UnixNano(), n)
+			requestID = newID()
 		}
 		w.Header().Set("X-Request-ID", requestID)
+		next(w, r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, requestID)))
+	}
+}
+
+type requestIDContextKey struct{}
+
+// requestIDFor returns the ID withRequestID assigned. It checks the response
+// header first because withPanicRecovery runs outside withRequestID and only
+// sees the original request; wrapped writers such as timeoutWriter keep their
+// own header map, so the context covers handlers behind them.
+func requestIDFor(w http.ResponseWriter, r *http.Request) string {
+	if id := w.Header().Get("X-Request-ID"); id != "" {
+		return id
+	}
+	id, _ := r.Context().Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// withBodySizeLimit caps request bodies at NEUROEDGE_MAX_BODY_BYTES (default 1 MiB).
+func withBodySizeLimit(next http.HandlerFunc) http.HandlerFunc {
+	maxBytes := int64(readIntEnv("NEUROEDGE_MAX_BODY_BYTES", 1<<20))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			writeError(w, r, ErrCodeBodyTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 		next(w, r)
 	}
 }
 
+// routePattern returns the mux route template for r, falling back to the raw
+// path. Labels and span names use it so IDs in paths don't explode cardinality.
+func routePattern(r *http.Request) string {
+	if cur := mux.CurrentRoute(r); cur != nil {
+		if tpl, err := cur.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return r.URL.Path
+}
+
+// withTracing starts a server span per request, continuing any incoming
+// traceparent, and hands the span context to downstream handlers.
+func withTracing(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		route := routePattern(r)
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r.WithContext(ctx))
+
+		span.SetAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", rec.status),
+			attribute.String("neuroedge.request_id", rec.Header().Get("X-Request-ID")),
+		)
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+}
+
+// withMetrics counts requests by route template, method and status for /metrics.
+func withMetrics(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		observeRequest(routePattern(r), r.Method, rec.status)
+	}
+}
+
 func withPanicRecovery(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
 				log.Printf("panic path=%s err=%v\n%s", r.URL.Path, rec, string(debug.Stack()))
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				writeError(w, r, ErrCodeInternal, http.StatusInternalServerError)
 			}
 		}()
 		next(w, r)
 	}
 }
 
-func withConcurrencyLimit(next http.HandlerFunc) http.HandlerFunc {
+// ensureConcurrency builds the shared limiter from NEUROEDGE_MAX_INFLIGHT on first use.
+func ensureConcurrency() *slotLimiter {
 	concurrencyOnce.Do(func() {
 		limit := 200
 		if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_MAX_INFLIGHT")); raw != "" {
@@ -82,28 +210,13 @@ func withConcurrencyLimit(next http.HandlerFunc) http.HandlerFunc {
 				limit = n
 			}
 		}
-		concurrencyTokens = make(chan struct{}, limit)
-		atomic.StoreInt64(&concurrencyLimit, int64(limit))
+		concurrency = newSlotLimiter(limit)
 	})
-	return func(w http.ResponseWriter, r *http.Request) {
-		select {
-		case concurrencyTokens <- struct{}{}:
-			atomic.AddInt64(&currentInflight, 1)
-			defer func() {
-				<-concurrencyTokens
-				atomic.AddInt64(&currentInflight, -1)
-			}()
-			next(w, r)
-		default:
-			w.Header().Set("Retry-After", "1")
-			http.Error(w, "service overloaded", http.StatusServiceUnavailable)
-		}
-	}
+	return concurrency
 }
 
 func getConcurrencySnapshot() ConcurrencySnapshot {
-	return ConcurrencySnapshot{
-		Current: atomic.LoadInt64(&currentInflight),
-		Limit:   atomic.LoadInt64(&concurrencyLimit),
-	}
+	snapshot := ensureConcurrency().snapshot()
+	snapshot.Routes = routeConcurrencySnapshot()
+	return snapshot
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOversizedBodyIs413(t *testing.T) {
+	t.Setenv("NEUROEDGE_API_KEY", "k")
+	t.Setenv("NEUROEDGE_MAX_BODY_BYTES", "128")
+	router := NewRouter()
+	body := `{"id":"big","type":"chat","payload":{"message":"` + strings.Repeat("x", 256) + `"}}`
+
+	tests := []struct {
+		name string
+		// chunked hides the length, so only MaxBytesReader can catch it.
+		chunked bool
+	}{
+		{name: "declared length"},
+		{name: "chunked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r io.Reader = strings.NewReader(body)
+			if tt.chunked {
+				r = io.MultiReader(r)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/v1/execute", r)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			req.Header.Set("X-API-Key", "k")
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want 413: %s", rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), string(ErrCodeBodyTooLarge)) {
+				t.Fatalf("body = %s, want %s", rec.Body, ErrCodeBodyTooLarge)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// kernel/api/nodes.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"neuroedge/kernel/discovery"
+)
+
+type nodeRegistration struct {
+	ID           string   `json:"id"`
+	Address      string   `json:"address"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// NodeRegisterHandler lets a mesh node announce itself. Registering an
+// existing ID replaces its address and capabilities.
+func NodeRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req nodeRegistration
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	if req.ID == "" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "node id required")
+		return
+	}
+	node, err := discovery.RegisterNode(req.ID, strings.TrimSpace(req.Address), req.Capabilities)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "%v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, node)
+}
+
+// NodeHeartbeatHandler refreshes a registered node's last-seen time.
+func NodeHeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	node, err := discovery.Heartbeat(mux.Vars(r)["id"])
+	if errors.Is(err, discovery.ErrUnknownNode) {
+		writeErrorf(w, r, ErrCodeNotFound, http.StatusNotFound, "node not registered")
+		return
+	}
+	if err != nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "%v", err)
+		return
+	}
+	writeJSON(w, node)
+}
+
+// nodeWatchKeepAlive is how often an idle watch stream sends an SSE comment
+// so proxies keep the connection open.
+const nodeWatchKeepAlive = 15 * time.Second
+
+// NodesWatchHandler streams node changes as Server-Sent Events. Each event
+// is named after its type (added, removed or updated) and carries
+// {"type": ..., "node": {...}} with the node's state after the change.
+func NodesWatchHandler(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	events, stop := discovery.WatchNodes()
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	keepAlive := time.NewTicker(nodeWatchKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = w.Write([]byte(": keep-alive\n\n"))
+		case evt := <-events:
+			data, _ := json.Marshal(evt)
+			writeSSE(w, evt.Type, string(data))
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// kernel/api/openapi.go
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes the routes in NewRouter. It is maintained by hand:
+// update it alongside any route, request or response change.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPIHandler serves the kernel's OpenAPI 3 document for client generation.
+func OpenAPIHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
@@ -0,0 +1,28 @@
+// kernel/api/policy.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"neuroedge/kernel/core"
+)
+
+type policyCheckRequest struct {
+	Action  string                 `json:"action"`
+	Context map[string]interface{} `json:"context"`
+}
+
+// PolicyCheckHandler runs an action through the ethics and cognition guards
+// without executing it, so clients can warn before submitting.
+func PolicyCheckHandler(w http.ResponseWriter, r *http.Request) {
+	var req policyCheckRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Action) == "" {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "action required")
+		return
+	}
+	writeJSON(w, core.CheckPolicy(req.Action, req.Context))
+}
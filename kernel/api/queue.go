@@ -0,0 +1,160 @@
+// kernel/api/queue.go
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bounded backpressure queue in front of the concurrency limiter. A short
+// burst that finds every slot taken waits up to NEUROEDGE_QUEUE_WAIT for one
+// instead of failing at once; at most NEUROEDGE_QUEUE_DEPTH requests wait at
+// a time, so queueing cannot grow latency without bound. With no wait
+// configured it behaves exactly like withPriorityConcurrency.
+
+const defaultQueueDepth = 100
+
+type requestQueue struct {
+	wait  time.Duration
+	depth int64
+
+	mu        sync.Mutex
+	waiting   int64
+	queued    uint64
+	admitted  uint64
+	timedOut  uint64
+	cancelled uint64
+	full      uint64
+	waitTotal time.Duration
+	waitMax   time.Duration
+}
+
+var (
+	queueOnce sync.Once
+	queue     *requestQueue
+)
+
+// ensureQueue builds the shared queue from NEUROEDGE_QUEUE_WAIT (Go duration
+// or seconds, default 0 = fail fast) and NEUROEDGE_QUEUE_DEPTH (default 100)
+// on first use.
+func ensureQueue() *requestQueue {
+	queueOnce.Do(func() {
+		q := &requestQueue{depth: int64(readIntEnv("NEUROEDGE_QUEUE_DEPTH", defaultQueueDepth))}
+		if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_QUEUE_WAIT")); raw != "" {
+			if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+				q.wait = d
+			} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+				q.wait = time.Duration(secs) * time.Second
+			}
+		}
+		queue = q
+	})
+	return queue
+}
+
+// enter claims a place in the queue, or reports it full.
+func (q *requestQueue) enter() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.waiting >= q.depth {
+		q.full++
+		return false
+	}
+	q.waiting++
+	q.queued++
+	return true
+}
+
+// leave gives the place back and records how the wait ended.
+func (q *requestQueue) leave(waited time.Duration, counter *uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.waiting--
+	*counter++
+	q.waitTotal += waited
+	if waited > q.waitMax {
+		q.waitMax = waited
+	}
+}
+
+// QueueSnapshot reports the backpressure queue's configuration and counters
+// since startup.
+type QueueSnapshot struct {
+	Waiting   int64   `json:"waiting"`
+	Depth     int64   `json:"depth"`
+	MaxWaitMs int64   `json:"max_wait_ms"`
+	Queued    uint64  `json:"queued"`
+	Admitted  uint64  `json:"admitted"`
+	TimedOut  uint64  `json:"timed_out"`
+	Cancelled uint64  `json:"cancelled"`
+	Full      uint64  `json:"rejected_full"`
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+	// LongestWaitMs is the longest any queued request waited, however it ended.
+	LongestWaitMs int64 `json:"longest_wait_ms"`
+}
+
+func (q *requestQueue) snapshot() QueueSnapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := QueueSnapshot{
+		Waiting:       q.waiting,
+		Depth:         q.depth,
+		MaxWaitMs:     q.wait.Milliseconds(),
+		Queued:        q.queued,
+		Admitted:      q.admitted,
+		TimedOut:      q.timedOut,
+		Cancelled:     q.cancelled,
+		Full:          q.full,
+		LongestWaitMs: q.waitMax.Milliseconds(),
+	}
+	if done := q.admitted + q.timedOut + q.cancelled; done > 0 {
+		out.AvgWaitMs = float64(q.waitTotal.Microseconds()) / float64(done) / 1000
+	}
+	return out
+}
+
+// withBoundedQueue is withPriorityConcurrency that waits for a slot rather
+// than shedding at once. Latency-sensitive routes that would rather get a
+// fast 503 keep using withPriorityConcurrency.
+func withBoundedQueue(next http.HandlerFunc) http.HandlerFunc {
+	limiter := ensureConcurrency()
+	q := ensureQueue()
+	reserved := int64(readIntEnv("NEUROEDGE_RESERVED_SLOTS", 0))
+	return func(w http.ResponseWriter, r *http.Request) {
+		reserve := priorityReserve(r, reserved)
+		ok, freed := limiter.acquireOrNotify(reserve)
+		if !ok && q.wait > 0 && q.enter() {
+			started := time.Now()
+			timer := time.NewTimer(q.wait)
+			ok = func() bool {
+				defer timer.Stop()
+				for {
+					select {
+					case <-freed:
+						if ok, freed = limiter.acquireOrNotify(reserve); ok {
+							q.leave(time.Since(started), &q.admitted)
+							return true
+						}
+					case <-timer.C:
+						q.leave(time.Since(started), &q.timedOut)
+						return false
+					case <-r.Context().Done():
+						q.leave(time.Since(started), &q.cancelled)
+						return false
+					}
+				}
+			}()
+		}
+		if !ok {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, ErrCodeOverloaded, http.StatusServiceUnavailable)
+			return
+		}
+		defer limiter.release()
+		next(w, r)
+	}
+}
@@ -2,69 +2,118 @@
 package handlers
 
 import (
-	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"neuroedge/kernel/core"
 )
 
-type visitor struct {
-	count      int
-	windowFrom time.Time
-	lastSeen   time.Time
+// bucket is one identity's token bucket.
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
 }
 
 var (
 	rateLimitMu sync.Mutex
-	visitors    = map[string]*visitor{}
+	buckets     = map[string]*bucket{}
 )
 
-func withRateLimit(next http.HandlerFunc) http.HandlerFunc {
-	maxPerMinute := readIntEnv("NEUROEDGE_RATE_LIMIT_PER_MIN", 60)
+// rateLimitConfig reads NEUROEDGE_RATE (tokens per second) and
+// NEUROEDGE_BURST (bucket size). Without them the older
+// NEUROEDGE_RATE_LIMIT_PER_MIN sets both: that many requests per minute,
+// all of which may arrive at once.
+func rateLimitConfig() (rate float64, burst int) {
+	perMinute := readIntEnv("NEUROEDGE_RATE_LIMIT_PER_MIN", 60)
+	rate = float64(perMinute) / 60
+	if v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("NEUROEDGE_RATE")), 64); err == nil && v > 0 {
+		rate = v
+	}
+	return rate, readIntEnv("NEUROEDGE_BURST", perMinute)
+}
 
-	return func(w http.ResponseWriter, r *http.Request) {
-		now := time.Now()
-		ip := clientIP(r)
-
-		rateLimitMu.Lock()
-		v, exists := visitors[ip]
-		if !exists {
-			v = &visitor{windowFrom: now, lastSeen: now}
-			visitors[ip] = v
-		}
+// rateLimitSettings is one rateLimitConfig reading.
+type rateLimitSettings struct {
+	rate  float64
+	burst int
+}
 
-		if now.Sub(v.windowFrom) >= time.Minute {
-			v.count = 0
-			v.windowFrom = now
-		}
+var (
+	rateLimitOnce    sync.Once
+	rateLimitCurrent atomic.Pointer[rateLimitSettings]
+)
 
-		v.lastSeen = now
-		allowed := v.count < maxPerMinute
-		if allowed {
-			v.count++
-		}
-		remaining := maxPerMinute - v.count
-		if remaining < 0 {
-			remaining = 0
+// currentRateLimit is rateLimitConfig as of startup or the last config
+// reload. Rate and burst are swapped together, so a request never sees one
+// old value and one new.
+func currentRateLimit() (float64, int) {
+	rateLimitOnce.Do(func() {
+		load := func() {
+			rate, burst := rateLimitConfig()
+			rateLimitCurrent.Store(&rateLimitSettings{rate: rate, burst: burst})
 		}
-		resetIn := int(time.Minute.Seconds() - now.Sub(v.windowFrom).Seconds())
-		if resetIn < 0 {
-			resetIn = 0
+		load()
+		core.OnConfigChange(func(*core.Config) { load() })
+	})
+	s := rateLimitCurrent.Load()
+	return s.rate, s.burst
+}
+
+// rateLimitKey identifies the caller: a valid API key gets its own bucket,
+// anything else is limited by client IP. Unverified keys are not trusted as
+// identities, or rotating made-up keys would dodge the IP limit.
+func rateLimitKey(r *http.Request) string {
+	if got := presentedAPIKey(r); got != "" {
+		if info, ok := resolveAPIKey(got); ok {
+			return "key:" + info.KeyID
 		}
-		cleanupVisitors(now)
-		rateLimitMu.Unlock()
+	}
+	return "ip:" + clientIP(r)
+}
 
-		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(maxPerMinute))
-		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetIn))
+// takeToken refills key's bucket for the time since it was last seen and
+// spends one token if available. It returns the tokens left and, when
+// refused, how long until the next token.
+func takeToken(key string, now time.Time, rate float64, burst int) (bool, float64, time.Duration) {
+	rateLimitMu.Lock()
+	defer rateLimitMu.Unlock()
+
+	b, ok := buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), lastSeen: now}
+		buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.lastSeen).Seconds()*rate)
+	b.lastSeen = now
+	cleanupBuckets(now, rate, burst)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, b.tokens, wait
+	}
+	b.tokens--
+	return true, b.tokens, 0
+}
+
+func withRateLimit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rate, burst := currentRateLimit()
+		allowed, left, wait := takeToken(rateLimitKey(r), time.Now(), rate, burst)
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(left)))
 
 		if !allowed {
-			w.Header().Set("Retry-After", strconv.Itoa(resetIn))
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, r, ErrCodeRateLimited, http.StatusTooManyRequests)
 			return
 		}
 
@@ -72,21 +121,57 @@ func withRateLimit(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func cleanupVisitors(now time.Time) {
-	for ip, v := range visitors {
-		if now.Sub(v.lastSeen) > 10*time.Minute {
-			delete(visitors, ip)
+// RateLimitIdentity is one caller's bucket as seen by the limiter.
+type RateLimitIdentity struct {
+	Key       string    `json:"key"`
+	Remaining float64   `json:"remaining"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// rateLimitIdentities returns up to n tracked identities closest to being
+// throttled (fewest tokens left, then most recently seen), with tokens
+// refilled to now. It only reads the buckets.
+func rateLimitIdentities(now time.Time, rate float64, burst, n int) []RateLimitIdentity {
+	rateLimitMu.Lock()
+	out := make([]RateLimitIdentity, 0, len(buckets))
+	for key, b := range buckets {
+		out = append(out, RateLimitIdentity{
+			Key:       key,
+			Remaining: math.Round(math.Min(float64(burst), b.tokens+now.Sub(b.lastSeen).Seconds()*rate)*100) / 100,
+			LastSeen:  b.lastSeen,
+		})
+	}
+	rateLimitMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Remaining != out[j].Remaining {
+			return out[i].Remaining < out[j].Remaining
+		}
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// cleanupBuckets drops buckets idle long enough to have refilled completely;
+// recreating them later is equivalent. Caller must hold rateLimitMu.
+func cleanupBuckets(now time.Time, rate float64, burst int) {
+	idle := time.Duration(float64(burst)/rate*float64(time.Second)) + time.Minute
+	for key, b := range buckets {
+		if now.Sub(b.lastSeen) > idle {
+			delete(buckets, key)
 		}
 	}
 }
 
+// clientIP is the caller's address for rate limiting and logs, resolved
+// like the IP filter does: X-Forwarded-For counts only behind a trusted
+// proxy, so a client can't pick a fresh identity per request.
 func clientIP(r *http.Request) string {
-	xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For"))
-	if xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
-		}
+	if addr, ok := filterClientIP(r, trustProxy()); ok {
+		return addr.String()
 	}
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetBuckets gives a test an empty limiter.
+func resetBuckets(t *testing.T) {
+	t.Helper()
+	rateLimitMu.Lock()
+	buckets = map[string]*bucket{}
+	rateLimitMu.Unlock()
+	t.Cleanup(func() {
+		rateLimitMu.Lock()
+		buckets = map[string]*bucket{}
+		rateLimitMu.Unlock()
+	})
+}
+
+func TestTakeTokenIsolatesIdentities(t *testing.T) {
+	const rate, burst = 1.0, 3
+	start := time.Unix(1_700_000_000, 0)
+
+	type take struct {
+		key   string
+		after time.Duration
+		want  bool
+	}
+	tests := []struct {
+		name  string
+		takes []take
+	}{
+		{
+			name: "exhausting one key leaves another untouched",
+			takes: []take{
+				{"key:a", 0, true}, {"key:a", 0, true}, {"key:a", 0, true}, {"key:a", 0, false},
+				{"key:b", 0, true}, {"key:b", 0, true}, {"key:b", 0, true}, {"key:b", 0, false},
+			},
+		},
+		{
+			name: "key and ip buckets are separate",
+			takes: []take{
+				{"ip:10.0.0.1", 0, true}, {"ip:10.0.0.1", 0, true}, {"ip:10.0.0.1", 0, true}, {"ip:10.0.0.1", 0, false},
+				{"key:a", 0, true},
+			},
+		},
+		{
+			name: "refill is per key",
+			takes: []take{
+				{"key:a", 0, true}, {"key:a", 0, true}, {"key:a", 0, true}, {"key:a", 0, false},
+				{"key:a", time.Second, true}, {"key:a", time.Second, false},
+				{"key:b", time.Second, true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetBuckets(t)
+			for i, tk := range tt.takes {
+				got, _, wait := takeToken(tk.key, start.Add(tk.after), rate, burst)
+				if got != tk.want {
+					t.Fatalf("take %d (%s at +%s) = %v, want %v", i, tk.key, tk.after, got, tk.want)
+				}
+				if !got && wait <= 0 {
+					t.Fatalf("take %d refused without a wait", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	t.Setenv("NEUROEDGE_API_KEY", "k")
+	t.Setenv("NEUROEDGE_API_KEYS", "k2")
+
+	tests := []struct {
+		name, apiKey, xff, trustProxy, want string
+	}{
+		{name: "valid key", apiKey: "k", want: "key:env-"},
+		{name: "second valid key", apiKey: "k2", want: "key:env-"},
+		{name: "made-up key falls back to ip", apiKey: "forged", want: "ip:192.0.2.1"},
+		{name: "no key", want: "ip:192.0.2.1"},
+		{name: "forwarded for ignored without trusted proxy", xff: "203.0.113.7", want: "ip:192.0.2.1"},
+		{name: "forwarded for behind trusted proxy", xff: "203.0.113.7, 10.0.0.1", trustProxy: "true", want: "ip:203.0.113.7"},
+	}
+	seen := map[string]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEUROEDGE_TRUST_PROXY", tt.trustProxy)
+			r := httptest.NewRequest(http.MethodGet, "/v1/kernel/health", nil)
+			if tt.apiKey != "" {
+				r.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			got := rateLimitKey(r)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Fatalf("rateLimitKey = %q, want prefix %q", got, tt.want)
+			}
+			if tt.apiKey != "" && !strings.HasPrefix(tt.want, "ip:") {
+				for other, key := range seen {
+					if key == got {
+						t.Fatalf("keys %q and %q share bucket %q", other, tt.apiKey, got)
+					}
+				}
+				seen[tt.apiKey] = got
+			}
+		})
+	}
+}
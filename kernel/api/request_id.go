@@ -0,0 +1,111 @@
+// kernel/api/request_id.go
+package handlers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	requestIDOnce sync.Once
+	requestIDFunc func() string
+)
+
+// requestIDGenerator returns the ID generator NEUROEDGE_REQUEST_ID_FORMAT
+// selects: "ulid" for ULIDs, anything else for req-<nanos>-<counter>.
+func requestIDGenerator() func() string {
+	requestIDOnce.Do(func() {
+		requestIDFunc = defaultRequestID
+		switch format := strings.ToLower(strings.TrimSpace(os.Getenv("NEUROEDGE_REQUEST_ID_FORMAT"))); format {
+		case "ulid":
+			requestIDFunc = newULID
+		case "", "default":
+		default:
+			fmt.Printf("⚠️ Unknown NEUROEDGE_REQUEST_ID_FORMAT %q, using default request IDs\n", format)
+		}
+	})
+	return requestIDFunc
+}
+
+func defaultRequestID() string {
+	n := atomic.AddUint64(&reqCounter, 1)
+	return fmt.Sprintf("req-%d-%d", time.Now().UnixNano(), n)
+}
+
+// crockford is the ULID alphabet: Crockford base32, which sorts in byte
+// order.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidState struct {
+	mu      sync.Mutex
+	lastMS  uint64
+	entropy [10]byte
+}
+
+// newULID returns a 26-character ULID: a 48-bit millisecond timestamp
+// followed by 80 random bits. IDs made within the same millisecond reuse
+// the previous random part plus one, as in the spec's monotonic mode, so
+// IDs from one kernel are unique and strictly increasing even under heavy
+// concurrency. A clock stepping backwards keeps the last timestamp rather
+// than going back with it.
+func newULID() string {
+	ulidState.mu.Lock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms > ulidState.lastMS {
+		ulidState.lastMS = ms
+		if _, err := rand.Read(ulidState.entropy[:]); err != nil {
+			panic(fmt.Sprintf("ulid entropy: %v", err))
+		}
+	} else if !incrementEntropy(&ulidState.entropy) {
+		// 2^80 IDs in one millisecond; borrow the next one.
+		ulidState.lastMS++
+	}
+	var id [16]byte
+	ms = ulidState.lastMS
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(ms)
+		ms >>= 8
+	}
+	copy(id[6:], ulidState.entropy[:])
+	ulidState.mu.Unlock()
+	return encodeULID(id)
+}
+
+// incrementEntropy adds one to the big-endian random part and reports
+// false if it wrapped around to zero.
+func incrementEntropy(e *[10]byte) bool {
+	for i := len(e) - 1; i >= 0; i-- {
+		e[i]++
+		if e[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID writes the 128 bits as 26 base32 digits, the first carrying
+// only the top 3 bits.
+func encodeULID(id [16]byte) string {
+	var out [26]byte
+	// Walk from the least significant end, five bits at a time.
+	var acc uint16
+	bits := 0
+	pos := len(out) - 1
+	for i := len(id) - 1; i >= 0; i-- {
+		acc |= uint16(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			out[pos] = crockford[acc&31]
+			pos--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	out[0] = crockford[acc&31]
+	return string(out[:])
+}
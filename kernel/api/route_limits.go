@@ -0,0 +1,86 @@
+// kernel/api/route_limits.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	routeLimitsOnce sync.Once
+	routeLimiters   map[string]*slotLimiter
+)
+
+// ensureRouteLimits parses NEUROEDGE_ROUTE_LIMITS, a comma-separated list of
+// route=limit pairs such as "/execute=20,/kernel/jobs/{id}=5". Routes are mux
+// path templates and may be written with or without the /v1 prefix; both
+// forms of a route share one limiter. Malformed entries are logged and
+// skipped.
+func ensureRouteLimits() map[string]*slotLimiter {
+	routeLimitsOnce.Do(func() {
+		routeLimiters = map[string]*slotLimiter{}
+		for _, entry := range strings.Split(os.Getenv("NEUROEDGE_ROUTE_LIMITS"), ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			route, raw, ok := strings.Cut(entry, "=")
+			n, err := strconv.Atoi(strings.TrimSpace(raw))
+			route = routeLimitKey(strings.TrimSpace(route))
+			if !ok || err != nil || n <= 0 || route == "" {
+				fmt.Printf("⚠️ Ignoring NEUROEDGE_ROUTE_LIMITS entry %q: want /route=positive-limit\n", entry)
+				continue
+			}
+			routeLimiters[route] = newSlotLimiter(n)
+		}
+	})
+	return routeLimiters
+}
+
+// routeLimitKey folds /v1/x and the legacy /x alias into one key.
+func routeLimitKey(route string) string {
+	if rest, ok := strings.CutPrefix(route, "/v1/"); ok {
+		return "/" + rest
+	}
+	return route
+}
+
+// withRouteConcurrency caps inflight requests per route on top of the
+// global limit, so one busy route cannot take every slot. Requests over
+// their route's limit are shed at once with 503, before they wait in the
+// global queue; routes without a configured limit pass straight through.
+func withRouteConcurrency(next http.HandlerFunc) http.HandlerFunc {
+	limiters := ensureRouteLimits()
+	return func(w http.ResponseWriter, r *http.Request) {
+		limiter := limiters[routeLimitKey(routePattern(r))]
+		if limiter == nil {
+			next(w, r)
+			return
+		}
+		if !limiter.tryAcquire() {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, ErrCodeOverloaded, http.StatusServiceUnavailable)
+			return
+		}
+		defer limiter.release()
+		next(w, r)
+	}
+}
+
+// routeConcurrencySnapshot reports inflight against the limit for every
+// route in NEUROEDGE_ROUTE_LIMITS, or nil when none are configured.
+func routeConcurrencySnapshot() map[string]ConcurrencySnapshot {
+	limiters := ensureRouteLimits()
+	if len(limiters) == 0 {
+		return nil
+	}
+	out := make(map[string]ConcurrencySnapshot, len(limiters))
+	for route, limiter := range limiters {
+		out[route] = limiter.snapshot()
+	}
+	return out
+}
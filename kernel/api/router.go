@@ -4,12 +4,12 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"os"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+
+	"neuroedge/kernel/core"
 )
 
 func chain(next http.HandlerFunc, mws ...func(http.HandlerFunc) http.HandlerFunc) http.HandlerFunc {
@@ -21,21 +21,36 @@ func chain(next http.HandlerFunc, mws ...func(http.HandlerFunc) http.HandlerFunc
 }
 
 func secureHandler(next http.HandlerFunc) http.HandlerFunc {
+	// The timeout sits innermost so a timed-out request releases its concurrency slot.
+	return streamHandler(withGzip(withTimeout(next)))
+}
+
+// streamHandler is secureHandler without the request timeout, for long-lived
+// SSE and WebSocket responses that manage their own lifetimes.
+func streamHandler(next http.HandlerFunc) http.HandlerFunc {
 	return chain(
 		next,
 		withCORS,
 		withPanicRecovery,
 		withRequestID,
+		withIPFilter,
+		withTracing,
+		withMetrics,
 		withSecurityHeaders,
-		withRequestLogging,
-		withConcurrencyLimit,
+		withLogging,
+		withBodySizeLimit,
 		withRateLimit,
+		// Auth comes before the slot middlewares so only authenticated
+		// callers can claim priority (see requestPriority).
 		withAPIKeyAuth,
+		withRouteConcurrency,
+		withBoundedQueue,
+		withRequestSignature,
 	)
 }
 
 func publicHandler(next http.HandlerFunc) http.HandlerFunc {
-	return chain(next, withCORS, withPanicRecovery, withRequestID, withSecurityHeaders, withRequestLogging, withConcurrencyLimit)
+	return chain(next, withCORS, withPanicRecovery, withRequestID, withTracing, withMetrics, withSecurityHeaders, withLogging, withPriorityConcurrency, withGzip)
 }
 
 func NewRouter() *mux.Router {
@@ -59,35 +74,116 @@ func NewRouter() *mux.Router {
 		runtime.ReadMemStats(&mem)
 		snapshot := getConcurrencySnapshot()
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"status":      "ok",
-			"service":     "kernel",
-			"time":        time.Now().UTC().Format(time.RFC3339),
-			"goroutines":  runtime.NumGoroutine(),
-			"allocBytes":  mem.Alloc,
-			"sysBytes":    mem.Sys,
-			"inflight":    snapshot.Current,
-			"inflightMax": snapshot.Limit,
-		})
+		details := map[string]any{
+			"status":       "ok",
+			"service":      "kernel",
+			"time":         time.Now().UTC().Format(time.RFC3339),
+			"goroutines":   runtime.NumGoroutine(),
+			"allocBytes":   mem.Alloc,
+			"sysBytes":     mem.Sys,
+			"inflight":     snapshot.Current,
+			"inflightMax":  snapshot.Limit,
+			"orchestrator": orchestratorStatus(),
+			"maintenance":  Maintenance().Enabled,
+		}
+		if usage, ok := meshHistoryUsage(); ok {
+			details["meshHistory"] = usage
+		}
+		for k, v := range buildInfo() {
+			details[k] = v
+		}
+		_ = json.NewEncoder(w).Encode(details)
 	})).Methods("GET")
 
-	// Ready means process is up and required auth config is present.
-	r.HandleFunc("/readyz", publicHandler(func(w http.ResponseWriter, _ *http.Request) {
-		if strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEY")) == "" {
-			http.Error(w, "not ready", http.StatusServiceUnavailable)
+	// Build identity for deployment debugging.
+	r.HandleFunc("/version", publicHandler(VersionHandler)).Methods("GET")
+
+	// Ready means process is up, required auth config is present and every
+	// component registered with core.GlobalReadiness (the engines, in
+	// cmd/api) reports ready within NEUROEDGE_READINESS_TIMEOUT_MS.
+	// ?deep=true additionally requires the orchestrator to answer a ping.
+	r.HandleFunc("/readyz", publicHandler(func(w http.ResponseWriter, r *http.Request) {
+		if shuttingDown.Load() {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "shutting down")
 			return
 		}
+		if !authConfigured() {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "not ready")
+			return
+		}
+		if notReady := core.GlobalReadiness.Check(readinessTimeout()); len(notReady) > 0 {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "not ready: %s", core.NotReadySummary(notReady))
+			return
+		}
+		if r.URL.Query().Get("deep") == "true" {
+			if err := PingOrchestrator(r.Context()); err != nil {
+				writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "orchestrator unreachable: %v", err)
+				return
+			}
+		}
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ready"))
 	})).Methods("GET")
 
-	// Protected kernel routes
-	r.HandleFunc("/kernel/health", secureHandler(HealthHandler)).Methods("GET")
-	r.HandleFunc("/kernel/nodes", secureHandler(NodesHandler)).Methods("GET")
-	r.HandleFunc("/kernel/capabilities", secureHandler(CapabilitiesHandler)).Methods("GET")
-	r.HandleFunc("/chat", secureHandler(ChatCommandHandler)).Methods("POST")
-	r.HandleFunc("/execute", secureHandler(ExecuteHandler)).Methods("POST")
-	r.HandleFunc("/events", secureHandler(EventIngestHandler)).Methods("POST")
+	// Machine-readable API description for SDK generation.
+	r.HandleFunc("/openapi.json", publicHandler(OpenAPIHandler)).Methods("GET")
+
+	// Prometheus scrape target; optionally gated by NEUROEDGE_METRICS_KEY.
+	r.HandleFunc("/metrics", publicHandler(MetricsHandler)).Methods("GET")
+
+	// Kernel and command routes live under /v1. The unprefixed paths they
+	// had before versioning remain as deprecated aliases.
+	registerV1Routes(r, "/v1", nil)
+	registerV1Routes(r, "", deprecatedAlias("/v1"))
 
 	return r
 }
+
+// registerV1Routes registers the v1 kernel and command routes on r under
+// prefix, each wrapped by wrap when it is non-nil. A future /v2 gets its own
+// register function called from NewRouter with "/v2". Routes go on r itself
+// rather than a PathPrefix subrouter, which would answer a wrong method with
+// 404 instead of 405.
+func registerV1Routes(r *mux.Router, prefix string, wrap func(http.HandlerFunc) http.HandlerFunc) {
+	handle := func(path string, h http.HandlerFunc, methods ...string) {
+		if wrap != nil {
+			h = wrap(h)
+		}
+		r.HandleFunc(prefix+path, h).Methods(methods...)
+	}
+
+	// Protected kernel routes. Any valid key may read; mutating routes need
+	// write scope and runtime tuning needs admin.
+	handle("/kernel/health", secureHandler(HealthHandler), "GET")
+	handle("/kernel/nodes", secureHandler(NodesHandler), "GET")
+	handle("/kernel/stats", secureHandler(StatsHandler), "GET")
+	handle("/kernel/nodes/register", secureHandler(requireScope(ScopeWrite, NodeRegisterHandler)), "POST")
+	handle("/kernel/nodes/{id}/heartbeat", secureHandler(requireScope(ScopeWrite, NodeHeartbeatHandler)), "POST")
+	handle("/kernel/capabilities", secureHandler(CapabilitiesHandler), "GET")
+	handle("/kernel/route", secureHandler(requireScope(ScopeWrite, CapabilityRouteHandler)), "POST")
+	handle("/kernel/concurrency", secureHandler(requireScope(ScopeAdmin, ConcurrencyHandler)), "GET", "POST")
+	handle("/kernel/limits", secureHandler(requireScope(ScopeAdmin, LimitsHandler)), "GET")
+	handle("/kernel/maintenance", secureHandler(MaintenanceHandler), "GET")
+	handle("/kernel/maintenance", secureHandler(requireScope(ScopeAdmin, MaintenanceHandler)), "POST")
+	handle("/kernel/shutdown", secureHandler(requireScope(ScopeAdmin, ShutdownHandler)), "POST")
+	handle("/kernel/audit", secureHandler(requireScope(ScopeAdmin, AuditHandler)), "GET")
+	handle("/kernel/policy/check", secureHandler(PolicyCheckHandler), "POST")
+	handle("/kernel/cognition/history", secureHandler(requireScope(ScopeAdmin, CognitionHistoryHandler)), "GET")
+	handle("/kernel/mesh/export", secureHandler(requireScope(ScopeAdmin, MeshExportHandler)), "GET")
+	handle("/kernel/mesh/rotation", secureHandler(requireScope(ScopeAdmin, MeshRotationHandler)), "GET")
+	handle("/kernel/mesh/topology", secureHandler(requireScope(ScopeAdmin, MeshTopologyHandler)), "GET")
+	handle("/kernel/mesh/inbox", secureHandler(requireScope(ScopeAdmin, MeshInboxHandler)), "GET")
+	handle("/kernel/optimizer/recent", secureHandler(OptimizerRecentHandler), "GET")
+	handle("/kernel/scaler/recent", secureHandler(ScalerRecentHandler), "GET")
+	handle("/chat", secureHandler(requireScope(ScopeWrite, rejectDuringMaintenance(ChatCommandHandler))), "POST")
+	handle("/chat/stream", streamHandler(requireScope(ScopeWrite, rejectDuringMaintenance(ChatStreamHandler))), "POST")
+	handle("/chat/ws", streamHandler(requireScope(ScopeWrite, rejectDuringMaintenance(ChatWebSocketHandler))), "GET")
+	handle("/kernel/nodes/watch", streamHandler(NodesWatchHandler), "GET")
+	handle("/kernel/events/stream", streamHandler(requireScope(ScopeAdmin, EventsStreamHandler)), "GET")
+	handle("/execute", secureHandler(requireScope(ScopeWrite, rejectDuringMaintenance(ExecuteHandler))), "POST")
+	handle("/execute/batch", secureHandler(requireScope(ScopeWrite, rejectDuringMaintenance(ExecuteBatchHandler))), "POST")
+	handle("/kernel/jobs/{id}", secureHandler(JobStatusHandler), "GET")
+	handle("/kernel/jobs/{id}", secureHandler(requireScope(ScopeWrite, JobCancelHandler)), "DELETE")
+	handle("/kernel/jobs/{id}/stream", streamHandler(JobStreamHandler), "GET")
+	handle("/events", secureHandler(requireScope(ScopeWrite, rejectDuringMaintenance(EventIngestHandler))), "POST")
+}
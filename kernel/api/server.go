@@ -0,0 +1,215 @@
+// kernel/api/server.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"neuroedge/kernel/core"
+)
+
+// shuttingDown flips when Server.Shutdown starts so /readyz drops the kernel
+// out of load-balancer rotation while inflight requests drain.
+var shuttingDown atomic.Bool
+
+// drainRequested is closed by RequestDrain to make Run shut down as if it had
+// received SIGTERM.
+var (
+	drainOnce      sync.Once
+	drainRequested = make(chan struct{})
+)
+
+// RequestDrain takes the kernel out of rotation and asks Run to shut down
+// gracefully. It reports false if a shutdown was already under way.
+func RequestDrain() bool {
+	if !shuttingDown.CompareAndSwap(false, true) {
+		return false
+	}
+	drainOnce.Do(func() { close(drainRequested) })
+	return true
+}
+
+// Server wraps http.Server with signal handling and an ordered drain:
+// stop accepting, wait for inflight requests, then run shutdown hooks.
+type Server struct {
+	http *http.Server
+
+	mu    sync.Mutex
+	hooks []func(context.Context) error
+}
+
+// NewServer wraps srv; its Handler is normally NewRouter().
+func NewServer(srv *http.Server) *Server {
+	return &Server{http: srv}
+}
+
+// OnShutdown registers fn to run after requests have drained, e.g. closing
+// the orchestrator client or flushing buffers. Hooks run in reverse
+// registration order, like defers.
+func (s *Server) OnShutdown(fn func(context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// Run serves until SIGINT or SIGTERM, then shuts down within timeout. SIGHUP
+// reloads the config file (see core.ReloadConfig) without interrupting it. It
+// serves HTTPS when NEUROEDGE_TLS_CERT and NEUROEDGE_TLS_KEY are set (see
+// tlsConfigFromEnv) and plain HTTP otherwise.
+func (s *Server) Run(timeout time.Duration) error {
+	tlsConfig, err := tlsConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if _, err := core.ReloadConfig(); err != nil {
+					fmt.Printf("⚠️ Config reload failed, keeping current settings: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	if tlsConfig != nil {
+		s.http.TLSConfig = tlsConfig
+		fmt.Printf("🔒 Serving TLS on %s (client certificates required: %t)\n", s.http.Addr, tlsConfig.ClientCAs != nil)
+		go func() {
+			// The certificate is already in TLSConfig.
+			serveErr <- s.http.ListenAndServeTLS("", "")
+		}()
+	} else {
+		go func() {
+			serveErr <- s.http.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-stop:
+	case <-drainRequested:
+		// /readyz already reports not-ready; give the load balancer
+		// NEUROEDGE_DRAIN_GRACE to notice before the listener closes.
+		grace := drainGrace()
+		fmt.Printf("Drain requested, closing listener in %s...\n", grace)
+		select {
+		case <-time.After(grace):
+		case <-stop:
+		}
+	}
+
+	fmt.Println("Shutting down API...")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// Shutdown stops accepting connections, waits until the inflight counter
+// reaches zero (hijacked streams such as /chat/ws included) or ctx ends,
+// then runs the shutdown hooks. Hooks run even when draining timed out.
+func (s *Server) Shutdown(ctx context.Context) error {
+	shuttingDown.Store(true)
+
+	var errs []error
+	if err := s.http.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	if err := waitForDrain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	s.mu.Lock()
+	hooks := append([]func(context.Context) error(nil), s.hooks...)
+	s.mu.Unlock()
+	// Hooks get their own short budget so a drain timeout doesn't skip them.
+	hookCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](hookCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func waitForDrain(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		inflight := getConcurrencySnapshot().Current
+		if inflight == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("drain: %d requests still inflight: %w", inflight, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// drainGrace reads NEUROEDGE_DRAIN_GRACE (Go duration or seconds, default 0).
+func drainGrace() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("NEUROEDGE_DRAIN_GRACE"))
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+// readinessTimeout bounds how long /readyz waits on readiness predicates:
+// NEUROEDGE_READINESS_TIMEOUT_MS, default 500.
+func readinessTimeout() time.Duration {
+	return time.Duration(readIntEnv("NEUROEDGE_READINESS_TIMEOUT_MS", 500)) * time.Millisecond
+}
+
+// ShutdownHandler drains this instance on command: /readyz starts failing at
+// once, and Run then closes the listener, waits for inflight requests and
+// runs the shutdown hooks, exactly as for SIGTERM. It answers 202 before any
+// of that happens, and 409 if a shutdown is already in progress.
+func ShutdownHandler(w http.ResponseWriter, r *http.Request) {
+	if !RequestDrain() {
+		writeErrorf(w, r, ErrCodeConflict, http.StatusConflict, "shutdown already in progress")
+		return
+	}
+	info, _ := r.Context().Value(authContextKey{}).(authInfo)
+	fmt.Printf("🛑 Drain requested by key %q from %s\n", info.KeyID, clientIP(r))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "draining",
+		"inflight": getConcurrencySnapshot().Current,
+	})
+}
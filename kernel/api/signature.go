@@ -0,0 +1,204 @@
+// kernel/api/signature.go
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// signedRequestPayload is what X-Request-Signature is the HMAC-SHA256 of:
+// the method, path with query, timestamp, request ID and the hex SHA-256 of
+// the body, joined by newlines. The request ID is signed so a replay can't
+// dodge the seen-set by changing it.
+func signedRequestPayload(method, uri, timestamp, requestID string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(strings.Join([]string{method, uri, timestamp, requestID, hex.EncodeToString(sum[:])}, "\n"))
+}
+
+// signRequest returns the hex X-Request-Signature for the given fields.
+func signRequest(key []byte, method, uri, timestamp, requestID string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(signedRequestPayload(method, uri, timestamp, requestID, body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seenRequestIDs remembers request IDs until they can no longer pass the
+// timestamp check. It never forgets an ID early, since that would reopen it
+// to replay: when it holds max unexpired IDs, new ones are refused until
+// the oldest expire.
+type seenRequestIDs struct {
+	mu     sync.Mutex
+	max    int
+	expiry map[string]time.Time
+	order  []seenRequestID
+}
+
+type seenRequestID struct {
+	id      string
+	expires time.Time
+}
+
+// seenOutcome is the result of seenRequestIDs.add.
+type seenOutcome int
+
+const (
+	seenNew seenOutcome = iota
+	seenReplay
+	seenFull
+)
+
+func newSeenRequestIDs(max int) *seenRequestIDs {
+	return &seenRequestIDs{max: max, expiry: map[string]time.Time{}}
+}
+
+// add records id until expires. It reports seenReplay if id is already
+// there, and seenFull, with how long until an ID expires, if there is no
+// room.
+func (s *seenRequestIDs) add(id string, expires, now time.Time) (seenOutcome, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.order) > 0 && !s.order[0].expires.After(now) {
+		s.dropOldestLocked()
+	}
+	if until, ok := s.expiry[id]; ok && until.After(now) {
+		return seenReplay, 0
+	}
+	if len(s.order) >= s.max {
+		// Timestamps arrive out of order, so expired IDs can sit behind
+		// the head; sweep them before giving up.
+		s.compactLocked(now)
+	}
+	if len(s.order) >= s.max {
+		next := s.order[0].expires
+		for _, seen := range s.order {
+			if seen.expires.Before(next) {
+				next = seen.expires
+			}
+		}
+		return seenFull, next.Sub(now)
+	}
+	s.expiry[id] = expires
+	s.order = append(s.order, seenRequestID{id: id, expires: expires})
+	return seenNew, 0
+}
+
+func (s *seenRequestIDs) dropOldestLocked() {
+	oldest := s.order[0]
+	// A later add of the same ID owns the map entry now.
+	if s.expiry[oldest.id].Equal(oldest.expires) {
+		delete(s.expiry, oldest.id)
+	}
+	s.order = s.order[1:]
+}
+
+// compactLocked drops every expired ID, wherever it sits in order.
+func (s *seenRequestIDs) compactLocked(now time.Time) {
+	kept := s.order[:0]
+	for _, seen := range s.order {
+		if seen.expires.After(now) {
+			kept = append(kept, seen)
+			continue
+		}
+		if s.expiry[seen.id].Equal(seen.expires) {
+			delete(s.expiry, seen.id)
+		}
+	}
+	s.order = kept
+}
+
+// withRequestSignature rejects requests that aren't signed with
+// NEUROEDGE_REQUEST_SIGNING_KEY when NEUROEDGE_REQUIRE_SIGNATURE is true, so a
+// leaked API key alone can't be used and captured requests can't be
+// replayed. Clients send X-Request-Timestamp (Unix seconds), a unique
+// X-Request-ID and X-Request-Signature, the hex HMAC-SHA256 described at
+// signedRequestPayload. A timestamp more than NEUROEDGE_SIGNATURE_SKEW_SEC
+// (default 300) from the server clock, or an ID already used within that
+// window, is refused with 401. NEUROEDGE_SIGNATURE_SEEN_MAX (default 100000)
+// bounds the remembered IDs; once that many are unexpired, further signed
+// requests get 503 with Retry-After rather than an ID being forgotten early.
+func withRequestSignature(next http.HandlerFunc) http.HandlerFunc {
+	required, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("NEUROEDGE_REQUIRE_SIGNATURE")))
+	if !required {
+		return next
+	}
+	key := []byte(os.Getenv("NEUROEDGE_REQUEST_SIGNING_KEY"))
+	skew := time.Duration(readIntEnv("NEUROEDGE_SIGNATURE_SKEW_SEC", 300)) * time.Second
+	seen := ensureSeenRequestIDs()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(key) == 0 {
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "request signing key not configured")
+			return
+		}
+		stamp := strings.TrimSpace(r.Header.Get("X-Request-Timestamp"))
+		requestID := strings.TrimSpace(r.Header.Get("X-Request-ID"))
+		signature := strings.ToLower(strings.TrimSpace(r.Header.Get("X-Request-Signature")))
+		if stamp == "" || requestID == "" || signature == "" {
+			writeErrorf(w, r, ErrCodeUnauthorized, http.StatusUnauthorized, "signed request required: X-Request-Timestamp, X-Request-ID and X-Request-Signature")
+			return
+		}
+		secs, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			writeErrorf(w, r, ErrCodeUnauthorized, http.StatusUnauthorized, "invalid X-Request-Timestamp: want Unix seconds")
+			return
+		}
+		now := time.Now()
+		signedAt := time.Unix(secs, 0)
+		if signedAt.Before(now.Add(-skew)) || signedAt.After(now.Add(skew)) {
+			writeErrorf(w, r, ErrCodeUnauthorized, http.StatusUnauthorized, "request timestamp outside the allowed %s skew", skew)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			// Bounded by withBodySizeLimit; restored for the handler.
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, r, ErrCodeBodyTooLarge, http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		want := signRequest(key, r.Method, r.URL.RequestURI(), stamp, requestID, body)
+		if !hmac.Equal([]byte(signature), []byte(want)) {
+			writeErrorf(w, r, ErrCodeUnauthorized, http.StatusUnauthorized, "invalid request signature")
+			return
+		}
+		// Only verified requests are recorded, so forged ones can't burn IDs.
+		// An ID stays remembered until its timestamp falls out of the window.
+		switch outcome, wait := seen.add(requestID, signedAt.Add(skew), now); outcome {
+		case seenReplay:
+			writeErrorf(w, r, ErrCodeUnauthorized, http.StatusUnauthorized, "replayed request ID")
+			return
+		case seenFull:
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "too many signed requests in the replay window, retry later")
+			return
+		}
+		next(w, r)
+	}
+}
+
+var (
+	seenIDsOnce sync.Once
+	seenIDs     *seenRequestIDs
+)
+
+// ensureSeenRequestIDs shares one seen-set across routes, so an ID used on
+// one route can't be replayed on another.
+func ensureSeenRequestIDs() *seenRequestIDs {
+	seenIDsOnce.Do(func() {
+		seenIDs = newSeenRequestIDs(readIntEnv("NEUROEDGE_SIGNATURE_SEEN_MAX", 100000))
+	})
+	return seenIDs
+}
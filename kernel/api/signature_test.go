@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeenRequestIDs(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := newSeenRequestIDs(2)
+
+	if got, _ := s.add("a", now.Add(time.Minute), now); got != seenNew {
+		t.Fatalf("first a = %v, want seenNew", got)
+	}
+	if got, _ := s.add("a", now.Add(time.Minute), now); got != seenReplay {
+		t.Fatalf("second a = %v, want seenReplay", got)
+	}
+	if got, _ := s.add("b", now.Add(2*time.Minute), now); got != seenNew {
+		t.Fatalf("b = %v, want seenNew", got)
+	}
+
+	// Full of unexpired IDs: refuse rather than forget a and reopen it.
+	got, wait := s.add("c", now.Add(time.Minute), now)
+	if got != seenFull || wait != time.Minute {
+		t.Fatalf("c = %v, %s; want seenFull, 1m", got, wait)
+	}
+	if got, _ := s.add("a", now.Add(time.Minute), now.Add(time.Second)); got != seenReplay {
+		t.Fatalf("a while full = %v, want seenReplay", got)
+	}
+
+	// Once a expires there is room again, and a may be reused.
+	later := now.Add(time.Minute)
+	if got, _ := s.add("c", later.Add(time.Minute), later); got != seenNew {
+		t.Fatalf("c after a expired = %v, want seenNew", got)
+	}
+	if got, _ := s.add("b", later.Add(time.Minute), later); got != seenReplay {
+		t.Fatalf("b = %v, want still seenReplay", got)
+	}
+}
+
+// Expired IDs behind an unexpired head still make room.
+func TestSeenRequestIDsCompactsOutOfOrder(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := newSeenRequestIDs(2)
+	s.add("long", now.Add(time.Hour), now)
+	s.add("short", now.Add(time.Second), now)
+
+	later := now.Add(time.Minute)
+	if got, _ := s.add("next", later.Add(time.Minute), later); got != seenNew {
+		t.Fatalf("next = %v, want seenNew after short expired", got)
+	}
+	if got, _ := s.add("long", later.Add(time.Minute), later); got != seenReplay {
+		t.Fatalf("long = %v, want seenReplay", got)
+	}
+}
+
+func TestWithRequestSignature(t *testing.T) {
+	key := []byte("signing-key")
+	t.Setenv("NEUROEDGE_REQUIRE_SIGNATURE", "true")
+	t.Setenv("NEUROEDGE_REQUEST_SIGNING_KEY", string(key))
+	var handled string
+	h := withRequestSignature(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, r.Body)
+		handled = buf.String()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	prefix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	send := func(requestID, body string, mutate func(r *http.Request, stamp string) string) *httptest.ResponseRecorder {
+		t.Helper()
+		stamp := strconv.FormatInt(time.Now().Unix(), 10)
+		r := httptest.NewRequest(http.MethodPost, "/v1/execute?async=true", strings.NewReader(body))
+		sig := signRequest(key, r.Method, r.URL.RequestURI(), stamp, prefix+requestID, []byte(body))
+		r.Header.Set("X-Request-ID", prefix+requestID)
+		r.Header.Set("X-Request-Timestamp", stamp)
+		if mutate != nil {
+			sig = mutate(r, stamp)
+		}
+		r.Header.Set("X-Request-Signature", sig)
+		rec := httptest.NewRecorder()
+		h(rec, r)
+		return rec
+	}
+
+	if rec := send("ok", `{"id":"1"}`, nil); rec.Code != http.StatusOK || handled != `{"id":"1"}` {
+		t.Fatalf("valid request: status %d, handler saw %q", rec.Code, handled)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(r *http.Request, stamp string) string
+	}{
+		{name: "missing signature", mutate: func(*http.Request, string) string { return "" }},
+		{name: "wrong key", mutate: func(r *http.Request, stamp string) string {
+			return signRequest([]byte("other"), r.Method, r.URL.RequestURI(), stamp, r.Header.Get("X-Request-ID"), []byte(`{"id":"2"}`))
+		}},
+		{name: "tampered body", mutate: func(r *http.Request, stamp string) string {
+			return signRequest(key, r.Method, r.URL.RequestURI(), stamp, r.Header.Get("X-Request-ID"), []byte(`{"id":"other"}`))
+		}},
+		{name: "different path", mutate: func(r *http.Request, stamp string) string {
+			return signRequest(key, r.Method, "/v1/execute", stamp, r.Header.Get("X-Request-ID"), []byte(`{"id":"2"}`))
+		}},
+		{name: "stale timestamp", mutate: func(r *http.Request, _ string) string {
+			old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+			r.Header.Set("X-Request-Timestamp", old)
+			return signRequest(key, r.Method, r.URL.RequestURI(), old, r.Header.Get("X-Request-ID"), []byte(`{"id":"2"}`))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := send(tt.name, `{"id":"2"}`, tt.mutate); rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+
+	// A rejected forgery doesn't burn its ID; a replay of a valid one is refused.
+	if rec := send("tampered body", `{"id":"2"}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("ID of a rejected forgery: status %d, want 200", rec.Code)
+	}
+	if rec := send("ok", `{"id":"1"}`, nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replay: status %d, want 401", rec.Code)
+	}
+}
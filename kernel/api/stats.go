@@ -0,0 +1,144 @@
+// kernel/api/stats.go
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rolling per-route, per-status request counts in 1-minute buckets, for
+// "how many requests hit /execute in the last minute" without Prometheus.
+// Buckets older than the window are reused in place, so memory stays at
+// one bucket per minute of window.
+
+const (
+	defaultStatsWindow = time.Hour
+	maxStatsWindow     = 24 * time.Hour
+)
+
+type statsKey struct {
+	route  string
+	status int
+}
+
+type statsBucket struct {
+	minute int64 // Unix minute the counts belong to
+	counts map[statsKey]uint64
+}
+
+type routeStats struct {
+	mu      sync.Mutex
+	buckets []statsBucket
+}
+
+var (
+	statsOnce sync.Once
+	stats     *routeStats
+)
+
+// requestStats builds the shared counter from NEUROEDGE_STATS_WINDOW (Go
+// duration or seconds, default 1h, at most 24h) on first use.
+func requestStats() *routeStats {
+	statsOnce.Do(func() {
+		window := defaultStatsWindow
+		if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_STATS_WINDOW")); raw != "" {
+			if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+				window = d
+			} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+				window = time.Duration(secs) * time.Second
+			}
+		}
+		if window > maxStatsWindow {
+			window = maxStatsWindow
+		}
+		minutes := int((window + time.Minute - 1) / time.Minute)
+		stats = &routeStats{buckets: make([]statsBucket, minutes)}
+	})
+	return stats
+}
+
+// record counts one request in the bucket for now's minute.
+func (s *routeStats) record(route string, status int, now time.Time) {
+	minute := now.Unix() / 60
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := &s.buckets[minute%int64(len(s.buckets))]
+	if b.minute != minute || b.counts == nil {
+		b.minute = minute
+		b.counts = make(map[statsKey]uint64)
+	}
+	b.counts[statsKey{route: route, status: status}]++
+}
+
+// RouteStat is one route's request counts over a stats window.
+type RouteStat struct {
+	Route    string            `json:"route"`
+	Total    uint64            `json:"total"`
+	Statuses map[string]uint64 `json:"statuses"`
+}
+
+// StatsSnapshot is the /kernel/stats response.
+type StatsSnapshot struct {
+	WindowMinutes int         `json:"window_minutes"`
+	Since         time.Time   `json:"since"`
+	Total         uint64      `json:"total"`
+	Routes        []RouteStat `json:"routes"`
+}
+
+// snapshot sums the last minutes buckets, the current partial minute
+// included; minutes <= 0 or beyond the window means the whole window.
+func (s *routeStats) snapshot(minutes int, now time.Time) StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if minutes <= 0 || minutes > len(s.buckets) {
+		minutes = len(s.buckets)
+	}
+	current := now.Unix() / 60
+	oldest := current - int64(minutes) + 1
+
+	byRoute := map[string]*RouteStat{}
+	var total uint64
+	for _, b := range s.buckets {
+		if b.minute < oldest || b.minute > current {
+			continue
+		}
+		for k, n := range b.counts {
+			rs, ok := byRoute[k.route]
+			if !ok {
+				rs = &RouteStat{Route: k.route, Statuses: map[string]uint64{}}
+				byRoute[k.route] = rs
+			}
+			rs.Total += n
+			rs.Statuses[strconv.Itoa(k.status)] += n
+			total += n
+		}
+	}
+
+	out := StatsSnapshot{
+		WindowMinutes: minutes,
+		Since:         time.Unix(oldest*60, 0).UTC(),
+		Total:         total,
+		Routes:        make([]RouteStat, 0, len(byRoute)),
+	}
+	for _, rs := range byRoute {
+		out.Routes = append(out.Routes, *rs)
+	}
+	sort.Slice(out.Routes, func(i, j int) bool { return out.Routes[i].Route < out.Routes[j].Route })
+	return out
+}
+
+// StatsHandler reports request counts per route and status over the last
+// ?minutes= (default the whole window).
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	minutes, err := queryInt(r.URL.Query().Get("minutes"), 0)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "invalid minutes: must be a non-negative integer")
+		return
+	}
+	writeJSON(w, requestStats().snapshot(minutes, time.Now()))
+}
@@ -0,0 +1,104 @@
+// kernel/api/stream.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"neuroedge/kernel/core"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// streamingClient is implemented by orchestrator clients that can relay
+// partial output, such as core.PythonClient.
+type streamingClient interface {
+	SubmitTaskStream(ctx context.Context, req *pb.TaskRequest) (<-chan core.TaskChunk, error)
+}
+
+// ChatStreamHandler relays orchestrator output to the client as Server-Sent
+// Events. A client disconnect cancels the request context and with it the
+// upstream call.
+func ChatStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var cmd kernelCommand
+	if !decodeJSONBody(w, r, &cmd) {
+		return
+	}
+	if strings.TrimSpace(cmd.ID) == "" {
+		cmd.ID = fmt.Sprintf("kernel-%d", time.Now().UnixNano())
+	}
+	if cmd.Payload == nil {
+		cmd.Payload = map[string]interface{}{}
+	}
+	if err := validateCommand(cmd); err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if err := checkEngineAllowed(cmd); err != nil {
+		writeErrorf(w, r, ErrCodeEngineNotAllowed, http.StatusForbidden, "%v", err)
+		return
+	}
+	if _, err := extractAction(cmd.Payload); err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	client := getOrchestratorClient()
+	if client == nil {
+		writeErrorf(w, r, ErrCodeUnavailable, http.StatusServiceUnavailable, "orchestrator not configured")
+		return
+	}
+	streamer, ok := client.(streamingClient)
+	if !ok {
+		writeErrorf(w, r, ErrCodeNotImplemented, http.StatusNotImplemented, "orchestrator does not support streaming")
+		return
+	}
+	taskReq, err := buildTaskRequest(cmd)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeInvalidArgument, http.StatusBadRequest, "payload not serializable")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's WriteTimeout; lift it for this response.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	chunks, err := streamer.SubmitTaskStream(r.Context(), taskReq)
+	if err != nil {
+		writeErrorf(w, r, ErrCodeUpstream, http.StatusBadGateway, "orchestrator error: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			writeSSE(w, "error", chunk.Err.Error())
+			_ = rc.Flush()
+			return
+		}
+		writeSSE(w, "chunk", chunk.Data)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+	if r.Context().Err() != nil {
+		return
+	}
+	writeSSE(w, "done", cmd.ID)
+	_ = rc.Flush()
+}
+
+// writeSSE writes one event, splitting multi-line data per the SSE framing rules.
+func writeSSE(w http.ResponseWriter, event, data string) {
+	fmt.Fprintf(w, "event: %s\n", event)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"neuroedge/kernel/core"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// fakeStreamer sends one chunk, then holds the stream open until the
+// request context is cancelled.
+type fakeStreamer struct {
+	cancelled chan struct{}
+}
+
+func (f *fakeStreamer) SubmitTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
+	return &pb.TaskResponse{TaskId: req.TaskId, Status: "done"}, nil
+}
+
+func (f *fakeStreamer) SubmitTaskStream(ctx context.Context, req *pb.TaskRequest) (<-chan core.TaskChunk, error) {
+	chunks := make(chan core.TaskChunk)
+	go func() {
+		defer close(chunks)
+		select {
+		case chunks <- core.TaskChunk{TaskId: req.TaskId, Data: "first"}:
+		case <-ctx.Done():
+		}
+		<-ctx.Done()
+		close(f.cancelled)
+	}()
+	return chunks, nil
+}
+
+// useOrchestratorClient swaps in c for one test.
+func useOrchestratorClient(t *testing.T, c pb.OrchestratorClient) {
+	t.Helper()
+	prev := getOrchestratorClient()
+	SetOrchestratorClient(c)
+	t.Cleanup(func() { SetOrchestratorClient(prev) })
+}
+
+func TestChatStreamFlushesAndCancelsUpstream(t *testing.T) {
+	fake := &fakeStreamer{cancelled: make(chan struct{})}
+	useOrchestratorClient(t, fake)
+	srv := httptest.NewServer(http.HandlerFunc(ChatStreamHandler))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader(`{"id":"s1","type":"chat","payload":{"message":"hi"}}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// The first chunk arrives while the upstream stream is still open, so it
+	// was flushed rather than buffered until the end.
+	got := make(chan string, 1)
+	go func() {
+		var event strings.Builder
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() && scanner.Text() != "" {
+			event.WriteString(scanner.Text() + "\n")
+		}
+		got <- event.String()
+	}()
+	select {
+	case event := <-got:
+		if event != "event: chunk\ndata: first\n" {
+			t.Fatalf("first event = %q", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first chunk was not flushed")
+	}
+
+	// Disconnecting cancels the upstream call.
+	cancel()
+	select {
+	case <-fake.cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("client disconnect did not cancel the upstream stream")
+	}
+}
+
+// blockingClient answers only when its context ends.
+type blockingClient struct{}
+
+func (blockingClient) SubmitTask(ctx context.Context, _ *pb.TaskRequest) (*pb.TaskResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestExecuteCancelledRequestIsCancelled(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_FALLBACK", "off")
+	useOrchestratorClient(t, blockingClient{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/v1/execute", strings.NewReader(`{"id":"cancel-1","type":"chat","payload":{"message":"hi"}}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan struct{})
+	go func() {
+		ExecuteHandler(rec, req)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ExecuteHandler did not return after the client went away")
+	}
+	if rec.Code != statusClientClosedRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, statusClientClosedRequest)
+	}
+	var resp kernelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := resp.Data.(map[string]interface{}); data["status"] != "cancelled" {
+		t.Fatalf("data = %+v, want status cancelled", resp.Data)
+	}
+}
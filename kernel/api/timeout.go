@@ -0,0 +1,131 @@
+// kernel/api/timeout.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTimeout reads NEUROEDGE_REQUEST_TIMEOUT as a Go duration ("45s") or
+// whole seconds ("45"), defaulting to 30s.
+func requestTimeout() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("NEUROEDGE_REQUEST_TIMEOUT"))
+	if raw == "" {
+		return 30 * time.Second
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return d
+	}
+	if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return 30 * time.Second
+}
+
+// timeoutWriter passes writes through until the deadline fires and discards
+// every handler write after it. If the handler has not started its response
+// by then, the middleware answers 503; otherwise the response is cut short.
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// withTimeout bounds handler time with NEUROEDGE_REQUEST_TIMEOUT. The request
+// context carries the deadline, so orchestrator calls made with it are
+// cancelled too, and the concurrency slot is released when the deadline hits.
+func withTimeout(next http.HandlerFunc) http.HandlerFunc {
+	timeout := requestTimeout()
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			next(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			// Re-raise on the serving goroutine so withPanicRecovery handles it.
+			panic(p)
+		case <-done:
+		case <-ctx.Done():
+			// From here on the handler goroutine's writes are discarded, so it
+			// never touches w once this middleware has returned.
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if tw.wroteHeader {
+				// Too late for an error status; the response is cut short.
+				return
+			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				// The client hung up; nobody reads a body, but logs should
+				// not count it as a timeout.
+				w.WriteHeader(statusClientClosedRequest)
+				return
+			}
+			writeErrorf(w, r, ErrCodeTimeout, http.StatusServiceUnavailable, "request timed out after %s", timeout)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runPastTimeout serves one request through h and then lets the handler,
+// which is parked on release after its deadline, carry on writing. It
+// returns the recorder and the error of the handler's late write.
+func runPastTimeout(t *testing.T, h http.HandlerFunc, release chan struct{}, lateErr chan error) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/execute", nil))
+	close(release)
+	select {
+	case err := <-lateErr:
+		return rec, err
+	case <-time.After(time.Second):
+		t.Fatal("handler did not finish")
+		return nil, nil
+	}
+}
+
+func TestWithTimeoutAnswers503BeforeHeaders(t *testing.T) {
+	t.Setenv("NEUROEDGE_REQUEST_TIMEOUT", "20ms")
+	release, lateErr := make(chan struct{}), make(chan error, 1)
+	h := withTimeout(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		<-release
+		_, err := w.Write([]byte("late"))
+		lateErr <- err
+	})
+
+	rec, err := runPastTimeout(t, h, release, lateErr)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if err != http.ErrHandlerTimeout {
+		t.Fatalf("late write err = %v, want ErrHandlerTimeout", err)
+	}
+	if strings.Contains(rec.Body.String(), "late") {
+		t.Fatalf("late write reached the client: %q", rec.Body.String())
+	}
+}
+
+// A handler that already committed its response keeps writing after the
+// middleware has returned and withGzip has closed w; run with -race.
+func TestWithTimeoutDropsWritesAfterHeaders(t *testing.T) {
+	t.Setenv("NEUROEDGE_REQUEST_TIMEOUT", "20ms")
+	release, lateErr := make(chan struct{}), make(chan error, 1)
+	h := withGzip(withTimeout(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("early"))
+		<-r.Context().Done()
+		<-release
+		_, err := w.Write([]byte("late"))
+		lateErr <- err
+	}))
+
+	rec, err := runPastTimeout(t, h, release, lateErr)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want the 200 committed before the deadline", rec.Code)
+	}
+	if err != http.ErrHandlerTimeout {
+		t.Fatalf("late write err = %v, want ErrHandlerTimeout", err)
+	}
+	if got := rec.Body.String(); got != "early" {
+		t.Fatalf("body = %q, want only the bytes written before the deadline", got)
+	}
+}
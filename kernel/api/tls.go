@@ -0,0 +1,63 @@
+// kernel/api/tls.go
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsConfigFromEnv builds the server's TLS settings from NEUROEDGE_TLS_CERT
+// and NEUROEDGE_TLS_KEY (PEM files). It returns nil when neither is set, so
+// the caller serves plain HTTP as before; setting only one is an error.
+//
+// Connections need TLS 1.2 or newer, and TLS 1.2 is limited to ECDHE
+// AEAD suites. When NEUROEDGE_TLS_CLIENT_CA names a PEM bundle, clients
+// must also present a certificate signed by it (mTLS). That includes
+// load-balancer health probes.
+func tlsConfigFromEnv() (*tls.Config, error) {
+	certFile := strings.TrimSpace(os.Getenv("NEUROEDGE_TLS_CERT"))
+	keyFile := strings.TrimSpace(os.Getenv("NEUROEDGE_TLS_KEY"))
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("NEUROEDGE_TLS_CERT and NEUROEDGE_TLS_KEY must be set together")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: load key pair: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		Certificates:     []tls.Certificate{cert},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		// TLS 1.3 suites are not configurable; these only apply to 1.2.
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+	}
+
+	if caFile := strings.TrimSpace(os.Getenv("NEUROEDGE_TLS_CLIENT_CA")); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates found in %s", caFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
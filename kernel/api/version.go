@@ -0,0 +1,37 @@
+// kernel/api/version.go
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X neuroedge/kernel/api.Version=1.2.0 -X neuroedge/kernel/api.Commit=$(git rev-parse --short HEAD) -X neuroedge/kernel/api.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+func orDev(v string) string {
+	if v == "" {
+		return "dev"
+	}
+	return v
+}
+
+func buildInfo() map[string]any {
+	return map[string]any{
+		"version":   orDev(Version),
+		"commit":    orDev(Commit),
+		"buildTime": orDev(BuildTime),
+		"goVersion": runtime.Version(),
+	}
+}
+
+// VersionHandler reports which kernel build is running
+func VersionHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, buildInfo())
+}
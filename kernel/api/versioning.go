@@ -0,0 +1,60 @@
+// kernel/api/versioning.go
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deprecatedAliasLogEvery limits the deprecation warning to one line per
+// route per interval, so a busy legacy client doesn't flood the log.
+const deprecatedAliasLogEvery = time.Minute
+
+var (
+	deprecatedMu     sync.Mutex
+	deprecatedLogged = map[string]time.Time{}
+)
+
+// deprecatedAlias wraps handlers served at their pre-versioning path. The
+// response is unchanged apart from Deprecation and Link headers pointing at
+// the path under successor (e.g. "/v1"), and a warning is logged naming the
+// route and caller so remaining integrations can be found and moved.
+func deprecatedAlias(successor string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+successor+r.URL.Path+">; rel=\"successor-version\"")
+
+			route := routePattern(r)
+			now := time.Now()
+			deprecatedMu.Lock()
+			due := now.Sub(deprecatedLogged[route]) >= deprecatedAliasLogEvery
+			if due {
+				deprecatedLogged[route] = now
+			}
+			deprecatedMu.Unlock()
+			if due {
+				log.Printf("⚠️ deprecated route %s %s called by %s; use %s%s", r.Method, route, clientIP(r), successor, route)
+			}
+			next(w, r)
+		}
+	}
+}
+
+// apiVersionPrefix returns the version prefix r was routed under, such as
+// "/v1", or "" for an unversioned alias, so links a handler returns keep the
+// caller on the version it used.
+func apiVersionPrefix(r *http.Request) string {
+	rest, ok := strings.CutPrefix(r.URL.Path, "/v")
+	if !ok {
+		return ""
+	}
+	n := strings.IndexByte(rest, '/')
+	if n <= 0 || strings.Trim(rest[:n], "0123456789") != "" {
+		return ""
+	}
+	return "/v" + rest[:n]
+}
@@ -0,0 +1,128 @@
+// kernel/api/websocket.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = (wsPongWait * 9) / 10
+	wsWriteWait  = 10 * time.Second
+	wsMaxFrame   = 1 << 20
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Same origin policy as withCORS; non-browser clients send no Origin.
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || originAllowed(currentCORSOrigins(), origin)
+	},
+}
+
+// ChatWebSocketHandler upgrades to a persistent chat session. Each inbound
+// kernelCommand frame is checked, dispatched and audited like a batch entry
+// (see runBatchCommand) and answered with a kernelResponse frame. The
+// session holds one concurrency slot for its whole lifetime and runs one
+// frame at a time; an in-flight orchestrator call is cancelled when the
+// socket drops.
+func ChatWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFor(w, r)
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the HTTP error.
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var writeMu sync.Mutex
+	write := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(v)
+	}
+
+	conn.SetReadLimit(wsMaxFrame)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	go func() {
+		ticker := time.NewTicker(wsPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				writeMu.Lock()
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+				writeMu.Unlock()
+				if err != nil {
+					cancel()
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Deferred in this order so a pending dispatch is cancelled before we wait on it.
+	var inflight sync.WaitGroup
+	defer inflight.Wait()
+	defer cancel()
+
+	// The socket counts as a single inflight slot, so frames run one at a
+	// time: the next frame isn't read until the previous one is answered.
+	for {
+		var cmd kernelCommand
+		if err := conn.ReadJSON(&cmd); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("ws session closed path=%s err=%v", r.URL.Path, err)
+			}
+			return
+		}
+		if strings.TrimSpace(cmd.ID) == "" {
+			cmd.ID = fmt.Sprintf("kernel-%d", time.Now().UnixNano())
+		}
+		if cmd.Payload == nil {
+			cmd.Payload = map[string]interface{}{}
+		}
+
+		// Dispatch off the read loop so the pinger can still cancel a slow
+		// inference when the peer goes away.
+		done := make(chan struct{})
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			defer close(done)
+			resp := runBatchCommand(ctx, requestID, cmd)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := write(resp); err != nil {
+				cancel()
+			}
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return
+		}
+		// Pongs queue unread while we wait, so restart the pong window.
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	}
+}
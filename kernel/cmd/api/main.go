@@ -7,19 +7,26 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	handlers "neuroedge/kernel/api"
+	"neuroedge/kernel/core"
+	"neuroedge/kernel/discovery"
+	"neuroedge/kernel/engines"
+	"neuroedge/kernel/mesh"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+	"neuroedge/kernel/tracing"
 )
 
 func main() {
-	apiKey := strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEY"))
-	if apiKey == "" {
-		log.Fatal("NEUROEDGE_API_KEY is required")
+	// Applied before anything reads the environment; SIGHUP reloads it in srv.Run.
+	core.LoadConfig()
+
+	if strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEY")) == "" && strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEYS")) == "" &&
+		strings.TrimSpace(os.Getenv("NEUROEDGE_API_KEYS_FILE")) == "" {
+		log.Fatal("NEUROEDGE_API_KEY, NEUROEDGE_API_KEYS or NEUROEDGE_API_KEYS_FILE is required")
 	}
 
 	port := strings.TrimSpace(os.Getenv("PORT"))
@@ -37,35 +44,133 @@ func main() {
 
 	fmt.Printf("Starting NeuroEdge API on %s\n", addr)
 
-	router := handlers.NewRouter()
+	shutdownTracing, err := tracing.Init()
+	if err != nil {
+		log.Fatalf("tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
 
-	server := &http.Server{
+	srv := handlers.NewServer(&http.Server{
 		Addr:              addr,
-		Handler:           router,
+		Handler:           handlers.NewRouter(),
 		ReadTimeout:       readTimeout,
 		ReadHeaderTimeout: readHeaderTimeout,
 		WriteTimeout:      writeTimeout,
 		IdleTimeout:       idleTimeout,
 		MaxHeaderBytes:    maxHeaderBytes,
-	}
+	})
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	// Registered first so it runs last, after everything that may still audit.
+	srv.OnShutdown(func(context.Context) error {
+		return core.Audit().Close()
+	})
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("API server error: %v", err)
+	orchestratorAddr := strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_ADDR"))
+	if orchestratorAddr == "" {
+		orchestratorAddr = "http://localhost:8090"
+	}
+	// A comma-separated list runs the replicas as a failover pool.
+	var orchestratorClient pb.OrchestratorClient
+	if strings.Contains(orchestratorAddr, ",") {
+		pool, err := core.NewPythonClientPool(strings.Split(orchestratorAddr, ","))
+		if err != nil {
+			log.Fatalf("orchestrator pool: %v", err)
 		}
-	}()
+		srv.OnShutdown(func(context.Context) error {
+			pool.Close()
+			return nil
+		})
+		log.Printf("orchestrator client mode=%s addrs=%s", pool.Mode(), orchestratorAddr)
+		orchestratorClient = pool
+	} else {
+		orchestrator, err := core.NewPythonClient(orchestratorAddr)
+		if err != nil {
+			log.Fatalf("orchestrator client: %v", err)
+		}
+		srv.OnShutdown(func(context.Context) error {
+			orchestrator.Close()
+			return nil
+		})
+		log.Printf("orchestrator client mode=%s addr=%s", orchestrator.Mode(), orchestratorAddr)
+		orchestratorClient = orchestrator
+	}
+	handlers.SetOrchestratorClient(orchestratorClient)
+
+	// Optionally let the orchestrator's safety model second-guess the static
+	// cognition patterns; its failures fall back to the patterns alone.
+	if engine := strings.TrimSpace(os.Getenv("NEUROEDGE_SAFETY_ENGINE")); engine != "" {
+		timeout := time.Duration(readIntEnv("NEUROEDGE_SAFETY_TIMEOUT_MS", 2000)) * time.Millisecond
+		core.SetGuardClassifier(core.OrchestratorClassifier{Client: orchestratorClient, Engine: engine}, timeout)
+		log.Printf("cognition classifier engine=%s timeout=%s", engine, timeout)
+	}
 
-	<-stop
-	fmt.Println("Shutting down API...")
+	// Engines share the bus EventIngestHandler publishes to, so bridge events
+	// reach in-process subscribers.
+	engineRegistry := core.NewEngineRegistry(core.GlobalEventBus)
+	engineRegistry.RegisterAllEngines()
+	// Closes the event-driven loop: inference:request events become
+	// orchestrator tasks answered on inference:response.
+	engineRegistry.RegisterEngine(core.NewInferenceEngine(core.GlobalEventBus, orchestratorClient))
+	optimizer, _ := engineRegistry.Engines["NeuroComputeOptimizer"].(*engines.NeuroComputeOptimizer)
+	srv.OnShutdown(func(context.Context) error {
+		engineRegistry.StopAll()
+		// After StopAll, so the optimizer's last recommendations reach
+		// NEUROEDGE_OPTIMIZER_AUDIT_LOG.
+		if optimizer != nil {
+			return optimizer.Close()
+		}
+		return nil
+	})
+	discovery.RegisterEngineSnapshot(engineRegistry)
+	stopReaper := discovery.StartHeartbeatReaper()
+	defer stopReaper()
+	if optimizer != nil {
+		handlers.RegisterComputeOptimizer(optimizer)
+	}
+	if scaler, ok := engineRegistry.Engines["ScalerEngine"].(*engines.ScalerEngine); ok {
+		handlers.RegisterScalerEngine(scaler)
+	}
+
+	// The mesh runs only when given an AES key (16, 24 or 32 bytes).
+	if key := os.Getenv("NEUROEDGE_MESH_KEY"); key != "" {
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			log.Fatalf("NEUROEDGE_MESH_KEY must be 16, 24 or 32 bytes, got %d", len(key))
+		}
+		handlers.RegisterMeshManager(mesh.NewMeshManager([]byte(key)))
+		// Degraded while the mesh histories are full and losing records.
+		core.GlobalHealthManager.Register("mesh_history", handlers.MeshHistoryHealthCheck())
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
+	// Engines that report liveness, like the optimizer's staleness window
+	// (NEUROEDGE_OPTIMIZER_STALE_AFTER), show up in /kernel/health too.
+	engineRegistry.RegisterHealthChecks(core.GlobalHealthManager)
+	// /readyz stays not-ready until every engine is running and, for those
+	// that implement Ready, subscribed to its topics.
+	engineRegistry.RegisterReadiness(core.GlobalReadiness)
+
+	// The orchestrator's reachability shows up in /kernel/health; a slow
+	// answer is reported as degraded.
+	core.GlobalHealthManager.Register("orchestrator", func() error {
+		started := time.Now()
+		if err := handlers.PingOrchestrator(context.Background()); err != nil {
+			return err
+		}
+		if elapsed := time.Since(started); elapsed > time.Second {
+			return core.Degraded(fmt.Errorf("ping took %s", elapsed.Round(time.Millisecond)))
+		}
+		return nil
+	})
+	core.GlobalHealthManager.StartMonitoring()
+	defer core.GlobalHealthManager.StopMonitoring()
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("shutdown error: %v", err)
+	if err := srv.Run(shutdownTimeout); err != nil {
+		log.Printf("API server error: %v", err)
 	}
 
 	fmt.Println("API stopped")
@@ -7,26 +7,33 @@ import (
 	"os/signal"
 	"syscall"
 
+	handlers "neuroedge/kernel/api"
 	"neuroedge/kernel/core"
 	"neuroedge/kernel/discovery"
-	"neuroedge/kernel/types"
+	"neuroedge/kernel/engines"
 )
 
 func main() {
 	fmt.Println("Starting NeuroEdge Kernel")
 
-	eventBus := types.NewEventBus()
+	eventBus := core.GlobalEventBus
 	core.InitializeAllAgents()
 
 	engineRegistry := core.NewEngineRegistry(eventBus)
 	engineRegistry.RegisterAllEngines()
 	discovery.RegisterEngineSnapshot(engineRegistry)
+	if optimizer, ok := engineRegistry.Engines["NeuroComputeOptimizer"].(*engines.NeuroComputeOptimizer); ok {
+		handlers.RegisterComputeOptimizer(optimizer)
+	}
+	if scaler, ok := engineRegistry.Engines["ScalerEngine"].(*engines.ScalerEngine); ok {
+		handlers.RegisterScalerEngine(scaler)
+	}
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	<-sigs
 
 	fmt.Println("Stopping NeuroEdge Kernel")
-	engineRegistry.StopAllEngines()
+	engineRegistry.StopAll()
 	core.StopAllAgents()
 }
@@ -0,0 +1,16 @@
+package contracts
+
+// ReadinessCheck is implemented by components that need time after Start
+// before they can serve, e.g. an engine that must be subscribed to its
+// topics. Ready returns nil once the component is ready and an error saying
+// what is missing otherwise. It must be cheap and must not block: /readyz
+// calls it on every probe.
+//
+// An engine becomes ready by finishing the work in Start (subscribing,
+// loading state) and recording that, typically by keeping the subscriptions
+// it got back, which Ready then checks. Engines without Ready count as ready
+// while running.
+type ReadinessCheck interface {
+	Name() string
+	Ready() error
+}
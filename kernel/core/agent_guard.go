@@ -2,31 +2,126 @@ package core
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"neuroedge/kernel/core/cognition"
 	"neuroedge/kernel/core/ethics"
 )
 
+var ethicsBlocks, cognitionBlocks uint64
+
+const defaultCognitionHistory = 256
+
+var (
+	guardCognitionOnce sync.Once
+	guardCognition     *cognition.Cognition
+)
+
+// sharedCognition is the guard's Cognition, kept across calls so its
+// decision history accumulates. NEUROEDGE_COGNITION_HISTORY sets how many
+// decisions it keeps (default 256); 0 turns recording off.
+func sharedCognition() *cognition.Cognition {
+	guardCognitionOnce.Do(func() {
+		size := defaultCognitionHistory
+		if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_COGNITION_HISTORY"))); err == nil && n >= 0 {
+			size = n
+		}
+		guardCognition = cognition.NewCognitionWithHistory(size)
+	})
+	return guardCognition
+}
+
+// SetGuardClassifier attaches an external classifier to the guard's
+// cognition stage; see cognition.SetClassifier.
+func SetGuardClassifier(cl cognition.Classifier, timeout time.Duration) {
+	sharedCognition().SetClassifier(cl, timeout)
+}
+
+// CognitionHistory returns the guard's most recent cognition decisions,
+// newest first.
+func CognitionHistory(limit int) []cognition.Decision {
+	return sharedCognition().History(limit)
+}
+
+// GuardBlockCounts reports how many tasks each guard stage has blocked
+func GuardBlockCounts() map[string]uint64 {
+	return map[string]uint64{
+		"ethics":    atomic.LoadUint64(&ethicsBlocks),
+		"cognition": atomic.LoadUint64(&cognitionBlocks),
+	}
+}
+
 // PreExecutionCheck ensures task is safe
 func PreExecutionCheck(agentName string, task string) bool {
+	ok, _ := preExecutionCheck(agentName, task)
+	return ok
+}
+
+// CheckTask is PreExecutionCheck that also says which stage blocked the
+// task ("ethics" or "cognition: <decision>").
+func CheckTask(agentName string, task string) (bool, string) {
+	return preExecutionCheck(agentName, task)
+}
+
+// preExecutionCheck is PreExecutionCheck that also says which stage blocked.
+func preExecutionCheck(agentName string, task string) (bool, string) {
 	fmt.Printf("[AgentGuard] Checking task for agent %s: %s\n", agentName, task)
 	eth := ethics.NewEthics()
 	if !eth.Evaluate(task) {
+		atomic.AddUint64(&ethicsBlocks, 1)
 		fmt.Printf("[AgentGuard] Ethics blocked task for %s\n", agentName)
-		return false
+		return false, "ethics"
 	}
-	cog := cognition.NewCognition()
-	decision := cog.Decide(task, map[string]interface{}{})
+	decision := sharedCognition().Decide(task, map[string]interface{}{})
 	if decision != "approved" {
+		atomic.AddUint64(&cognitionBlocks, 1)
 		fmt.Printf("[AgentGuard] Cognition decision=%s for %s\n", decision, agentName)
-		return false
+		return false, "cognition: " + decision
 	}
-	return true
+	return true, ""
+}
+
+// PolicyCheck is the dry-run result of both guard stages for one task.
+type PolicyCheck struct {
+	Allowed bool `json:"allowed"`
+	Ethics  struct {
+		Allowed bool   `json:"allowed"`
+		Reason  string `json:"reason,omitempty"`
+	} `json:"ethics"`
+	Cognition struct {
+		Decision string `json:"decision"`
+		Reason   string `json:"reason,omitempty"`
+	} `json:"cognition"`
+}
+
+// CheckPolicy runs task through ethics and cognition like PreExecutionCheck
+// but executes nothing and leaves the block counters alone. Both stages are
+// always evaluated so callers see every reason, not just the first.
+func CheckPolicy(task string, context map[string]interface{}) PolicyCheck {
+	var pc PolicyCheck
+	pc.Ethics.Allowed, pc.Ethics.Reason = ethics.NewEthics().Explain(task)
+	pc.Cognition.Decision, pc.Cognition.Reason = sharedCognition().Explain(task, context)
+	pc.Allowed = pc.Ethics.Allowed && pc.Cognition.Decision == "approved"
+	return pc
 }
 
-// ExecuteWithGuard wraps agent execution
+// ExecuteWithGuard wraps agent execution and records the outcome in the
+// audit log
 func ExecuteWithGuard(agentName string, task string, fn func(string)) {
-	if PreExecutionCheck(agentName, task) {
+	ok, reason := preExecutionCheck(agentName, task)
+	Audit().Record(AuditEntry{
+		Type:          "agent",
+		Agent:         agentName,
+		Action:        task,
+		Success:       ok,
+		BlockedReason: reason,
+	})
+	if ok {
 		fn(task)
 	} else {
 		fmt.Printf("[AgentGuard] Task blocked for agent %s: %s\n", agentName, task)
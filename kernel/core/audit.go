@@ -0,0 +1,186 @@
+// kernel/core/audit.go
+package core
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultAuditSize = 1000
+
+// AuditEntry records one command the kernel ran or refused.
+type AuditEntry struct {
+	RequestID     string                 `json:"request_id,omitempty"`
+	Type          string                 `json:"type"`
+	Agent         string                 `json:"agent,omitempty"`
+	Action        string                 `json:"action"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Success       bool                   `json:"success"`
+	BlockedReason string                 `json:"blocked_reason,omitempty"`
+	Payload       map[string]interface{} `json:"payload,omitempty"`
+}
+
+// AuditLog keeps the most recent entries in a ring and, when a file is
+// configured, appends every entry to it as a JSON line so the trail survives
+// restarts.
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+	next    int
+	full    bool
+	file    *os.File
+	redact  *Redactor
+}
+
+var (
+	auditOnce sync.Once
+	audit     *AuditLog
+)
+
+// Audit returns the kernel audit log, configured on first use from
+// NEUROEDGE_AUDIT_SIZE (ring length, default 1000), NEUROEDGE_AUDIT_FILE
+// (optional JSON-lines file) and NEUROEDGE_AUDIT_REDACT_KEYS (comma-separated
+// payload keys to mask, defaulting to the NEUROEDGE_REDACT_KEYS list that
+// logs mask). Masked values are RedactedMask unless
+// NEUROEDGE_AUDIT_HMAC_KEY is set, in which case they are a keyed hash so
+// entries can be correlated without the key holder being able to reverse
+// them.
+func Audit() *AuditLog {
+	auditOnce.Do(func() {
+		size := defaultAuditSize
+		if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_AUDIT_SIZE"))); err == nil && n > 0 {
+			size = n
+		}
+		keys := redactKeysFromEnv("NEUROEDGE_AUDIT_REDACT_KEYS", "NEUROEDGE_REDACT_KEYS")
+		audit = NewAuditLog(size, strings.TrimSpace(os.Getenv("NEUROEDGE_AUDIT_FILE")), keys)
+		if key := os.Getenv("NEUROEDGE_AUDIT_HMAC_KEY"); key != "" {
+			audit.redact = NewRedactor(keys, hmacAuditValue([]byte(key)))
+		}
+	})
+	return audit
+}
+
+// NewAuditLog creates a log holding size entries. With a non-empty path it
+// loads the newest entries already in the file and appends new ones to it;
+// if the file can't be opened the log stays in memory only.
+func NewAuditLog(size int, path string, redactKeys []string) *AuditLog {
+	if size <= 0 {
+		size = defaultAuditSize
+	}
+	a := &AuditLog{entries: make([]AuditEntry, size), redact: NewRedactor(redactKeys, nil)}
+	if path == "" {
+		return a
+	}
+	a.load(path)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Printf("⚠️ Audit file %s unavailable, keeping audit in memory: %v\n", path, err)
+		return a
+	}
+	a.file = f
+	return a
+}
+
+// load replays path into the ring; lines that don't parse are skipped.
+func (a *AuditLog) load(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e AuditEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			a.push(e)
+		}
+	}
+}
+
+// Redact returns a copy of payload with every configured key, at any depth,
+// masked.
+func (a *AuditLog) Redact(payload map[string]interface{}) map[string]interface{} {
+	return a.redact.Redact(payload)
+}
+
+// hmacAuditValue replaces a value with a short HMAC-SHA256 under key. A plain
+// hash would let anyone with the audit trail test guesses for passwords and
+// tokens; without the key they can't.
+func hmacAuditValue(key []byte) func(v interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		raw, _ := json.Marshal(v)
+		mac := hmac.New(sha256.New, key)
+		mac.Write(raw)
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+}
+
+// Record stores e, stamping the time if unset. Callers pass payloads through
+// Redact first.
+func (a *AuditLog) Record(e AuditEntry) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.push(e)
+	if a.file == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		fmt.Printf("⚠️ Failed to write audit entry: %v\n", err)
+	}
+}
+
+// push adds e to the ring. Caller must hold a.mu or own a.
+func (a *AuditLog) push(e AuditEntry) {
+	a.entries[a.next] = e
+	a.next = (a.next + 1) % len(a.entries)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// Recent returns up to limit entries, newest first. limit <= 0 returns all.
+func (a *AuditLog) Recent(limit int) []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := a.next
+	if a.full {
+		n = len(a.entries)
+	}
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]AuditEntry, 0, limit)
+	for i := 1; i <= limit; i++ {
+		out = append(out, a.entries[(a.next-i+len(a.entries))%len(a.entries)])
+	}
+	return out
+}
+
+// Close closes the backing file, if any.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuditLogMasksSensitiveValues(t *testing.T) {
+	a := NewAuditLog(4, "", []string{"password", "token"})
+	got := a.Redact(map[string]interface{}{
+		"user":     "ada",
+		"password": "hunter2",
+		"nested":   map[string]interface{}{"Token": "abc"},
+	})
+	if got["password"] != RedactedMask {
+		t.Errorf("password = %v, want %q", got["password"], RedactedMask)
+	}
+	if nested := got["nested"].(map[string]interface{}); nested["Token"] != RedactedMask {
+		t.Errorf("nested token = %v, want %q", nested["Token"], RedactedMask)
+	}
+	if got["user"] != "ada" {
+		t.Errorf("user = %v, want it untouched", got["user"])
+	}
+}
+
+func TestHMACAuditValue(t *testing.T) {
+	a, b := hmacAuditValue([]byte("k1")), hmacAuditValue([]byte("k2"))
+	first, again := a("hunter2"), a("hunter2")
+	if first != again {
+		t.Fatalf("same key and value gave %v and %v", first, again)
+	}
+	if s, _ := first.(string); !strings.HasPrefix(s, "hmac:") || strings.Contains(s, "hunter2") {
+		t.Fatalf("hmac value = %v", first)
+	}
+	if first == a("hunter3") {
+		t.Fatal("different values gave the same hmac")
+	}
+	if first == b("hunter2") {
+		t.Fatal("different keys gave the same hmac")
+	}
+}
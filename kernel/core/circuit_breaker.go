@@ -0,0 +1,143 @@
+// kernel/core/circuit_breaker.go
+package core
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by PythonClient while the orchestrator breaker
+// is open and calls are being fast-failed.
+var ErrCircuitOpen = errors.New("orchestrator circuit breaker open")
+
+// Breaker states reported by PythonClient.BreakerState.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// circuitBreaker opens after threshold consecutive failures, rejects calls
+// until cooldown has elapsed, then lets a single probe through. The probe's
+// outcome closes the breaker again or restarts the cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     string
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	probing   bool
+}
+
+// newCircuitBreakerFromEnv reads NEUROEDGE_BREAKER_FAILURES (count) and
+// NEUROEDGE_BREAKER_COOLDOWN (duration or seconds).
+func newCircuitBreakerFromEnv() *circuitBreaker {
+	threshold := defaultBreakerFailures
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_BREAKER_FAILURES"))); err == nil && n > 0 {
+		threshold = n
+	}
+	cooldown := defaultBreakerCooldown
+	if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_BREAKER_COOLDOWN")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			cooldown = d
+		} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+			cooldown = time.Duration(secs) * time.Second
+		}
+	}
+	return &circuitBreaker{state: BreakerClosed, threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may proceed, moving an open breaker to
+// half-open once the cooldown has passed.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// trip opens the breaker as if threshold failures had just happened, e.g.
+// for a backend that did not answer its startup probe.
+func (b *circuitBreaker) trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerOpen
+	b.failures = b.threshold
+	b.probing = false
+	b.openedAt = time.Now()
+}
+
+// abandon releases a half-open probe slot without recording an outcome,
+// e.g. when the caller cancelled the request.
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) currentState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// retryAfter is how long until an open breaker lets a probe through, or zero
+// when it is not open.
+func (b *circuitBreaker) retryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != BreakerOpen {
+		return 0
+	}
+	if left := b.cooldown - time.Since(b.openedAt); left > 0 {
+		return left
+	}
+	return 0
+}
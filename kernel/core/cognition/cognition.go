@@ -1,43 +1,222 @@
 package cognition
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/core/policy"
+	"neuroedge/kernel/core/textnorm"
 )
 
 type Cognition struct {
-	denyPatterns []string
+	policy.Matcher
+
+	// history is nil unless built with NewCognitionWithHistory.
+	history *decisionHistory
+
+	classifierMu      sync.RWMutex
+	classifier        Classifier
+	classifierTimeout time.Duration
+}
+
+// Classifier scores a task with an external model, such as the
+// orchestrator's safety model. verdict must be one of "approved",
+// "review_required" or "rejected"; score is informational.
+type Classifier interface {
+	Classify(ctx context.Context, task string) (verdict string, score float64, err error)
+}
+
+const defaultClassifierTimeout = 2 * time.Second
+
+// decisionRank orders decisions from least to most restrictive.
+var decisionRank = map[string]int{
+	"approved":        0,
+	"review_required": 1,
+	"rejected":        2,
+}
+
+// Decision is one recorded Decide call.
+type Decision struct {
+	Task           string    `json:"task"`
+	Decision       string    `json:"decision"`
+	Reason         string    `json:"reason,omitempty"`
+	MatchedPattern string    `json:"matched_pattern,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// decisionHistory is a fixed-size ring of the latest decisions.
+type decisionHistory struct {
+	mu      sync.Mutex
+	entries []Decision
+	next    int
+	full    bool
 }
 
 func NewCognition() *Cognition {
-	return &Cognition{
-		denyPatterns: []string{
-			"disable auth",
-			"bypass safety",
-			"drop database",
-			"wipe",
-		},
+	c := &Cognition{}
+	c.Load([]string{
+		"disable auth",
+		"bypass safety",
+		"drop database",
+		"wipe",
+	})
+	return c
+}
+
+// NewCognitionWithHistory is NewCognition that also remembers the last size
+// Decide calls for History. A size <= 0 records nothing.
+func NewCognitionWithHistory(size int) *Cognition {
+	c := NewCognition()
+	if size > 0 {
+		c.history = &decisionHistory{entries: make([]Decision, size)}
+	}
+	return c
+}
+
+// SetClassifier makes Decide consult cl after the static patterns and keep
+// the more restrictive of the two decisions. A call that errors, times out
+// (timeout <= 0 means 2s) or returns an unknown verdict is ignored and the
+// static decision stands. A nil cl removes the classifier.
+func (c *Cognition) SetClassifier(cl Classifier, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultClassifierTimeout
 	}
+	c.classifierMu.Lock()
+	defer c.classifierMu.Unlock()
+	c.classifier = cl
+	c.classifierTimeout = timeout
 }
 
 func (c *Cognition) Decide(task string, context map[string]interface{}) string {
 	fmt.Printf("🤖 Cognition deciding for task: %s\n", task)
-	normalized := strings.ToLower(strings.TrimSpace(task))
-	if normalized == "" {
-		return "review_required"
+	decision, reason, pattern := c.decide(task, context)
+	if c.history != nil {
+		c.history.record(Decision{
+			Task:           task,
+			Decision:       decision,
+			Reason:         reason,
+			MatchedPattern: pattern,
+			Timestamp:      time.Now().UTC(),
+		})
 	}
-	for _, p := range c.denyPatterns {
-		if strings.Contains(normalized, p) {
-			return "rejected"
-		}
+	return decision
+}
+
+// Explain returns the decision Decide would make and why, without logging
+// or recording it. The reason is empty for approved tasks.
+func (c *Cognition) Explain(task string, context map[string]interface{}) (string, string) {
+	decision, reason, _ := c.decide(task, context)
+	return decision, reason
+}
+
+func (c *Cognition) decide(task string, context map[string]interface{}) (decision, reason, pattern string) {
+	decision, reason, pattern = c.decideStatic(task, context)
+	if decision == "rejected" {
+		return decision, reason, pattern
+	}
+	if verdict, why, ok := c.classify(task); ok && decisionRank[verdict] > decisionRank[decision] {
+		return verdict, why, pattern
+	}
+	return decision, reason, pattern
+}
+
+// classify asks the configured classifier about task. ok is false when there
+// is no classifier or its answer can't be used.
+func (c *Cognition) classify(task string) (verdict, reason string, ok bool) {
+	c.classifierMu.RLock()
+	cl, timeout := c.classifier, c.classifierTimeout
+	c.classifierMu.RUnlock()
+	if cl == nil {
+		return "", "", false
+	}
+
+	type result struct {
+		verdict string
+		score   float64
+		err     error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// Run apart so a classifier that ignores ctx still can't hold Decide
+	// past the timeout.
+	done := make(chan result, 1)
+	go func() {
+		v, s, err := cl.Classify(ctx, task)
+		done <- result{v, s, err}
+	}()
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res.err = ctx.Err()
+	}
+	verdict, score, err := res.verdict, res.score, res.err
+	if err != nil {
+		fmt.Printf("🤖 Cognition classifier failed, using static patterns: %v\n", err)
+		return "", "", false
+	}
+	if _, known := decisionRank[verdict]; !known {
+		fmt.Printf("🤖 Cognition classifier returned unknown verdict %q, using static patterns\n", verdict)
+		return "", "", false
+	}
+	return verdict, fmt.Sprintf("classifier verdict %s (score %.2f)", verdict, score), true
+}
+
+// decideStatic applies the deny patterns and context flags only.
+func (c *Cognition) decideStatic(task string, context map[string]interface{}) (decision, reason, pattern string) {
+	// Folded as in ethics.Evaluate; callers log the original.
+	if textnorm.Fold(task) == "" {
+		return "review_required", "empty task", ""
+	}
+	if denied, pattern := c.Match(task); denied {
+		return "rejected", fmt.Sprintf("matched deny pattern %q", pattern), pattern
 	}
 	if context != nil {
 		if critical, ok := context["requires_human_approval"].(bool); ok && critical {
-			return "review_required"
+			return "review_required", "requires_human_approval is set", ""
 		}
 		if risk, ok := context["risk_level"].(string); ok && strings.EqualFold(risk, "high") {
-			return "review_required"
+			return "review_required", "risk_level is high", ""
 		}
 	}
-	return "approved"
+	return "approved", "", ""
+}
+
+// History returns up to limit recorded decisions, newest first; limit <= 0
+// returns all of them. It is empty unless built with NewCognitionWithHistory.
+func (c *Cognition) History(limit int) []Decision {
+	if c.history == nil {
+		return []Decision{}
+	}
+	return c.history.recent(limit)
+}
+
+func (h *decisionHistory) record(d Decision) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = d
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *decisionHistory) recent(limit int) []Decision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := h.next
+	if h.full {
+		n = len(h.entries)
+	}
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]Decision, 0, limit)
+	for i := 1; i <= limit; i++ {
+		out = append(out, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+	return out
 }
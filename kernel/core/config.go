@@ -1,24 +1,293 @@
 package core
 
 import (
+	"bufio"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 )
 
+// Config is the kernel configuration as of the last load. Call LoadConfig
+// at boot and ReloadConfig (on SIGHUP) to pick up edits to the config file.
 type Config struct {
-	Env       string
-	LogLevel  string
-	MeshPort  int
-	WDCNode   string
-	KernelID  string
+	Env      string
+	LogLevel string
+	MeshPort int
+	WDCNode  string
+	KernelID string
+
+	// File is NEUROEDGE_CONFIG_FILE and Values the settings read from it,
+	// which overlay the process environment.
+	File   string
+	Values map[string]string
+	// Changed lists the keys whose effective value differs from the
+	// previous load, sorted. Non-reloadable keys are never included.
+	Changed []string
+}
+
+// staticConfigKeys are read once, when the listener, middleware, clients
+// and engines are built or on first use; a reload that changes them is
+// logged and otherwise ignored. Keys read on every use (API keys, CORS, rate
+// limits, ethics patterns, fallback mode, engine allowlist...) are not
+// listed and reload.
+var staticConfigKeys = map[string]bool{
+	// Listener and TLS.
+	"PORT":                         true,
+	"HTTP_READ_TIMEOUT_SEC":        true,
+	"HTTP_READ_HEADER_TIMEOUT_SEC": true,
+	"HTTP_WRITE_TIMEOUT_SEC":       true,
+	"HTTP_IDLE_TIMEOUT_SEC":        true,
+	"HTTP_SHUTDOWN_TIMEOUT_SEC":    true,
+	"HTTP_MAX_HEADER_BYTES":        true,
+	"NEUROEDGE_TLS_CERT":           true,
+	"NEUROEDGE_TLS_KEY":            true,
+	"NEUROEDGE_TLS_CLIENT_CA":      true,
+	"NEUROEDGE_CONFIG_FILE":        true,
+
+	// Middleware, built with the router.
+	"NEUROEDGE_IP_ALLOW":                true,
+	"NEUROEDGE_IP_DENY":                 true,
+	"NEUROEDGE_TRUST_PROXY":             true,
+	"NEUROEDGE_MAX_BODY_BYTES":          true,
+	"NEUROEDGE_REQUEST_TIMEOUT":         true,
+	"NEUROEDGE_MAX_INFLIGHT":            true,
+	"NEUROEDGE_QUEUE_WAIT":              true,
+	"NEUROEDGE_QUEUE_DEPTH":             true,
+	"NEUROEDGE_ROUTE_LIMITS":            true,
+	"NEUROEDGE_RESERVED_SLOTS":          true,
+	"NEUROEDGE_LOG_FORMAT":              true,
+	"NEUROEDGE_REQUEST_ID_FORMAT":       true,
+	"NEUROEDGE_STATS_WINDOW":            true,
+	"NEUROEDGE_IDEMPOTENCY_MAX_ENTRIES": true,
+	"NEUROEDGE_REQUIRE_SIGNATURE":       true,
+	"NEUROEDGE_REQUEST_SIGNING_KEY":     true,
+	"NEUROEDGE_SIGNATURE_SKEW_SEC":      true,
+	"NEUROEDGE_SIGNATURE_SEEN_MAX":      true,
+
+	// Audit and redaction.
+	"NEUROEDGE_AUDIT_SIZE":        true,
+	"NEUROEDGE_AUDIT_FILE":        true,
+	"NEUROEDGE_AUDIT_REDACT_KEYS": true,
+	"NEUROEDGE_AUDIT_HMAC_KEY":    true,
+	"NEUROEDGE_REDACT_KEYS":       true,
+
+	// Orchestrator client.
+	"NEUROEDGE_ORCHESTRATOR_ADDR":            true,
+	"NEUROEDGE_ORCHESTRATOR_TLS":             true,
+	"NEUROEDGE_ORCHESTRATOR_INSECURE":        true,
+	"NEUROEDGE_ORCHESTRATOR_CA":              true,
+	"NEUROEDGE_ORCHESTRATOR_CERT":            true,
+	"NEUROEDGE_ORCHESTRATOR_KEY":             true,
+	"NEUROEDGE_ORCHESTRATOR_TOKEN":           true,
+	"NEUROEDGE_ORCHESTRATOR_DIAL_TIMEOUT_MS": true,
+	"NEUROEDGE_BREAKER_FAILURES":             true,
+	"NEUROEDGE_BREAKER_COOLDOWN":             true,
+	"NEUROEDGE_SAFETY_ENGINE":                true,
+	"NEUROEDGE_SAFETY_TIMEOUT_MS":            true,
+
+	// Kernel services and engines.
+	"NEUROEDGE_EVENT_HISTORY":          true,
+	"NEUROEDGE_HEALTH_INTERVAL":        true,
+	"NEUROEDGE_INFERENCE_CONCURRENCY":  true,
+	"NEUROEDGE_INFERENCE_TIMEOUT":      true,
+	"NEUROEDGE_COGNITION_HISTORY":      true,
+	"NEUROEDGE_NODE_HEARTBEAT_TIMEOUT": true,
+	"NEUROEDGE_NODE_RETENTION":         true,
+	"NEUROEDGE_OPTIMIZER_AUDIT_LOG":    true,
+	"NEUROEDGE_OPTIMIZER_MIN_REPLICAS": true,
+	"NEUROEDGE_OPTIMIZER_MAX_REPLICAS": true,
+	"NEUROEDGE_OPTIMIZER_STALE_AFTER":  true,
+	"NEUROEDGE_SCALER_COOLDOWN":        true,
+	"OTEL_TRACES_EXPORTER":             true,
+
+	// Mesh.
+	"NEUROEDGE_MESH_KEY":            true,
+	"NEUROEDGE_MESH_SECRET":         true,
+	"NEUROEDGE_MESH_NODE_ID":        true,
+	"NEUROEDGE_MESH_MSG_RATE":       true,
+	"NEUROEDGE_MESH_MSG_BURST":      true,
+	"NEUROEDGE_MESH_MAX_MSG_BYTES":  true,
+	"NEUROEDGE_MESH_OVERSIZE":       true,
+	"NEUROEDGE_MESH_NODE_TIMEOUT":   true,
+	"NEUROEDGE_MESH_ROUTE_FAILURES": true,
+	"NEUROEDGE_MESH_ROUTE_COOLDOWN": true,
+	"NEUROEDGE_MESH_GOSSIP_FANOUT":  true,
+	"NEUROEDGE_MESH_GOSSIP_TTL":     true,
 }
 
+var (
+	configMu      sync.Mutex
+	currentConfig *Config
+	// configBase holds the environment as it was before the file first
+	// set each key, so removing a key from the file restores it.
+	configBase      = map[string]*string{}
+	configListeners []func(*Config)
+)
+
+// LoadConfig performs the boot-time load. A bad config file is reported and
+// the environment is used as is.
 func LoadConfig() *Config {
+	cfg, err := ReloadConfig()
+	if err != nil {
+		fmt.Printf("⚠️ Config file not loaded, using environment only: %v\n", err)
+		return CurrentConfig()
+	}
+	return cfg
+}
+
+// CurrentConfig returns the most recently loaded Config.
+func CurrentConfig() *Config {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if currentConfig == nil {
+		currentConfig = buildConfig("", nil, nil)
+	}
+	return currentConfig
+}
+
+// OnConfigChange registers fn to run after a reload that changed at least
+// one setting. Callbacks run in registration order, after the new values
+// are visible through os.Getenv.
+func OnConfigChange(fn func(*Config)) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	configListeners = append(configListeners, fn)
+}
+
+// ReloadConfig re-reads NEUROEDGE_CONFIG_FILE (KEY=VALUE lines, # comments)
+// and applies it on top of the environment. The first load applies every
+// key; later loads skip staticConfigKeys. On error the previous settings
+// stay in effect.
+func ReloadConfig() (*Config, error) {
+	path := strings.TrimSpace(os.Getenv("NEUROEDGE_CONFIG_FILE"))
+	values := map[string]string{}
+	if path != "" {
+		var err error
+		if values, err = readConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	configMu.Lock()
+	first := currentConfig == nil
+	var prev map[string]string
+	if !first {
+		prev = currentConfig.Values
+	}
+
+	keys := map[string]bool{}
+	for k := range values {
+		keys[k] = true
+	}
+	for k := range prev {
+		keys[k] = true
+	}
+	var changed []string
+	for k := range keys {
+		want, inFile := values[k]
+		set := true
+		if !inFile {
+			// Dropped from the file: fall back to the original environment.
+			base, saved := configBase[k]
+			if !saved {
+				continue
+			}
+			want, set = "", base != nil
+			if set {
+				want = *base
+			}
+		}
+		if cur, ok := os.LookupEnv(k); cur == want && ok == set {
+			continue
+		}
+		if !first && staticConfigKeys[k] {
+			fmt.Printf("⚠️ Config reload ignored %s: it only takes effect on restart\n", k)
+			// Keep reporting the value actually in use.
+			if old, ok := prev[k]; ok {
+				values[k] = old
+			} else {
+				delete(values, k)
+			}
+			continue
+		}
+		if _, saved := configBase[k]; !saved {
+			if old, ok := os.LookupEnv(k); ok {
+				configBase[k] = &old
+			} else {
+				configBase[k] = nil
+			}
+		}
+		if set {
+			os.Setenv(k, want)
+		} else {
+			os.Unsetenv(k)
+		}
+		changed = append(changed, k)
+	}
+	sort.Strings(changed)
+
+	cfg := buildConfig(path, values, changed)
+	currentConfig = cfg
+	listeners := append([]func(*Config){}, configListeners...)
+	configMu.Unlock()
+
+	if !first && len(changed) > 0 {
+		fmt.Printf("🔄 Config reloaded from %s: %s\n", path, strings.Join(changed, ", "))
+		for _, fn := range listeners {
+			fn(cfg)
+		}
+	}
+	return cfg, nil
+}
+
+// readConfigFile parses KEY=VALUE lines; blank lines and lines starting
+// with # are skipped, and matching surrounding quotes are removed.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		values[key] = val
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func buildConfig(path string, values map[string]string, changed []string) *Config {
+	if values == nil {
+		values = map[string]string{}
+	}
 	return &Config{
 		Env:      getEnv("NEUROEDGE_ENV", "production"),
 		LogLevel: getEnv("NEUROEDGE_LOGLEVEL", "INFO"),
 		MeshPort: 8000,
 		WDCNode:  getEnv("NEUROEDGE_WDC_NODE", "https://wdc.node"),
 		KernelID: getEnv("NEUROEDGE_KERNEL_ID", "neuroedge-001"),
+		File:     path,
+		Values:   values,
+		Changed:  changed,
 	}
 }
 
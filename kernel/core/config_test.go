@@ -0,0 +1,61 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useFreshConfig gives the test its own config state and restores the
+// package's afterwards.
+func useFreshConfig(t *testing.T) {
+	t.Helper()
+	configMu.Lock()
+	prevConfig, prevBase, prevListeners := currentConfig, configBase, configListeners
+	currentConfig, configBase, configListeners = nil, map[string]*string{}, nil
+	configMu.Unlock()
+	t.Cleanup(func() {
+		configMu.Lock()
+		currentConfig, configBase, configListeners = prevConfig, prevBase, prevListeners
+		configMu.Unlock()
+	})
+}
+
+func TestReloadConfigIgnoresStaticKeys(t *testing.T) {
+	useFreshConfig(t)
+	path := filepath.Join(t.TempDir(), "kernel.env")
+	t.Setenv("NEUROEDGE_CONFIG_FILE", path)
+	for _, k := range []string{"NEUROEDGE_MAX_INFLIGHT", "NEUROEDGE_TRUST_PROXY", "NEUROEDGE_STRICT_COMMANDS"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("NEUROEDGE_MAX_INFLIGHT=10\nNEUROEDGE_TRUST_PROXY=false\nNEUROEDGE_STRICT_COMMANDS=false\n")
+	if _, err := ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	write("NEUROEDGE_MAX_INFLIGHT=99\nNEUROEDGE_TRUST_PROXY=true\nNEUROEDGE_STRICT_COMMANDS=true\n")
+	cfg, err := ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Changed) != 1 || cfg.Changed[0] != "NEUROEDGE_STRICT_COMMANDS" {
+		t.Fatalf("Changed = %v, want only NEUROEDGE_STRICT_COMMANDS", cfg.Changed)
+	}
+	if got := os.Getenv("NEUROEDGE_MAX_INFLIGHT"); got != "10" {
+		t.Errorf("NEUROEDGE_MAX_INFLIGHT = %q, want the boot value 10", got)
+	}
+	if got := cfg.Values["NEUROEDGE_TRUST_PROXY"]; got != "false" {
+		t.Errorf("reported NEUROEDGE_TRUST_PROXY = %q, want the value in use", got)
+	}
+	if got := os.Getenv("NEUROEDGE_STRICT_COMMANDS"); got != "true" {
+		t.Errorf("NEUROEDGE_STRICT_COMMANDS = %q, want the reloaded value", got)
+	}
+}
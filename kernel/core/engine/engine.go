@@ -24,7 +24,8 @@ func InitAllEngines(eventBus *types.EventBus) []interface{} {
 		engines.NewNeuroCloudEngine(eventBus),
 		engines.NewNeuroCodeEngine(eventBus),
 		engines.NewNeuroComputeEngine(eventBus),
-		engines.NewNeuroComputeOptimizer(eventBus),
+		engines.NewNeuroComputeOptimizerFromEnv(eventBus),
+		engines.NewScalerEngine(eventBus, nil),
 		engines.NewNeuroCreatorEngine(eventBus),
 		engines.NewNeuroDataEngine(eventBus),
 		engines.NewNeuroDefenseEngine(eventBus),
@@ -2,44 +2,210 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
+	"neuroedge/kernel/contracts"
 	"neuroedge/kernel/engines"
 	"neuroedge/kernel/types"
 )
 
-// EngineInterface ensures all engines implement these methods
-type EngineInterface interface {
+// Engine is the lifecycle every engine implements. Start and Stop are
+// called by EngineRegistry, at most once each per run.
+type Engine interface {
 	Start()
 	Stop()
 	Name() string
 }
 
-// EngineRegistry keeps track of all engines
+// EngineRegistry owns the engines' lifecycle: it starts them in
+// registration order and stops them in reverse, tracking which are running.
 type EngineRegistry struct {
-	Engines  map[string]EngineInterface
+	Engines  map[string]Engine
 	EventBus *types.EventBus
+
+	mu      sync.Mutex
+	order   []string
+	running map[string]bool
 }
 
 // NewEngineRegistry creates a new registry
 func NewEngineRegistry(bus *types.EventBus) *EngineRegistry {
 	return &EngineRegistry{
-		Engines:  make(map[string]EngineInterface),
+		Engines:  make(map[string]Engine),
 		EventBus: bus,
+		running:  make(map[string]bool),
 	}
 }
 
-// RegisterEngine registers a single engine
-func (r *EngineRegistry) RegisterEngine(engine EngineInterface) {
+// Register adds engine without starting it; StartAll starts it. A second
+// engine with the same name replaces the first, which is stopped if it was
+// running.
+func (r *EngineRegistry) Register(engine Engine) {
 	name := engine.Name()
+	r.mu.Lock()
+	old, exists := r.Engines[name]
+	wasRunning := r.running[name]
 	r.Engines[name] = engine
+	delete(r.running, name)
+	if !exists {
+		r.order = append(r.order, name)
+	}
+	r.mu.Unlock()
+
+	if exists && wasRunning {
+		stopEngine(old)
+	}
 	fmt.Println("[EngineRegistry] Registered engine:", name)
+}
+
+// RegisterEngine registers engine and starts it right away.
+func (r *EngineRegistry) RegisterEngine(engine Engine) {
+	r.Register(engine)
+	r.start(engine.Name())
+}
+
+// StartAll starts every registered engine that isn't running, in
+// registration order.
+func (r *EngineRegistry) StartAll() {
+	r.mu.Lock()
+	names := append([]string(nil), r.order...)
+	r.mu.Unlock()
+	for _, name := range names {
+		r.start(name)
+	}
+}
+
+// StopAll stops every running engine in reverse registration order. An
+// engine whose Stop panics is logged and counted as stopped so the rest
+// still shut down.
+func (r *EngineRegistry) StopAll() {
+	r.mu.Lock()
+	names := append([]string(nil), r.order...)
+	r.mu.Unlock()
+	for i := len(names) - 1; i >= 0; i-- {
+		r.stop(names[i])
+	}
+}
+
+// IsRunning reports whether the named engine has been started and not
+// stopped.
+func (r *EngineRegistry) IsRunning(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[name]
+}
+
+// Running returns every registered engine's name with whether it is
+// running.
+func (r *EngineRegistry) Running() map[string]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]bool, len(r.Engines))
+	for name := range r.Engines {
+		out[name] = r.running[name]
+	}
+	return out
+}
+
+func (r *EngineRegistry) start(name string) {
+	r.mu.Lock()
+	engine, ok := r.Engines[name]
+	if !ok || r.running[name] {
+		r.mu.Unlock()
+		return
+	}
+	r.running[name] = true
+	r.mu.Unlock()
 	engine.Start()
 }
 
+func (r *EngineRegistry) stop(name string) {
+	r.mu.Lock()
+	engine, ok := r.Engines[name]
+	if !ok || !r.running[name] {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.running, name)
+	r.mu.Unlock()
+	if stopEngine(engine) {
+		fmt.Println("[EngineRegistry] Stopped engine:", name)
+	}
+}
+
+// stopEngine calls engine.Stop, reporting false if it panicked.
+func stopEngine(engine Engine) (ok bool) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			fmt.Printf("⚠️ [EngineRegistry] Engine %s panicked while stopping: %v\n", engine.Name(), rec)
+			ok = false
+		}
+	}()
+	engine.Stop()
+	return true
+}
+
+// RegisterHealthChecks adds a check to hm for every registered engine that
+// implements contracts.HealthCheck, under the engine's name. A stopped
+// engine reports degraded rather than running its check.
+func (r *EngineRegistry) RegisterHealthChecks(hm *HealthManager) {
+	r.mu.Lock()
+	var checks []contracts.HealthCheck
+	for _, name := range r.order {
+		if hc, ok := r.Engines[name].(contracts.HealthCheck); ok {
+			checks = append(checks, hc)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, hc := range checks {
+		hc := hc
+		name := hc.Name()
+		hm.Register(name, func() error {
+			if !r.IsRunning(name) {
+				return Degraded(errors.New("engine stopped"))
+			}
+			return hc.CheckHealth()
+		})
+	}
+}
+
+// errNotStarted is the readiness error for a registered engine that isn't
+// running.
+var errNotStarted = errors.New("engine not started")
+
+// RegisterReadiness adds a predicate to t for every registered engine, so
+// the kernel is only ready once all of them are: an engine must be running,
+// and one that implements contracts.ReadinessCheck must also report Ready.
+func (r *EngineRegistry) RegisterReadiness(t *ReadinessTracker) {
+	r.mu.Lock()
+	names := append([]string(nil), r.order...)
+	r.mu.Unlock()
+
+	for _, name := range names {
+		name := name
+		t.Register("engine:"+name, func() error {
+			r.mu.Lock()
+			engine, running := r.Engines[name], r.running[name]
+			r.mu.Unlock()
+			if !running {
+				return errNotStarted
+			}
+			if rc, ok := engine.(contracts.ReadinessCheck); ok {
+				return rc.Ready()
+			}
+			return nil
+		})
+	}
+}
+
 // GetAllEngines returns all registered engines
-func (r *EngineRegistry) GetAllEngines() []EngineInterface {
-	all := make([]EngineInterface, 0, len(r.Engines))
+func (r *EngineRegistry) GetAllEngines() []Engine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make([]Engine, 0, len(r.Engines))
 	for _, e := range r.Engines {
 		all = append(all, e)
 	}
@@ -48,56 +214,55 @@ func (r *EngineRegistry) GetAllEngines() []EngineInterface {
 
 // RegisterAllEngines registers all 42 engines
 func (r *EngineRegistry) RegisterAllEngines() {
-	r.RegisterEngine(engines.NewNeuroLogicEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroGPTEngine(r.EventBus))
-	r.RegisterEngine(engines.NewTaskEmissionEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroVisionEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroAudioEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroCodeEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroOpsEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroDataEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroSearchEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroMedicalEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroFinanceEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroGovEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroLegalEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroSecurityEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroEdgeMeshEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroWDCWalletEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroChainValidatorEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroIdentityEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroAPIEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroMemoryEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroTranslateEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroEmotionsEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroMathEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroQuantumEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroComputeEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroHumanEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroTeacherEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroCEOEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroTradeEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroHREngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroResearchEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroCreatorEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuro3DEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroRobotEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroGeoEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroEdgeAntiTheftEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroDefenseEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroCloudEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroOfflineEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroSensorsEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroAgentsCoreEngine(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroComputeOptimizer(r.EventBus))
-	r.RegisterEngine(engines.NewNeuroFusionEngine(r.EventBus))
+	r.Register(engines.NewNeuroLogicEngine(r.EventBus))
+	r.Register(engines.NewNeuroGPTEngine(r.EventBus))
+	r.Register(engines.NewTaskEmissionEngine(r.EventBus))
+	r.Register(engines.NewNeuroVisionEngine(r.EventBus))
+	r.Register(engines.NewNeuroAudioEngine(r.EventBus))
+	r.Register(engines.NewNeuroCodeEngine(r.EventBus))
+	r.Register(engines.NewNeuroOpsEngine(r.EventBus))
+	r.Register(engines.NewNeuroDataEngine(r.EventBus))
+	r.Register(engines.NewNeuroSearchEngine(r.EventBus))
+	r.Register(engines.NewNeuroMedicalEngine(r.EventBus))
+	r.Register(engines.NewNeuroFinanceEngine(r.EventBus))
+	r.Register(engines.NewNeuroGovEngine(r.EventBus))
+	r.Register(engines.NewNeuroLegalEngine(r.EventBus))
+	r.Register(engines.NewNeuroSecurityEngine(r.EventBus))
+	r.Register(engines.NewNeuroEdgeMeshEngine(r.EventBus))
+	r.Register(engines.NewNeuroWDCWalletEngine(r.EventBus))
+	r.Register(engines.NewNeuroChainValidatorEngine(r.EventBus))
+	r.Register(engines.NewNeuroIdentityEngine(r.EventBus))
+	r.Register(engines.NewNeuroAPIEngine(r.EventBus))
+	r.Register(engines.NewNeuroMemoryEngine(r.EventBus))
+	r.Register(engines.NewNeuroTranslateEngine(r.EventBus))
+	r.Register(engines.NewNeuroEmotionsEngine(r.EventBus))
+	r.Register(engines.NewNeuroMathEngine(r.EventBus))
+	r.Register(engines.NewNeuroQuantumEngine(r.EventBus))
+	r.Register(engines.NewNeuroComputeEngine(r.EventBus))
+	r.Register(engines.NewNeuroHumanEngine(r.EventBus))
+	r.Register(engines.NewNeuroTeacherEngine(r.EventBus))
+	r.Register(engines.NewNeuroCEOEngine(r.EventBus))
+	r.Register(engines.NewNeuroTradeEngine(r.EventBus))
+	r.Register(engines.NewNeuroHREngine(r.EventBus))
+	r.Register(engines.NewNeuroResearchEngine(r.EventBus))
+	r.Register(engines.NewNeuroCreatorEngine(r.EventBus))
+	r.Register(engines.NewNeuro3DEngine(r.EventBus))
+	r.Register(engines.NewNeuroRobotEngine(r.EventBus))
+	r.Register(engines.NewNeuroGeoEngine(r.EventBus))
+	r.Register(engines.NewNeuroEdgeAntiTheftEngine(r.EventBus))
+	r.Register(engines.NewNeuroDefenseEngine(r.EventBus))
+	r.Register(engines.NewNeuroCloudEngine(r.EventBus))
+	r.Register(engines.NewNeuroOfflineEngine(r.EventBus))
+	r.Register(engines.NewNeuroSensorsEngine(r.EventBus))
+	r.Register(engines.NewNeuroAgentsCoreEngine(r.EventBus))
+	r.Register(engines.NewNeuroComputeOptimizerFromEnv(r.EventBus))
+	r.Register(engines.NewScalerEngine(r.EventBus, nil))
+	r.Register(engines.NewNeuroFusionEngine(r.EventBus))
+	r.StartAll()
 	fmt.Println("[EngineRegistry] All 42 engines registered and started ✅")
 }
 
 // StopAllEngines stops all engines
 func (r *EngineRegistry) StopAllEngines() {
-	for name, engine := range r.Engines {
-		engine.Stop()
-		fmt.Println("[EngineRegistry] Stopped engine:", name)
-	}
+	r.StopAll()
 }
@@ -1,15 +1,134 @@
 package ethics
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/core/policy"
+	"neuroedge/kernel/core/textnorm"
 )
 
+// defaultThreshold blocks on any single default-weight match, which is how
+// Ethics behaved before patterns had weights.
+const defaultThreshold = 1.0
+
 type Ethics struct {
-	denyPatterns []string
+	policy.Matcher
+
+	// threshold is the score at or above which Evaluate blocks.
+	threshold float64
+}
+
+// policyFile is the NEUROEDGE_ETHICS_POLICY_FILE format:
+//
+//	{"threshold": 1.0, "patterns": [{"pattern": "disable auth", "weight": 1.0},
+//	                                {"pattern": "sudo", "weight": 0.4}]}
+type policyFile struct {
+	Threshold float64          `json:"threshold"`
+	Patterns  []policy.Pattern `json:"patterns"`
+}
+
+var (
+	policyMu      sync.Mutex
+	policyPath    string
+	policyModTime time.Time
+	policyCached  *policyFile
+)
+
+// loadPolicyFile reads path, re-reading only when its modification time
+// changes. It returns nil when the file is missing or invalid, including
+// when a pattern has a negative weight.
+func loadPolicyFile(path string) *policyFile {
+	info, err := os.Stat(path)
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if err != nil {
+		// Warn once, not on every evaluation.
+		if path != policyPath || !policyModTime.IsZero() {
+			fmt.Printf("⚠️ Ethics policy file unavailable, using defaults: %v\n", err)
+		}
+		policyPath, policyModTime, policyCached = path, time.Time{}, nil
+		return nil
+	}
+	if path == policyPath && info.ModTime().Equal(policyModTime) {
+		return policyCached
+	}
+	policyPath, policyModTime, policyCached = path, info.ModTime(), nil
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("⚠️ Ethics policy file unavailable, using defaults: %v\n", err)
+		return nil
+	}
+	var pf policyFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		fmt.Printf("⚠️ Failed to parse ethics policy, using defaults: %v\n", err)
+		return nil
+	}
+	for i := range pf.Patterns {
+		if pf.Patterns[i].Weight < 0 {
+			fmt.Printf("⚠️ Invalid ethics policy, using defaults: %s:%d: pattern %q has negative weight %g\n",
+				path, patternLine(data, i), pf.Patterns[i].Pattern, pf.Patterns[i].Weight)
+			return nil
+		}
+		if pf.Patterns[i].Weight == 0 {
+			pf.Patterns[i].Weight = 1
+		}
+	}
+	policyCached = &pf
+	return policyCached
 }
 
+// patternLine returns the 1-based line where entry index of the top-level
+// "patterns" array starts in data, or 0 if it can't be found.
+func patternLine(data []byte, index int) int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		if key != "patterns" {
+			var skip json.RawMessage
+			if dec.Decode(&skip) != nil {
+				return 0
+			}
+			continue
+		}
+		if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+			return 0
+		}
+		for i := 0; dec.More(); i++ {
+			if i == index {
+				// The offset may sit before the separating comma or spaces.
+				off := int(dec.InputOffset())
+				for off < len(data) && bytes.IndexByte([]byte(" \t\r\n,"), data[off]) >= 0 {
+					off++
+				}
+				return bytes.Count(data[:off], []byte("\n")) + 1
+			}
+			var skip json.RawMessage
+			if dec.Decode(&skip) != nil {
+				return 0
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// NewEthics builds the deny list from, in order of precedence,
+// NEUROEDGE_ETHICS_POLICY_FILE (weighted patterns and threshold),
+// NEUROEDGE_ETHICS_DENY_PATTERNS (comma-separated, weight 1) or the
+// built-in list. NEUROEDGE_ETHICS_THRESHOLD overrides the threshold.
 func NewEthics() *Ethics {
 	raw := os.Getenv("NEUROEDGE_ETHICS_DENY_PATTERNS")
 	patterns := []string{
@@ -22,28 +141,70 @@ func NewEthics() *Ethics {
 	if strings.TrimSpace(raw) != "" {
 		custom := []string{}
 		for _, p := range strings.Split(raw, ",") {
-			trimmed := strings.ToLower(strings.TrimSpace(p))
-			if trimmed != "" {
-				custom = append(custom, trimmed)
+			if textnorm.Fold(p) != "" {
+				custom = append(custom, p)
 			}
 		}
 		if len(custom) > 0 {
 			patterns = custom
 		}
 	}
-	return &Ethics{denyPatterns: patterns}
+	e := &Ethics{threshold: defaultThreshold}
+	e.Load(patterns)
+
+	if path := strings.TrimSpace(os.Getenv("NEUROEDGE_ETHICS_POLICY_FILE")); path != "" {
+		if pf := loadPolicyFile(path); pf != nil {
+			if len(pf.Patterns) > 0 {
+				if err := e.LoadWeighted(pf.Patterns); err != nil {
+					fmt.Printf("⚠️ Invalid ethics policy, using defaults: %v\n", err)
+				}
+			}
+			if pf.Threshold > 0 {
+				e.threshold = pf.Threshold
+			}
+		}
+	}
+	if t, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("NEUROEDGE_ETHICS_THRESHOLD")), 64); err == nil && t > 0 {
+		e.threshold = t
+	}
+	return e
+}
+
+// Score sums the weights of every deny pattern action matches.
+func (e *Ethics) Score(action string) float64 {
+	score, _ := e.score(action)
+	return score
+}
+
+func (e *Ethics) score(action string) (float64, []string) {
+	var score float64
+	var matched []string
+	for _, p := range e.MatchAll(action) {
+		score += p.Weight
+		matched = append(matched, strconv.Quote(p.Pattern))
+	}
+	return score, matched
 }
 
 func (e *Ethics) Evaluate(action string) bool {
 	fmt.Printf("⚖️ Evaluating ethics for action: %s\n", action)
-	candidate := strings.ToLower(strings.TrimSpace(action))
-	if candidate == "" {
-		return false
+	allowed, reason := e.Explain(action)
+	if !allowed {
+		fmt.Printf("⚖️ Ethics denied action, %s\n", reason)
 	}
-	for _, p := range e.denyPatterns {
-		if strings.Contains(candidate, p) {
-			return false
-		}
+	return allowed
+}
+
+// Explain returns what Evaluate would decide and why, without logging. The
+// reason is empty for allowed actions.
+func (e *Ethics) Explain(action string) (bool, string) {
+	// Matching folds lookalike glyphs and zero-width characters; callers
+	// log the original.
+	if textnorm.Fold(action) == "" {
+		return false, "empty action"
+	}
+	if score, matched := e.score(action); score >= e.threshold {
+		return false, fmt.Sprintf("score %.2f reached threshold %.2f (matched %s)", score, e.threshold, strings.Join(matched, ", "))
 	}
-	return true
+	return true, ""
 }
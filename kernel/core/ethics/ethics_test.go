@@ -0,0 +1,64 @@
+package ethics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const negativePolicy = `{
+  "threshold": 1.0,
+  "patterns": [
+    {"pattern": "disable auth", "weight": 1.0},
+    {"pattern": "sudo", "weight": 0.4},
+    {
+      "pattern": "please", "weight": -5
+    }
+  ]
+}
+`
+
+func writePolicy(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPolicyFileRejectsNegativeWeights(t *testing.T) {
+	if pf := loadPolicyFile(writePolicy(t, negativePolicy)); pf != nil {
+		t.Fatalf("loaded a policy with a negative weight: %+v", pf)
+	}
+
+	t.Setenv("NEUROEDGE_ETHICS_DENY_PATTERNS", "")
+	t.Setenv("NEUROEDGE_ETHICS_THRESHOLD", "")
+	t.Setenv("NEUROEDGE_ETHICS_POLICY_FILE", writePolicy(t, negativePolicy))
+	// The negative weight must not cancel out a default pattern.
+	if allowed, _ := NewEthics().Explain("please rm -rf /"); allowed {
+		t.Fatal("negative weight lowered the score below the threshold")
+	}
+}
+
+func TestPatternLine(t *testing.T) {
+	data := []byte(negativePolicy)
+	for index, want := range []int{4, 5, 6} {
+		if got := patternLine(data, index); got != want {
+			t.Fatalf("patternLine(%d) = %d, want %d", index, got, want)
+		}
+	}
+	if got := patternLine(data, 3); got != 0 {
+		t.Fatalf("patternLine past the end = %d, want 0", got)
+	}
+	if got := patternLine([]byte(`{"patterns": [{"pattern": "a", "weight": -1}]}`), 0); got != 1 {
+		t.Fatalf("single-line policy: patternLine = %d, want 1", got)
+	}
+}
+
+func TestLoadPolicyFileDefaultsZeroWeight(t *testing.T) {
+	pf := loadPolicyFile(writePolicy(t, `{"patterns": [{"pattern": "sudo"}]}`))
+	if pf == nil || len(pf.Patterns) != 1 || pf.Patterns[0].Weight != 1 {
+		t.Fatalf("policy = %+v, want sudo with weight 1", pf)
+	}
+}
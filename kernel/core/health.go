@@ -1,8 +1,10 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"runtime"
 	"sync"
 	"time"
@@ -10,13 +12,53 @@ import (
 	"neuroedge/kernel/contracts"
 )
 
-// HealthStatus represents the health state of a component
+// Component and kernel health states, from best to worst. A component is
+// unknown until its first check has run.
+const (
+	HealthHealthy   = "healthy"
+	HealthUnknown   = "unknown"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+)
+
+// ErrDegraded marks a check failure that still leaves the component serving.
+// Wrap it with Degraded so the status becomes degraded rather than unhealthy.
+var ErrDegraded = errors.New("degraded")
+
+// Degraded returns reason marked as a degraded-but-serving condition.
+func Degraded(reason error) error {
+	return fmt.Errorf("%w: %w", ErrDegraded, reason)
+}
+
+// RollupHealth returns the worst of statuses, or healthy when there are none.
+func RollupHealth(statuses []string) string {
+	overall := HealthHealthy
+	for _, s := range statuses {
+		switch s {
+		case HealthUnhealthy:
+			return HealthUnhealthy
+		case HealthDegraded:
+			overall = HealthDegraded
+		case HealthUnknown:
+			if overall == HealthHealthy {
+				overall = HealthUnknown
+			}
+		}
+	}
+	return overall
+}
+
+// HealthStatus represents the health state of a component. Healthy is true
+// for both healthy and degraded, matching its meaning before Status existed.
 type HealthStatus struct {
 	Name       string
+	Status     string
 	Healthy    bool
 	LastCheck  time.Time
 	LastError  error
 	Additional string
+	// LastDuration is how long the most recent check took.
+	LastDuration time.Duration
 }
 
 // HealthManager monitors and checks the health of components
@@ -24,20 +66,41 @@ type HealthManager struct {
 	components []contracts.HealthCheck
 	statuses   map[string]*HealthStatus
 	mu         sync.Mutex
+	interval   time.Duration
 	ticker     *time.Ticker
 	stopChan   chan bool
 }
 
-// NewHealthManager creates a new health manager
+// NewHealthManager creates a new health manager. Checks run every
+// NEUROEDGE_HEALTH_INTERVAL (duration, default 10s) once monitoring starts.
 func NewHealthManager() *HealthManager {
+	interval := 10 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("NEUROEDGE_HEALTH_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
 	return &HealthManager{
 		components: make([]contracts.HealthCheck, 0),
 		statuses:   make(map[string]*HealthStatus),
-		ticker:     time.NewTicker(10 * time.Second),
+		interval:   interval,
 		stopChan:   make(chan bool),
 	}
 }
 
+// funcCheck adapts a plain check function to contracts.HealthCheck.
+type funcCheck struct {
+	name  string
+	check func() error
+}
+
+func (f funcCheck) Name() string       { return f.name }
+func (f funcCheck) CheckHealth() error { return f.check() }
+
+// Register adds a named check function, for callers that are not
+// components themselves (e.g. the orchestrator client's Ping).
+func (hm *HealthManager) Register(name string, check func() error) {
+	hm.RegisterComponent(funcCheck{name: name, check: check})
+}
+
 // RegisterComponent adds a component for health monitoring
 func (hm *HealthManager) RegisterComponent(c contracts.HealthCheck) {
 	hm.mu.Lock()
@@ -46,6 +109,7 @@ func (hm *HealthManager) RegisterComponent(c contracts.HealthCheck) {
 	hm.components = append(hm.components, c)
 	hm.statuses[c.Name()] = &HealthStatus{
 		Name:      c.Name(),
+		Status:    HealthUnknown,
 		Healthy:   false,
 		LastCheck: time.Now(),
 	}
@@ -54,8 +118,10 @@ func (hm *HealthManager) RegisterComponent(c contracts.HealthCheck) {
 // StartMonitoring begins periodic health checks
 func (hm *HealthManager) StartMonitoring() {
 	fmt.Println("🩺 Health Monitoring Started")
+	hm.ticker = time.NewTicker(hm.interval)
 
 	go func() {
+		hm.runChecks()
 		for {
 			select {
 			case <-hm.ticker.C:
@@ -70,6 +136,9 @@ func (hm *HealthManager) StartMonitoring() {
 
 // StopMonitoring stops the periodic health checks
 func (hm *HealthManager) StopMonitoring() {
+	if hm.ticker == nil {
+		return
+	}
 	hm.stopChan <- true
 	hm.ticker.Stop()
 }
@@ -90,37 +159,55 @@ func (hm *HealthManager) StatusesSnapshot() map[string]*HealthStatus {
 // Global instance for API
 var GlobalHealthManager = NewHealthManager()
 
-// runChecks performs health checks on all registered components
+// runChecks performs health checks on all registered components. Checks run
+// without holding the lock so a slow one doesn't stall StatusesSnapshot.
 func (hm *HealthManager) runChecks() {
 	hm.mu.Lock()
-	defer hm.mu.Unlock()
-
-	for _, comp := range hm.components {
-		func(c contracts.HealthCheck) {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("⚠️ Panic recovered from component %s: %v", c.Name(), r)
-					hm.statuses[c.Name()].Healthy = false
-					hm.statuses[c.Name()].LastError = fmt.Errorf("panic: %v", r)
-				}
-			}()
-
-			err := c.CheckHealth()
-			status := hm.statuses[c.Name()]
-			status.LastCheck = time.Now()
-
-			if err != nil {
-				status.Healthy = false
-				status.LastError = err
-				log.Printf("⚠️ Component %s unhealthy: %v", c.Name(), err)
-			} else {
-				status.Healthy = true
-				status.LastError = nil
-			}
-		}(comp)
+	components := append([]contracts.HealthCheck(nil), hm.components...)
+	hm.mu.Unlock()
+
+	for _, comp := range components {
+		started := time.Now()
+		err := runCheck(comp)
+		elapsed := time.Since(started)
+
+		hm.mu.Lock()
+		status := hm.statuses[comp.Name()]
+		status.LastCheck = time.Now()
+		status.LastDuration = elapsed
+		switch {
+		case errors.Is(err, ErrDegraded):
+			status.Status = HealthDegraded
+			status.Healthy = true
+			status.LastError = err
+			log.Printf("⚠️ Component %s degraded: %v", comp.Name(), err)
+		case err != nil:
+			status.Status = HealthUnhealthy
+			status.Healthy = false
+			status.LastError = err
+			log.Printf("⚠️ Component %s unhealthy: %v", comp.Name(), err)
+		default:
+			status.Status = HealthHealthy
+			status.Healthy = true
+			status.LastError = nil
+		}
+		hm.mu.Unlock()
 	}
 
+	hm.mu.Lock()
 	hm.printSummary()
+	hm.mu.Unlock()
+}
+
+// runCheck calls one component's check, turning a panic into an error.
+func runCheck(c contracts.HealthCheck) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Panic recovered from component %s: %v", c.Name(), r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.CheckHealth()
 }
 
 // printSummary outputs a structured health report
@@ -129,7 +216,12 @@ func (hm *HealthManager) printSummary() {
 
 	for _, status := range hm.statuses {
 		healthStr := "✅ Healthy"
-		if !status.Healthy {
+		switch status.Status {
+		case HealthUnknown:
+			healthStr = "⏳ Pending first check"
+		case HealthDegraded:
+			healthStr = fmt.Sprintf("🟡 Degraded (%v)", status.LastError)
+		case HealthUnhealthy:
 			healthStr = fmt.Sprintf("⚠️ Unhealthy (last error: %v)", status.LastError)
 		}
 
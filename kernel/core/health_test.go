@@ -0,0 +1,55 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRollupHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{name: "no components", want: HealthHealthy},
+		{name: "all healthy", statuses: []string{HealthHealthy, HealthHealthy}, want: HealthHealthy},
+		{name: "pending check", statuses: []string{HealthHealthy, HealthUnknown}, want: HealthUnknown},
+		{name: "degraded beats unknown", statuses: []string{HealthUnknown, HealthDegraded, HealthHealthy}, want: HealthDegraded},
+		{name: "unhealthy wins", statuses: []string{HealthDegraded, HealthUnhealthy, HealthUnknown}, want: HealthUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RollupHealth(tt.statuses); got != tt.want {
+				t.Fatalf("RollupHealth(%v) = %q, want %q", tt.statuses, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentsAreUnknownUntilChecked(t *testing.T) {
+	hm := NewHealthManager()
+	hm.Register("ok", func() error { return nil })
+	hm.Register("slow", func() error { return Degraded(errors.New("slow")) })
+	hm.Register("down", func() error { return errors.New("down") })
+
+	for name, s := range hm.StatusesSnapshot() {
+		if s.Status != HealthUnknown {
+			t.Fatalf("%s before first check = %q, want %q", name, s.Status, HealthUnknown)
+		}
+	}
+
+	hm.runChecks()
+	want := map[string]struct {
+		status  string
+		healthy bool
+	}{
+		"ok":   {HealthHealthy, true},
+		"slow": {HealthDegraded, true},
+		"down": {HealthUnhealthy, false},
+	}
+	for name, s := range hm.StatusesSnapshot() {
+		if s.Status != want[name].status || s.Healthy != want[name].healthy {
+			t.Fatalf("%s = %q healthy=%v, want %q healthy=%v", name, s.Status, s.Healthy, want[name].status, want[name].healthy)
+		}
+	}
+}
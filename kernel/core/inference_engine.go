@@ -0,0 +1,232 @@
+// kernel/core/inference_engine.go
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+	"neuroedge/kernel/types"
+)
+
+const (
+	InferenceRequestTopic  = "inference:request"
+	InferenceResponseTopic = "inference:response"
+)
+
+// InferenceEngine turns inference:request events into orchestrator tasks
+// and publishes each result on inference:response. Requests pass the agent
+// guard first, and at most MaxConcurrent run at once; the bus delivers each
+// event on its own goroutine, so requests beyond that wait for a slot
+// without stalling the publisher. Requests still waiting at Stop are
+// answered "cancelled".
+//
+// A request event's Data is a map with "id", "engine" (required), "input"
+// (string, or any JSON value) and optionally "agent"; the response carries
+// "id", "status" and "output", or "error" and "blocked_reason".
+type InferenceEngine struct {
+	EventBus *types.EventBus
+	Client   pb.OrchestratorClient
+	// MaxConcurrent bounds in-flight orchestrator calls
+	// (NEUROEDGE_INFERENCE_CONCURRENCY, default 4).
+	MaxConcurrent int
+	// Timeout bounds each call (NEUROEDGE_INFERENCE_TIMEOUT, Go duration or
+	// seconds, default 30s).
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	sub      types.Subscription
+	running  bool
+	slots    chan struct{}
+	inflight sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// NewInferenceEngine dispatches bus inference requests to client.
+func NewInferenceEngine(bus *types.EventBus, client pb.OrchestratorClient) *InferenceEngine {
+	e := &InferenceEngine{
+		EventBus:      bus,
+		Client:        client,
+		MaxConcurrent: 4,
+		Timeout:       30 * time.Second,
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_INFERENCE_CONCURRENCY"))); err == nil && n > 0 {
+		e.MaxConcurrent = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_INFERENCE_TIMEOUT")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			e.Timeout = d
+		} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+			e.Timeout = time.Duration(secs) * time.Second
+		}
+	}
+	return e
+}
+
+func (e *InferenceEngine) Name() string {
+	return "InferenceEngine"
+}
+
+func (e *InferenceEngine) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.running {
+		return
+	}
+	limit := e.MaxConcurrent
+	if limit <= 0 {
+		limit = 1
+	}
+	e.slots = make(chan struct{}, limit)
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.sub = e.EventBus.Subscribe(InferenceRequestTopic, e.handle)
+	e.running = true
+	fmt.Printf("🚀 InferenceEngine started (max %d concurrent)\n", limit)
+}
+
+// Stop unsubscribes, cancels waiting and in-flight requests and waits for
+// them to publish their responses.
+func (e *InferenceEngine) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.EventBus.Unsubscribe(e.sub)
+	e.cancel()
+	e.running = false
+	e.mu.Unlock()
+
+	e.inflight.Wait()
+	fmt.Println("🛑 InferenceEngine stopped")
+}
+
+// Ready reports whether the engine is subscribed to inference:request. It
+// implements contracts.ReadinessCheck together with Name.
+func (e *InferenceEngine) Ready() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.running || !e.EventBus.Subscribed(e.sub) {
+		return fmt.Errorf("not subscribed to %s", InferenceRequestTopic)
+	}
+	return nil
+}
+
+// inferenceRequest is the decoded Data of an inference:request event.
+type inferenceRequest struct {
+	ID     string
+	Engine string
+	Input  string
+	Agent  string
+}
+
+func (e *InferenceEngine) handle(evt types.Event) {
+	req, err := decodeInferenceRequest(evt.Data)
+	if err != nil {
+		e.respond(req.ID, map[string]interface{}{"status": "invalid", "error": err.Error()})
+		return
+	}
+
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	slots, ctx := e.slots, e.ctx
+	e.inflight.Add(1)
+	e.mu.Unlock()
+	defer e.inflight.Done()
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		e.respond(req.ID, map[string]interface{}{"status": "cancelled", "error": "inference engine stopped"})
+		return
+	}
+	defer func() { <-slots }()
+	e.dispatch(ctx, req)
+}
+
+func (e *InferenceEngine) dispatch(ctx context.Context, req inferenceRequest) {
+	ok, reason := preExecutionCheck(req.Agent, req.Input)
+	Audit().Record(AuditEntry{
+		RequestID:     req.ID,
+		Type:          "inference",
+		Agent:         req.Agent,
+		Action:        req.Engine,
+		Success:       ok,
+		BlockedReason: reason,
+	})
+	if !ok {
+		e.respond(req.ID, map[string]interface{}{"status": "blocked", "blocked_reason": reason})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+	resp, err := e.Client.SubmitTask(ctx, &pb.TaskRequest{
+		EngineName: req.Engine,
+		TaskId:     req.ID,
+		InputData:  req.Input,
+	})
+	if errors.Is(err, context.Canceled) {
+		e.respond(req.ID, map[string]interface{}{"status": "cancelled", "error": "inference engine stopped"})
+		return
+	}
+	if err != nil {
+		e.respond(req.ID, map[string]interface{}{"status": "error", "error": err.Error()})
+		return
+	}
+	e.respond(req.ID, map[string]interface{}{"status": resp.Status, "output": resp.OutputData})
+}
+
+func (e *InferenceEngine) respond(id string, data map[string]interface{}) {
+	data["id"] = id
+	e.EventBus.Publish(types.Event{
+		Name:   InferenceResponseTopic,
+		Data:   data,
+		Source: e.Name(),
+	})
+}
+
+// decodeInferenceRequest reads an event payload. The ID is filled in even
+// when the rest is invalid, so the error response can be correlated.
+func decodeInferenceRequest(data interface{}) (inferenceRequest, error) {
+	var req inferenceRequest
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return req, fmt.Errorf("request data must be an object")
+	}
+	req.ID, _ = m["id"].(string)
+	req.Engine, _ = m["engine"].(string)
+	req.Agent, _ = m["agent"].(string)
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("inference-%d", time.Now().UnixNano())
+	}
+	if strings.TrimSpace(req.Engine) == "" {
+		return req, fmt.Errorf("engine is required")
+	}
+	if req.Agent == "" {
+		req.Agent = "InferenceEngine"
+	}
+	switch input := m["input"].(type) {
+	case string:
+		req.Input = input
+	case nil:
+	default:
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return req, fmt.Errorf("input is not JSON-encodable: %v", err)
+		}
+		req.Input = string(raw)
+	}
+	return req, nil
+}
@@ -0,0 +1,162 @@
+// kernel/core/job_client.go
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pollInitialDelay = 250 * time.Millisecond
+	pollMaxDelay     = 5 * time.Second
+	// pollDeadlineMargin is how long before the context deadline the last
+	// poll is made, so it still has time to complete.
+	pollDeadlineMargin = 100 * time.Millisecond
+)
+
+// ErrJobFailed is returned by PollJob, wrapped, when the job finished as
+// failed or cancelled.
+var ErrJobFailed = errors.New("job failed")
+
+// KernelResponse is the kernel's reply to a command, as returned by
+// /execute and carried in finished async jobs.
+type KernelResponse struct {
+	ID        string      `json:"id"`
+	Success   bool        `json:"success"`
+	Stdout    string      `json:"stdout,omitempty"`
+	Stderr    string      `json:"stderr,omitempty"`
+	Timestamp string      `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// jobStatus is the GET /v1/kernel/jobs/{id} body.
+type jobStatus struct {
+	ID       string          `json:"id"`
+	Status   string          `json:"status"`
+	Response *KernelResponse `json:"response,omitempty"`
+}
+
+// PollJob polls GET /v1/kernel/jobs/{id} on the kernel at baseURL (e.g.
+// "http://localhost:8080") until the job is done, failed or cancelled, or
+// ctx ends. apiKey is sent as X-API-Key when set.
+//
+// Polls start 250ms apart and double up to 5s, each wait jittered between
+// half and all of the current delay so clients that submitted together
+// spread out. A Retry-After on 429 or 503 replies stretches the wait. When
+// ctx has a deadline, the final poll is made just before it rather than
+// sleeping past it.
+//
+// A done job returns its response. Failed and cancelled jobs return their
+// response, if any, along with an error wrapping ErrJobFailed. Network
+// errors, 429 and 5xx replies are retried; other error statuses, such as
+// 404 for an unknown or expired job, end polling at once.
+func PollJob(ctx context.Context, baseURL, jobID string, apiKey string) (*KernelResponse, error) {
+	endpoint := strings.TrimRight(baseURL, "/") + "/v1/kernel/jobs/" + url.PathEscape(jobID)
+	client := &http.Client{}
+	delay := pollInitialDelay
+
+	for {
+		status, retryAfter, err := fetchJobStatus(ctx, client, endpoint, apiKey)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var retry *retryableError
+		if err != nil && !errors.As(err, &retry) {
+			return nil, err
+		}
+		if err == nil {
+			switch status.Status {
+			case "done":
+				return status.Response, nil
+			case "failed", "cancelled":
+				reason := status.Status
+				if status.Response != nil && status.Response.Stderr != "" {
+					reason = status.Response.Stderr
+				}
+				return status.Response, fmt.Errorf("%w: job %s: %s", ErrJobFailed, jobID, reason)
+			}
+		}
+
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			left := time.Until(deadline) - pollDeadlineMargin
+			if left <= 0 {
+				// That was the last poll that could finish in time.
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			if wait > left {
+				wait = left
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		delay *= 2
+		if delay > pollMaxDelay {
+			delay = pollMaxDelay
+		}
+	}
+}
+
+// retryableError marks a poll failure worth trying again.
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// fetchJobStatus makes one poll, returning the job and any Retry-After the
+// kernel asked for.
+func fetchJobStatus(ctx context.Context, client *http.Client, endpoint, apiKey string) (*jobStatus, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, &retryableError{err}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, 0, &retryableError{err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("job poll returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			var retryAfter time.Duration
+			if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+				retryAfter = time.Duration(secs) * time.Second
+			}
+			return nil, retryAfter, &retryableError{err}
+		}
+		return nil, 0, err
+	}
+
+	var status jobStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, 0, fmt.Errorf("decode job status: %w", err)
+	}
+	return &status, 0, nil
+}
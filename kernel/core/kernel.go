@@ -2,6 +2,8 @@
 package core
 
 import (
+	"os"
+	"strconv"
 	"time"
 
 	"neuroedge/kernel/config"
@@ -9,6 +11,19 @@ import (
 	"neuroedge/kernel/types"
 )
 
+// GlobalEventBus is the process-wide bus shared by engines and the API bridge.
+var GlobalEventBus = newGlobalEventBus()
+
+// newGlobalEventBus retains NEUROEDGE_EVENT_HISTORY events per topic for
+// late subscribers; unset keeps history off.
+func newGlobalEventBus() *types.EventBus {
+	bus := types.NewEventBus()
+	if n, err := strconv.Atoi(os.Getenv("NEUROEDGE_EVENT_HISTORY")); err == nil && n > 0 {
+		bus.SetHistorySize(n)
+	}
+	return bus
+}
+
 type Kernel struct {
 	Config   *config.KernelConfig
 	EventBus *types.EventBus
@@ -18,7 +33,7 @@ type Kernel struct {
 func NewKernel(cfg *config.KernelConfig) *Kernel {
 	return &Kernel{
 		Config:   cfg,
-		EventBus: types.NewEventBus(),
+		EventBus: GlobalEventBus,
 	}
 }
 
@@ -0,0 +1,111 @@
+// kernel/core/orchestrator_credentials.go
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// orchestratorSecurity is the transport and auth configuration for reaching
+// the orchestrator, read from NEUROEDGE_ORCHESTRATOR_* env vars.
+type orchestratorSecurity struct {
+	tlsConfig     *tls.Config
+	token         string
+	allowInsecure bool
+}
+
+func envTrue(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// loadOrchestratorSecurity reads NEUROEDGE_ORCHESTRATOR_TLS with the optional
+// NEUROEDGE_ORCHESTRATOR_CA / _CERT / _KEY paths, NEUROEDGE_ORCHESTRATOR_TOKEN,
+// and NEUROEDGE_ORCHESTRATOR_INSECURE to permit plaintext gRPC.
+func loadOrchestratorSecurity() (*orchestratorSecurity, error) {
+	sec := &orchestratorSecurity{
+		token:         strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_TOKEN")),
+		allowInsecure: envTrue("NEUROEDGE_ORCHESTRATOR_INSECURE"),
+	}
+	if !envTrue("NEUROEDGE_ORCHESTRATOR_TLS") {
+		return sec, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caPath := strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_CA")); caPath != "" {
+		pem, err := os.ReadFile(caPath)
+		if err != nil {
+			return nil, fmt.Errorf("read orchestrator CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("orchestrator CA %s: no certificates found", caPath)
+		}
+		cfg.RootCAs = pool
+	}
+	certPath := strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_CERT"))
+	keyPath := strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_KEY"))
+	if certPath != "" || keyPath != "" {
+		if certPath == "" || keyPath == "" {
+			return nil, errors.New("NEUROEDGE_ORCHESTRATOR_CERT and NEUROEDGE_ORCHESTRATOR_KEY must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("load orchestrator client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	sec.tlsConfig = cfg
+	return sec, nil
+}
+
+// dialOptions returns the gRPC transport and per-RPC credentials. Plaintext
+// is refused unless NEUROEDGE_ORCHESTRATOR_INSECURE is set.
+func (s *orchestratorSecurity) dialOptions() ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	switch {
+	case s.tlsConfig != nil:
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(s.tlsConfig)))
+	case s.allowInsecure:
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	default:
+		return nil, errors.New("orchestrator gRPC needs NEUROEDGE_ORCHESTRATOR_TLS=true or NEUROEDGE_ORCHESTRATOR_INSECURE=true")
+	}
+	if s.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{
+			token:  s.token,
+			secure: s.tlsConfig != nil,
+		}))
+	}
+	return opts, nil
+}
+
+// bearerToken attaches the orchestrator token to every RPC.
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+var _ credentials.PerRPCCredentials = bearerToken{}
+
+func (b bearerToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.token}, nil
+}
+
+// RequireTransportSecurity is false only on connections explicitly allowed
+// to run without TLS.
+func (b bearerToken) RequireTransportSecurity() bool {
+	return b.secure
+}
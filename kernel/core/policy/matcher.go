@@ -0,0 +1,80 @@
+// kernel/core/policy/matcher.go
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"neuroedge/kernel/core/textnorm"
+)
+
+// Pattern is a deny pattern with its severity weight.
+type Pattern struct {
+	Pattern string  `json:"pattern"`
+	Weight  float64 `json:"weight"`
+}
+
+// Matcher checks input against a deny list of substrings. Patterns and input
+// are both folded with textnorm.Fold, so case, lookalike glyphs and
+// zero-width characters don't affect matching.
+type Matcher struct {
+	patterns []Pattern
+}
+
+// Load replaces the deny list, giving every pattern weight 1. Patterns that
+// fold to nothing are skipped.
+func (m *Matcher) Load(patterns []string) {
+	weighted := make([]Pattern, 0, len(patterns))
+	for _, p := range patterns {
+		weighted = append(weighted, Pattern{Pattern: p, Weight: 1})
+	}
+	_ = m.LoadWeighted(weighted) // weight 1 is never rejected
+}
+
+// LoadWeighted replaces the deny list with patterns carrying their own
+// weights. Patterns that fold to nothing are skipped. A negative weight
+// would let one match cancel out others, so it is an error and the deny
+// list is left unchanged.
+func (m *Matcher) LoadWeighted(patterns []Pattern) error {
+	folded := make([]Pattern, 0, len(patterns))
+	for i, p := range patterns {
+		if p.Weight < 0 {
+			return fmt.Errorf("pattern %d (%q) has negative weight %g", i, p.Pattern, p.Weight)
+		}
+		if p.Pattern = textnorm.Fold(p.Pattern); p.Pattern != "" {
+			folded = append(folded, p)
+		}
+	}
+	m.patterns = folded
+	return nil
+}
+
+// Match reports whether input contains a deny pattern and returns the first
+// one found, in load order.
+func (m *Matcher) Match(input string) (bool, string) {
+	candidate := textnorm.Fold(input)
+	if candidate == "" {
+		return false, ""
+	}
+	for _, p := range m.patterns {
+		if strings.Contains(candidate, p.Pattern) {
+			return true, p.Pattern
+		}
+	}
+	return false, ""
+}
+
+// MatchAll returns every deny pattern input contains, in load order.
+func (m *Matcher) MatchAll(input string) []Pattern {
+	candidate := textnorm.Fold(input)
+	if candidate == "" {
+		return nil
+	}
+	var matched []Pattern
+	for _, p := range m.patterns {
+		if strings.Contains(candidate, p.Pattern) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
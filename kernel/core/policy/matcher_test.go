@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcherMatch(t *testing.T) {
+	var m Matcher
+	m.Load([]string{"Rm -RF", "drop table", "  ", "\u200b"})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "please rm -rf /", want: "rm -rf"},
+		{name: "case", input: "DROP TABLE users", want: "drop table"},
+		{name: "cyrillic confusables", input: "drор tаblе users", want: "drop table"},
+		{name: "greek confusables", input: "drοp tαble", want: "drop table"},
+		{name: "combining marks", input: "dro\u0301p ta\u0308ble", want: "drop table"},
+		{name: "precomposed accents", input: "dróp täble", want: "drop table"},
+		{name: "zero-width characters", input: "rm\u200b -\u200drf", want: "rm -rf"},
+		{name: "fullwidth", input: "ｒｍ －ｒｆ", want: "rm -rf"},
+		{name: "whitespace runs", input: "drop \t\n table", want: "drop table"},
+		{name: "no match", input: "select * from users"},
+		{name: "empty input", input: ""},
+		{name: "only invisible input", input: "\u200b\u200b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, got := m.Match(tt.input)
+			if ok != (tt.want != "") || got != tt.want {
+				t.Fatalf("Match(%q) = %v, %q, want %q", tt.input, ok, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherLoadSkipsEmptyPatterns(t *testing.T) {
+	var m Matcher
+	m.Load([]string{"", "  ", "\u200b\u200c", "\u0301", "ok"})
+	if want := []Pattern{{Pattern: "ok", Weight: 1}}; !reflect.DeepEqual(m.patterns, want) {
+		t.Fatalf("patterns = %+v, want %+v", m.patterns, want)
+	}
+
+	// Load replaces the previous list.
+	m.Load([]string{"other"})
+	if ok, _ := m.Match("ok"); ok {
+		t.Fatal("pattern from the previous Load still matches")
+	}
+}
+
+func TestMatcherMatchAll(t *testing.T) {
+	var m Matcher
+	m.LoadWeighted([]Pattern{
+		{Pattern: "DROP TABLE", Weight: 5},
+		{Pattern: "rm -rf", Weight: 3},
+		{Pattern: "sudo", Weight: 0.5},
+	})
+
+	got := m.MatchAll("ѕudo rm -rf / && drop\u200b table x")
+	want := []Pattern{{"drop table", 5}, {"rm -rf", 3}, {"sudo", 0.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MatchAll = %+v, want %+v in load order", got, want)
+	}
+	if got := m.MatchAll("harmless"); got != nil {
+		t.Fatalf("MatchAll(harmless) = %+v, want nil", got)
+	}
+	if got := m.MatchAll(""); got != nil {
+		t.Fatalf("MatchAll(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestMatcherLoadWeightedRejectsNegativeWeights(t *testing.T) {
+	var m Matcher
+	m.Load([]string{"rm -rf"})
+	err := m.LoadWeighted([]Pattern{{Pattern: "sudo", Weight: 1}, {Pattern: "please", Weight: -2}})
+	if err == nil || !strings.Contains(err.Error(), `pattern 1 ("please")`) {
+		t.Fatalf("err = %v, want it to name pattern 1", err)
+	}
+	if ok, got := m.Match("rm -rf /"); !ok || got != "rm -rf" {
+		t.Fatal("a rejected LoadWeighted changed the deny list")
+	}
+}
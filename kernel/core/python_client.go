@@ -2,6 +2,7 @@
 package core
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,50 +10,241 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	pb "neuroedge/kernel/ml/orchestrator/generated"
+	"neuroedge/kernel/tracing"
 )
 
-// PythonClient implements pb.OrchestratorClient
+// PythonClient implements pb.OrchestratorClient. It speaks gRPC when dialed
+// with a host:port address and HTTP for http:// or https:// addresses.
+//
+// A PythonClient is safe for concurrent use and pools its connections, so
+// create one per orchestrator and share it rather than building clients per
+// request.
 type PythonClient struct {
 	conn       *grpc.ClientConn
+	grpcClient pb.OrchestratorClient
+	grpcCancel pb.TaskCancelClient
 	httpClient *http.Client
 	address    string
+	token      string
+	mode       string
+	breaker    *circuitBreaker
 }
 
-// NewPythonClient connects to the Python orchestrator service
+// Client modes reported by PythonClient.Mode.
+const (
+	ModeGRPC     = "grpc"
+	ModeHTTP     = "http"
+	ModeFallback = "fallback"
+)
+
+const fallbackOrchestratorAddr = "http://localhost:8090"
+
+const defaultOrchestratorDialTimeout = 5 * time.Second
+
+// orchestratorDialTimeout reads NEUROEDGE_ORCHESTRATOR_DIAL_TIMEOUT_MS, how
+// long a gRPC dial may wait for the orchestrator (default 5s).
+func orchestratorDialTimeout() time.Duration {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_DIAL_TIMEOUT_MS"))); err == nil && n > 0 {
+		return time.Duration(n) * time.Millisecond
+	}
+	return defaultOrchestratorDialTimeout
+}
+
+// NewPythonClient connects to the Python orchestrator service. http(s)
+// addresses use HTTP; anything else is dialed over gRPC, falling back to the
+// local HTTP service when the orchestrator can't be reached. Invalid TLS or
+// transport settings are returned as errors rather than falling back.
 func NewPythonClient(address string) (*PythonClient, error) {
+	return NewPythonClientWithTransport(address, nil)
+}
+
+// NewPythonClientWithTransport is NewPythonClient with a caller-tuned
+// transport for the HTTP path. A nil t uses the default pooled transport.
+func NewPythonClientWithTransport(address string, t *http.Transport) (*PythonClient, error) {
+	sec, err := loadOrchestratorSecurity()
+	if err != nil {
+		return nil, err
+	}
+	pc, err := newPythonClient(address, sec, t, true)
+	var cfgErr *orchestratorConfigError
+	if errors.As(err, &cfgErr) {
+		// A security misconfiguration must not turn into plaintext HTTP.
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("⚠️ gRPC dial to %s failed, falling back to %s: %v", address, fallbackOrchestratorAddr, err)
+		return &PythonClient{
+			httpClient: newOrchestratorHTTPClient(sec, t),
+			address:    fallbackOrchestratorAddr,
+			token:      sec.token,
+			mode:       ModeFallback,
+			breaker:    newCircuitBreakerFromEnv(),
+		}, nil
+	}
+	return pc, nil
+}
+
+// NewPythonClientStrict is like NewPythonClient but returns the gRPC dial
+// error instead of falling back to HTTP.
+func NewPythonClientStrict(address string) (*PythonClient, error) {
+	sec, err := loadOrchestratorSecurity()
+	if err != nil {
+		return nil, err
+	}
+	pc, err := newPythonClient(address, sec, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("dial orchestrator %s: %w", address, err)
+	}
+	return pc, nil
+}
+
+// NewPythonClientFromConn builds a gRPC client over an already dialed
+// connection, for callers that need their own dial options (an in-process
+// listener, a custom resolver). Close closes conn.
+func NewPythonClientFromConn(conn *grpc.ClientConn) *PythonClient {
+	return &PythonClient{
+		conn:       conn,
+		grpcClient: pb.NewOrchestratorClient(conn),
+		grpcCancel: pb.NewTaskCancelClient(conn),
+		httpClient: newOrchestratorHTTPClient(&orchestratorSecurity{}, nil),
+		address:    conn.Target(),
+		mode:       ModeGRPC,
+		breaker:    newCircuitBreakerFromEnv(),
+	}
+}
+
+// newPythonClient builds a client for address. With block a gRPC address is
+// dialed up to the dial timeout and an unreachable orchestrator is an error;
+// without it the connection is made in the background and calls fail over
+// gRPC until it is up.
+func newPythonClient(address string, sec *orchestratorSecurity, t *http.Transport, block bool) (*PythonClient, error) {
 	pc := &PythonClient{
-		httpClient: &http.Client{Timeout: 12 * time.Second},
+		httpClient: newOrchestratorHTTPClient(sec, t),
 		address:    strings.TrimSpace(address),
+		token:      sec.token,
+		breaker:    newCircuitBreakerFromEnv(),
 	}
 	if strings.HasPrefix(pc.address, "http://") || strings.HasPrefix(pc.address, "https://") {
+		pc.mode = ModeHTTP
 		return pc, nil
 	}
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock())
-	if err == nil {
-		pc.conn = conn
-		return pc, nil
+	opts, err := sec.dialOptions()
+	if err != nil {
+		return nil, &orchestratorConfigError{err: err}
 	}
-	// Graceful fallback to local HTTP ML service path when gRPC endpoint is unavailable.
-	pc.address = "http://localhost:8090"
-	return &PythonClient{
-		conn:       conn,
-		httpClient: &http.Client{Timeout: 12 * time.Second},
-		address:    pc.address,
-	}, nil
+	var conn *grpc.ClientConn
+	if block {
+		// Bounded, so an unreachable orchestrator can't hang startup.
+		ctx, cancel := context.WithTimeout(context.Background(), orchestratorDialTimeout())
+		defer cancel()
+		conn, err = grpc.DialContext(ctx, pc.address, append(opts, grpc.WithBlock(), grpc.FailOnNonTempDialError(true))...)
+	} else {
+		conn, err = grpc.NewClient(pc.address, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pc.conn = conn
+	pc.grpcClient = pb.NewOrchestratorClient(conn)
+	pc.grpcCancel = pb.NewTaskCancelClient(conn)
+	pc.mode = ModeGRPC
+	return pc, nil
 }
 
-// SubmitTask implements pb.OrchestratorClient interface
-func (pc *PythonClient) SubmitTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
-	if req == nil {
-		return nil, errors.New("nil task request")
+// orchestratorConfigError marks a client that could not be built because
+// of its settings, as opposed to an orchestrator that could not be reached.
+type orchestratorConfigError struct {
+	err error
+}
+
+func (e *orchestratorConfigError) Error() string { return e.err.Error() }
+func (e *orchestratorConfigError) Unwrap() error { return e.err }
+
+// newOrchestratorTransport keeps a bounded pool of keep-alive connections to
+// the orchestrator and applies its TLS settings, if any, to https:// calls.
+func newOrchestratorTransport(sec *orchestratorSecurity) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 32
+	transport.MaxConnsPerHost = 64
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.DisableKeepAlives = false
+	if sec.tlsConfig != nil {
+		transport.TLSClientConfig = sec.tlsConfig
 	}
-	base := strings.TrimRight(pc.address, "/")
-	url := fmt.Sprintf("%s/infer", base)
+	return transport
+}
+
+func newOrchestratorHTTPClient(sec *orchestratorSecurity, t *http.Transport) *http.Client {
+	if t == nil {
+		t = newOrchestratorTransport(sec)
+	}
+	return &http.Client{Timeout: 12 * time.Second, Transport: t}
+}
+
+// setAuth sends the orchestrator token on HTTP requests, matching the
+// per-RPC credentials used over gRPC.
+func (pc *PythonClient) setAuth(req *http.Request) {
+	if pc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+pc.token)
+	}
+}
+
+// Mode reports how the client reaches the orchestrator: ModeGRPC, ModeHTTP,
+// or ModeFallback when a gRPC dial failed and the local HTTP service is used.
+func (pc *PythonClient) Mode() string {
+	return pc.mode
+}
+
+// BreakerState reports the orchestrator circuit breaker state: BreakerClosed,
+// BreakerOpen or BreakerHalfOpen.
+func (pc *PythonClient) BreakerState() string {
+	return pc.breaker.currentState()
+}
+
+// BreakerRetryAfter reports how long until the open breaker lets a probe
+// through, or zero when calls are currently allowed.
+func (pc *PythonClient) BreakerRetryAfter() time.Duration {
+	return pc.breaker.retryAfter()
+}
+
+// recordOutcome feeds a call result to the breaker. Calls the caller
+// cancelled say nothing about orchestrator health and are not counted.
+func (pc *PythonClient) recordOutcome(ctx context.Context, failed bool) {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		pc.breaker.abandon()
+	case failed:
+		pc.breaker.failure()
+	default:
+		pc.breaker.success()
+	}
+}
+
+// TaskChunk is one partial output from a streamed task. Err is set on the
+// final chunk when the stream ended abnormally.
+type TaskChunk struct {
+	TaskId string
+	Data   string
+	Err    error
+}
+
+func inferPayload(req *pb.TaskRequest, stream bool) []byte {
 	payload := map[string]interface{}{
 		"text": req.InputData,
 		"payload": map[string]interface{}{
@@ -60,51 +252,318 @@ func (pc *PythonClient) SubmitTask(ctx context.Context, req *pb.TaskRequest) (*p
 			"taskId": req.TaskId,
 		},
 	}
+	if stream {
+		payload["stream"] = true
+	}
 	body, _ := json.Marshal(payload)
-	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(body)))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpResp, err := pc.httpClient.Do(httpReq)
-	if err != nil {
+	return body
+}
+
+// SubmitTask implements pb.OrchestratorClient interface. When ctx ends
+// before the orchestrator answers, the call is abandoned and ctx.Err() is
+// returned on both transports, so callers can tell a client that went away
+// from a failed task.
+func (pc *PythonClient) SubmitTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
+	resp, err := pc.submitTask(ctx, req)
+	var unreachable *unreachableError
+	if errors.As(err, &unreachable) && pc.grpcClient == nil {
+		// HTTP callers have always seen an unreachable orchestrator as a
+		// failed task rather than an error.
 		return &pb.TaskResponse{
 			TaskId:     req.TaskId,
 			Status:     "failed",
-			OutputData: fmt.Sprintf(`{"error":"%s"}`, err.Error()),
+			OutputData: fmt.Sprintf(`{"error":"%s"}`, unreachable.err.Error()),
 		}, nil
 	}
+	return resp, err
+}
+
+// abandonedCall records on span that the caller gave up with err (the
+// context's error) and returns it.
+func abandonedCall(span trace.Span, err error) error {
+	span.SetStatus(codes.Error, "caller abandoned the call")
+	span.SetAttributes(attribute.String("neuroedge.abandoned", err.Error()))
+	return err
+}
+
+// unreachableError marks a call that never got an answer from the
+// orchestrator, as opposed to one the orchestrator rejected.
+type unreachableError struct {
+	err error
+}
+
+func (e *unreachableError) Error() string { return e.err.Error() }
+func (e *unreachableError) Unwrap() error { return e.err }
+
+func (pc *PythonClient) submitTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
+	if req == nil {
+		return nil, errors.New("nil task request")
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "orchestrator.SubmitTask", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("neuroedge.engine", req.EngineName),
+		attribute.String("neuroedge.task_id", req.TaskId),
+	)
+
+	// Already abandoned: don't spend a half-open probe on it.
+	if err := ctx.Err(); err != nil {
+		return nil, abandonedCall(span, err)
+	}
+	if !pc.breaker.allow() {
+		span.SetStatus(codes.Error, ErrCircuitOpen.Error())
+		return nil, ErrCircuitOpen
+	}
+
+	if pc.grpcClient != nil {
+		resp, err := pc.grpcClient.SubmitTask(ctx, req)
+		pc.recordOutcome(ctx, err != nil)
+		if err != nil && ctx.Err() != nil {
+			return nil, abandonedCall(span, ctx.Err())
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "orchestrator gRPC call failed")
+			if status.Code(err) == grpccodes.Unavailable {
+				return nil, &unreachableError{err: err}
+			}
+			return nil, err
+		}
+		// The Python servicer reports "error"; normalize to the HTTP path's "failed".
+		if resp.Status == "error" {
+			resp.Status = "failed"
+		}
+		if resp.Status == "failed" {
+			span.SetStatus(codes.Error, "task failed")
+		}
+		return resp, nil
+	}
+
+	base := strings.TrimRight(pc.address, "/")
+	url := fmt.Sprintf("%s/infer", base)
+	body := inferPayload(req, false)
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(body)))
+	httpReq.Header.Set("Content-Type", "application/json")
+	pc.setAuth(httpReq)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+	httpResp, err := pc.httpClient.Do(httpReq)
+	pc.recordOutcome(ctx, err != nil || httpResp.StatusCode >= 500)
+	if err != nil && ctx.Err() != nil {
+		return nil, abandonedCall(span, ctx.Err())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "orchestrator unreachable")
+		return nil, &unreachableError{err: err}
+	}
 	defer httpResp.Body.Close()
-	respBody, _ := io.ReadAll(httpResp.Body)
-	status := "success"
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil && ctx.Err() != nil {
+		// A cut-off body is not a result.
+		return nil, abandonedCall(span, ctx.Err())
+	}
+	taskStatus := "success"
 	if httpResp.StatusCode >= 400 {
-		status = "failed"
+		taskStatus = "failed"
+		span.SetStatus(codes.Error, httpResp.Status)
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", httpResp.StatusCode))
 	return &pb.TaskResponse{
 		TaskId:     req.TaskId,
-		Status:     status,
+		Status:     taskStatus,
 		OutputData: string(respBody),
 	}, nil
 }
 
-// SubmitTaskWithInput is a helper to call SubmitTask with raw input
-func (pc *PythonClient) SubmitTaskWithInput(engineName, taskID string, input interface{}) {
-	inputJSON, _ := json.Marshal(input)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// SubmitTaskStream requests a streamed inference and relays each line of the
+// response body as a chunk while it arrives. The channel is closed when the
+// upstream finishes; cancelling ctx aborts the upstream request.
+func (pc *PythonClient) SubmitTaskStream(ctx context.Context, req *pb.TaskRequest) (<-chan TaskChunk, error) {
+	if req == nil {
+		return nil, errors.New("nil task request")
+	}
+	if pc.grpcClient != nil {
+		// The gRPC service is unary; deliver its result as a single chunk.
+		resp, err := pc.SubmitTask(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		out := make(chan TaskChunk, 1)
+		out <- TaskChunk{TaskId: resp.TaskId, Data: resp.OutputData}
+		close(out)
+		return out, nil
+	}
+	if !pc.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+	base := strings.TrimRight(pc.address, "/")
+	url := fmt.Sprintf("%s/infer", base)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(inferPayload(req, true))))
+	if err != nil {
+		pc.breaker.abandon()
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	pc.setAuth(httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+
+	// The shared client's timeout covers the whole body, which would cut long
+	// streams short; rely on ctx for cancellation instead.
+	streamClient := &http.Client{Transport: pc.httpClient.Transport}
+	httpResp, err := streamClient.Do(httpReq)
+	pc.recordOutcome(ctx, err != nil || httpResp.StatusCode >= 500)
+	if err != nil {
+		return nil, &unreachableError{err: err}
+	}
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		respBody, _ := io.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("orchestrator returned %d: %s", httpResp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	out := make(chan TaskChunk)
+	go func() {
+		defer close(out)
+		defer httpResp.Body.Close()
+		scanner := bufio.NewScanner(httpResp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			line = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if line == "" {
+				continue
+			}
+			select {
+			case out <- TaskChunk{TaskId: req.TaskId, Data: line}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			select {
+			case out <- TaskChunk{TaskId: req.TaskId, Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out, nil
+}
 
-	req := &pb.TaskRequest{
+// SubmitTaskWithInput is a helper to call SubmitTask with raw input, which is
+// JSON-encoded into the request.
+func (pc *PythonClient) SubmitTaskWithInput(ctx context.Context, engineName, taskID string, input interface{}) (*pb.TaskResponse, error) {
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("encode task input: %w", err)
+	}
+	return pc.SubmitTask(ctx, &pb.TaskRequest{
 		EngineName: engineName,
 		TaskId:     taskID,
 		InputData:  string(inputJSON),
+	})
+}
+
+// SubmitTaskAsync is the fire-and-forget form of SubmitTaskWithInput: it runs
+// in the background with a 30s timeout and only logs the outcome.
+func (pc *PythonClient) SubmitTaskAsync(engineName, taskID string, input interface{}) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		resp, err := pc.SubmitTaskWithInput(ctx, engineName, taskID, input)
+		if err != nil {
+			log.Printf("⚠️ Failed to submit task: %v", err)
+			return
+		}
+		log.Printf("✅ Task %s completed with status %s", resp.TaskId, resp.Status)
+	}()
+}
+
+// ErrTaskNotCancellable is returned by CancelTask when the orchestrator
+// reports that it has already finished the task or does not know it.
+var ErrTaskNotCancellable = errors.New("task not cancellable")
+
+// CancelTask asks the orchestrator to stop taskID, over the CancelTask RPC or
+// POST /cancel on the HTTP path.
+func (pc *PythonClient) CancelTask(ctx context.Context, taskID string) error {
+	if pc.grpcCancel != nil {
+		resp, err := pc.grpcCancel.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: taskID})
+		switch status.Code(err) {
+		case grpccodes.OK:
+		case grpccodes.NotFound, grpccodes.FailedPrecondition:
+			return ErrTaskNotCancellable
+		default:
+			return err
+		}
+		if resp.Status != "cancelled" {
+			return ErrTaskNotCancellable
+		}
+		return nil
 	}
 
-	resp, err := pc.SubmitTask(ctx, req)
+	body, _ := json.Marshal(map[string]string{"taskId": taskID})
+	url := strings.TrimRight(pc.address, "/") + "/cancel"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(body)))
 	if err != nil {
-		log.Printf("⚠️ Failed to submit task: %v", err)
-		return
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	pc.setAuth(httpReq)
+	httpResp, err := pc.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	respBody, _ := io.ReadAll(httpResp.Body)
+	var result struct {
+		Status string `json:"status"`
+	}
+	_ = json.Unmarshal(respBody, &result)
+	// Only the orchestrator's own answer about the task is final. A bare 404
+	// more likely means it has no /cancel route than that it lost the task.
+	switch {
+	case httpResp.StatusCode == http.StatusConflict, result.Status == "completed", result.Status == "not_found":
+		return ErrTaskNotCancellable
+	case httpResp.StatusCode >= 400:
+		return fmt.Errorf("orchestrator cancel returned %d: %s", httpResp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// Ping checks the orchestrator is reachable: the gRPC health service over
+// gRPC, or GET /health over HTTP. A gRPC server without the health service
+// still answered, so Unimplemented counts as reachable.
+func (pc *PythonClient) Ping(ctx context.Context) error {
+	if pc.conn != nil {
+		resp, err := healthpb.NewHealthClient(pc.conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		if status.Code(err) == grpccodes.Unimplemented {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("orchestrator health: %s", resp.GetStatus())
+		}
+		return nil
 	}
 
-	var output interface{}
-	json.Unmarshal([]byte(resp.OutputData), &output)
-	fmt.Printf("✅ Task %s completed with status %s, output: %+v\n", resp.TaskId, resp.Status, output)
+	url := strings.TrimRight(pc.address, "/") + "/health"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	pc.setAuth(httpReq)
+	httpResp, err := pc.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	_, _ = io.Copy(io.Discard, httpResp.Body)
+	if httpResp.StatusCode >= 400 {
+		return fmt.Errorf("orchestrator health returned %d", httpResp.StatusCode)
+	}
+	return nil
 }
 
 // Close closes the gRPC connection
@@ -0,0 +1,85 @@
+// kernel/core/python_client_batch.go
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+const defaultBatchConcurrency = 8
+
+// batchConcurrency reads NEUROEDGE_ORCHESTRATOR_BATCH_CONCURRENCY, the number
+// of batch items in flight to the orchestrator at once.
+func batchConcurrency() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_ORCHESTRATOR_BATCH_CONCURRENCY"))); err == nil && n > 0 {
+		return n
+	}
+	return defaultBatchConcurrency
+}
+
+// SubmitBatch submits every input to engineName through a bounded worker
+// pool and returns the responses in input order. An item that fails gets a
+// "failed" response carrying the error instead of aborting the batch; the
+// returned error is only set when ctx ends before all items were attempted.
+func (pc *PythonClient) SubmitBatch(ctx context.Context, engineName string, inputs []interface{}) ([]*pb.TaskResponse, error) {
+	results := make([]*pb.TaskResponse, len(inputs))
+	prefix := fmt.Sprintf("batch-%d", time.Now().UnixNano())
+
+	workers := batchConcurrency()
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				taskID := fmt.Sprintf("%s-%d", prefix, i)
+				resp, err := pc.SubmitTaskWithInput(ctx, engineName, taskID, inputs[i])
+				if err != nil {
+					resp = failedBatchItem(taskID, err)
+				}
+				results[i] = resp
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range inputs {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, resp := range results {
+		if resp == nil {
+			results[i] = failedBatchItem(fmt.Sprintf("%s-%d", prefix, i), err)
+		}
+	}
+	return results, err
+}
+
+func failedBatchItem(taskID string, err error) *pb.TaskResponse {
+	out, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return &pb.TaskResponse{
+		TaskId:     taskID,
+		Status:     "failed",
+		OutputData: string(out),
+	}
+}
@@ -0,0 +1,226 @@
+// kernel/core/python_client_pool.go
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// PythonClientPool spreads tasks over several orchestrator replicas. Calls
+// start at the next backend in round-robin order and fail over to the
+// following ones when a backend is unreachable or its breaker is open, so a
+// dead replica is skipped until its breaker lets a probe through again.
+type PythonClientPool struct {
+	backends []*PythonClient
+	next     atomic.Uint64
+}
+
+// NewPythonClientPool builds one client per address without waiting for
+// any of them to connect, so a dead replica can't hold up startup. Each
+// backend is then probed with Ping, all in parallel and bounded by
+// NEUROEDGE_ORCHESTRATOR_DIAL_TIMEOUT_MS; one that doesn't answer starts
+// with its breaker open and is retried once the cooldown passes. Invalid
+// TLS or transport settings are still an error.
+func NewPythonClientPool(addresses []string) (*PythonClientPool, error) {
+	sec, err := loadOrchestratorSecurity()
+	if err != nil {
+		return nil, err
+	}
+	pool := &PythonClientPool{}
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		pc, err := newPythonClient(addr, sec, nil, false)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("orchestrator %s: %w", addr, err)
+		}
+		pool.backends = append(pool.backends, pc)
+	}
+	if len(pool.backends) == 0 {
+		return nil, errors.New("orchestrator pool needs at least one address")
+	}
+	pool.probe(orchestratorDialTimeout())
+	return pool, nil
+}
+
+// probe pings every backend and opens the breaker of each one that fails to
+// answer within timeout.
+func (p *PythonClientPool) probe(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, pc := range p.backends {
+		wg.Add(1)
+		go func(pc *PythonClient) {
+			defer wg.Done()
+			if err := pc.Ping(ctx); err != nil {
+				pc.breaker.trip()
+				log.Printf("⚠️ Orchestrator %s unreachable at startup, breaker open: %v", pc.address, err)
+			}
+		}(pc)
+	}
+	wg.Wait()
+}
+
+// order returns the backends starting at the round-robin cursor.
+func (p *PythonClientPool) order() []*PythonClient {
+	start := int(p.next.Add(1)-1) % len(p.backends)
+	out := make([]*PythonClient, 0, len(p.backends))
+	out = append(out, p.backends[start:]...)
+	return append(out, p.backends[:start]...)
+}
+
+// shouldFailOver reports whether err means the backend never handled the
+// call, so trying another replica is safe.
+func shouldFailOver(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var unreachable *unreachableError
+	return errors.Is(err, ErrCircuitOpen) || errors.As(err, &unreachable)
+}
+
+// SubmitTask implements pb.OrchestratorClient.
+func (p *PythonClientPool) SubmitTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
+	resp, _, err := p.SubmitTaskBackend(ctx, req)
+	return resp, err
+}
+
+// SubmitTaskBackend is SubmitTask that also reports the address of the
+// backend that served the request.
+func (p *PythonClientPool) SubmitTaskBackend(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, string, error) {
+	var lastErr error
+	for _, pc := range p.order() {
+		resp, err := pc.submitTask(ctx, req)
+		if err == nil {
+			return resp, pc.address, nil
+		}
+		if !shouldFailOver(ctx, err) {
+			return nil, pc.address, err
+		}
+		lastErr = fmt.Errorf("%s: %w", pc.address, err)
+	}
+	if errors.Is(lastErr, ErrCircuitOpen) {
+		return nil, "", ErrCircuitOpen
+	}
+	return nil, "", fmt.Errorf("all orchestrator backends failed, last: %w", lastErr)
+}
+
+// SubmitTaskStream streams from the first backend that accepts the request.
+func (p *PythonClientPool) SubmitTaskStream(ctx context.Context, req *pb.TaskRequest) (<-chan TaskChunk, error) {
+	var lastErr error
+	for _, pc := range p.order() {
+		chunks, err := pc.SubmitTaskStream(ctx, req)
+		if err == nil {
+			return chunks, nil
+		}
+		if !shouldFailOver(ctx, err) {
+			return nil, err
+		}
+		lastErr = fmt.Errorf("%s: %w", pc.address, err)
+	}
+	return nil, fmt.Errorf("all orchestrator backends failed, last: %w", lastErr)
+}
+
+// CancelTask asks every backend to cancel taskID, since any of them may be
+// running it. It succeeds if one did and returns ErrTaskNotCancellable if
+// all backends answered that they could not.
+func (p *PythonClientPool) CancelTask(ctx context.Context, taskID string) error {
+	var errs []error
+	for _, pc := range p.backends {
+		err := pc.CancelTask(ctx, taskID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrTaskNotCancellable) {
+			errs = append(errs, fmt.Errorf("%s: %w", pc.address, err))
+		}
+	}
+	if len(errs) == 0 {
+		return ErrTaskNotCancellable
+	}
+	return errors.Join(errs...)
+}
+
+// Ping succeeds when any backend answers.
+func (p *PythonClientPool) Ping(ctx context.Context) error {
+	var errs []error
+	for _, pc := range p.backends {
+		err := pc.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", pc.address, err))
+	}
+	return errors.Join(errs...)
+}
+
+// Mode reports "pool"; per-backend modes are in Backends.
+func (p *PythonClientPool) Mode() string {
+	return "pool"
+}
+
+// BreakerState reports the healthiest backend's breaker: closed if any
+// backend is closed, half-open if any is probing, otherwise open.
+func (p *PythonClientPool) BreakerState() string {
+	state := BreakerOpen
+	for _, pc := range p.backends {
+		switch pc.BreakerState() {
+		case BreakerClosed:
+			return BreakerClosed
+		case BreakerHalfOpen:
+			state = BreakerHalfOpen
+		}
+	}
+	return state
+}
+
+// BreakerRetryAfter is zero when any backend accepts calls, otherwise the
+// shortest wait until one of them lets a probe through.
+func (p *PythonClientPool) BreakerRetryAfter() time.Duration {
+	var wait time.Duration
+	for i, pc := range p.backends {
+		d := pc.BreakerRetryAfter()
+		if d == 0 {
+			return 0
+		}
+		if i == 0 || d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// BackendStatus describes one pool member for debugging endpoints.
+type BackendStatus struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Breaker string `json:"breaker"`
+}
+
+// Backends lists every pool member with its mode and breaker state.
+func (p *PythonClientPool) Backends() []BackendStatus {
+	out := make([]BackendStatus, 0, len(p.backends))
+	for _, pc := range p.backends {
+		out = append(out, BackendStatus{Address: pc.address, Mode: pc.Mode(), Breaker: pc.BreakerState()})
+	}
+	return out
+}
+
+// Close closes every backend connection.
+func (p *PythonClientPool) Close() {
+	for _, pc := range p.backends {
+		pc.Close()
+	}
+}
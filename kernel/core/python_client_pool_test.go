@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// deadAddress returns an http:// address nothing listens on.
+func deadAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return "http://" + addr
+}
+
+func TestNewPythonClientPoolSurvivesDeadReplica(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_DIAL_TIMEOUT_MS", "500")
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer live.Close()
+	dead := deadAddress(t)
+
+	start := time.Now()
+	pool, err := NewPythonClientPool([]string{dead, live.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("pool startup took %s with a dead replica", elapsed)
+	}
+
+	breakers := map[string]string{}
+	for _, b := range pool.Backends() {
+		breakers[b.Address] = b.Breaker
+	}
+	if breakers[dead] != BreakerOpen {
+		t.Errorf("dead replica breaker = %q, want %q", breakers[dead], BreakerOpen)
+	}
+	if breakers[live.URL] != BreakerClosed {
+		t.Errorf("live replica breaker = %q, want %q", breakers[live.URL], BreakerClosed)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, backend, err := pool.SubmitTaskBackend(context.Background(), &pb.TaskRequest{TaskId: "t", EngineName: "e"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if backend != live.URL {
+			t.Fatalf("served by %s, want the live replica", backend)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+func TestNewPythonClientReturnsConfigErrors(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_TLS", "")
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_INSECURE", "")
+
+	pc, err := NewPythonClient("orchestrator:50051")
+	if err == nil {
+		t.Fatalf("got a %s client, want an error for gRPC without TLS or the insecure opt-in", pc.Mode())
+	}
+	var cfgErr *orchestratorConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("err = %v, want an orchestratorConfigError", err)
+	}
+}
+
+func TestNewPythonClientHTTPAddressNeedsNoDial(t *testing.T) {
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_TLS", "")
+	t.Setenv("NEUROEDGE_ORCHESTRATOR_INSECURE", "")
+
+	pc, err := NewPythonClient("http://orchestrator:8090")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc.Mode() != ModeHTTP {
+		t.Fatalf("mode = %s, want %s", pc.Mode(), ModeHTTP)
+	}
+}
+
+func TestSubmitTaskCancelledContext(t *testing.T) {
+	started, upstreamCancelled := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a disconnect once the body is consumed.
+		io.ReadAll(r.Body)
+		close(started)
+		select {
+		case <-r.Context().Done():
+			close(upstreamCancelled)
+		case <-time.After(5 * time.Second):
+			w.Write([]byte(`{"status":"done"}`))
+		}
+	}))
+	defer srv.Close()
+
+	pc, err := NewPythonClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	begin := time.Now()
+	resp, err := pc.SubmitTask(ctx, &pb.TaskRequest{EngineName: "chat", TaskId: "t1", InputData: "hi"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SubmitTask = %+v, %v; want context.Canceled", resp, err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("SubmitTask took %s after cancel", elapsed)
+	}
+	select {
+	case <-upstreamCancelled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream request was not cancelled")
+	}
+
+	// An already-cancelled context returns before calling upstream.
+	if _, err := pc.SubmitTask(ctx, &pb.TaskRequest{TaskId: "t2"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("pre-cancelled SubmitTask err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCancelTaskHTTPStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error // nil, ErrTaskNotCancellable, or errOther
+	}{
+		{name: "cancelled", status: http.StatusOK, body: `{"status":"cancelled"}`},
+		{name: "completed", status: http.StatusOK, body: `{"status":"completed"}`, want: ErrTaskNotCancellable},
+		{name: "unknown task", status: http.StatusNotFound, body: `{"status":"not_found"}`, want: ErrTaskNotCancellable},
+		{name: "conflict", status: http.StatusConflict, want: ErrTaskNotCancellable},
+		{name: "no cancel route", status: http.StatusNotFound, body: "404 page not found", want: errOther},
+		{name: "server error", status: http.StatusInternalServerError, want: errOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/cancel" {
+					t.Errorf("path = %s, want /cancel", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			pc, err := NewPythonClient(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = pc.CancelTask(context.Background(), "t1")
+			switch {
+			case tt.want == nil && err != nil:
+				t.Fatalf("CancelTask = %v, want nil", err)
+			case tt.want == ErrTaskNotCancellable && !errors.Is(err, ErrTaskNotCancellable):
+				t.Fatalf("CancelTask = %v, want ErrTaskNotCancellable", err)
+			case tt.want == errOther && (err == nil || errors.Is(err, ErrTaskNotCancellable)):
+				t.Fatalf("CancelTask = %v, want a generic error", err)
+			}
+		})
+	}
+}
+
+var errOther = errors.New("any error but ErrTaskNotCancellable")
@@ -0,0 +1,113 @@
+// kernel/core/readiness.go
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/contracts"
+)
+
+// ReadinessTracker collects readiness predicates that /readyz consults. It is
+// ready only when every registered predicate returns nil. Unlike the
+// HealthManager it keeps no state: predicates run on each Check, so readiness
+// flips as soon as the last component reports ready.
+type ReadinessTracker struct {
+	mu     sync.Mutex
+	checks map[string]func() error
+}
+
+// NewReadinessTracker returns an empty tracker, which is ready.
+func NewReadinessTracker() *ReadinessTracker {
+	return &ReadinessTracker{checks: make(map[string]func() error)}
+}
+
+// GlobalReadiness is the tracker /readyz consults.
+var GlobalReadiness = NewReadinessTracker()
+
+// Register adds or replaces the named predicate.
+func (t *ReadinessTracker) Register(name string, ready func() error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checks[name] = ready
+}
+
+// RegisterComponent registers c.Ready under c.Name().
+func (t *ReadinessTracker) RegisterComponent(c contracts.ReadinessCheck) {
+	t.Register(c.Name(), c.Ready)
+}
+
+// Check runs every predicate concurrently and returns the reason each
+// not-ready component gave, keyed by name; an empty map means ready. A
+// predicate that hasn't answered within timeout, or panics, counts as not
+// ready, so Check never takes much longer than timeout.
+func (t *ReadinessTracker) Check(timeout time.Duration) map[string]string {
+	t.mu.Lock()
+	checks := make(map[string]func() error, len(t.checks))
+	for name, fn := range t.checks {
+		checks[name] = fn
+	}
+	t.mu.Unlock()
+
+	type result struct {
+		name string
+		err  error
+	}
+	// Buffered so predicates that outlive the timeout don't leak blocked.
+	results := make(chan result, len(checks))
+	for name, fn := range checks {
+		go func(name string, fn func() error) {
+			results <- result{name: name, err: runReadiness(fn)}
+		}(name, fn)
+	}
+
+	notReady := make(map[string]string)
+	pending := make(map[string]bool, len(checks))
+	for name := range checks {
+		pending[name] = true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.name)
+			if res.err != nil {
+				notReady[res.name] = res.err.Error()
+			}
+		case <-timer.C:
+			for name := range pending {
+				notReady[name] = fmt.Sprintf("no answer within %s", timeout)
+			}
+			return notReady
+		}
+	}
+	return notReady
+}
+
+// NotReadySummary formats Check's result as "a: reason; b: reason", sorted
+// by name.
+func NotReadySummary(notReady map[string]string) string {
+	names := make([]string, 0, len(notReady))
+	for name := range notReady {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + notReady[name]
+	}
+	return strings.Join(parts, "; ")
+}
+
+func runReadiness(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
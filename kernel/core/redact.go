@@ -0,0 +1,96 @@
+// kernel/core/redact.go
+package core
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// RedactedMask replaces sensitive values in logged payloads.
+const RedactedMask = "***"
+
+// DefaultRedactKeys are the payload keys treated as sensitive unless
+// NEUROEDGE_REDACT_KEYS says otherwise.
+var DefaultRedactKeys = []string{"password", "token", "secret", "api_key", "apikey", "authorization"}
+
+// Redactor replaces the values of sensitive keys, matched case-insensitively
+// at any depth of a decoded JSON payload, including inside arrays.
+type Redactor struct {
+	keys    map[string]bool
+	replace func(v interface{}) interface{}
+}
+
+// NewRedactor redacts keys, replacing each value with replace(value). A nil
+// replace uses RedactedMask.
+func NewRedactor(keys []string, replace func(v interface{}) interface{}) *Redactor {
+	if replace == nil {
+		replace = func(interface{}) interface{} { return RedactedMask }
+	}
+	r := &Redactor{keys: map[string]bool{}, replace: replace}
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			r.keys[k] = true
+		}
+	}
+	return r
+}
+
+// Redact returns a copy of payload with every sensitive value replaced; the
+// input is not modified.
+func (r *Redactor) Redact(payload map[string]interface{}) map[string]interface{} {
+	if payload == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		if r.keys[strings.ToLower(k)] {
+			out[k] = r.replace(v)
+			continue
+		}
+		out[k] = r.RedactValue(v)
+	}
+	return out
+}
+
+// RedactValue is Redact for any decoded JSON value.
+func (r *Redactor) RedactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return r.Redact(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = r.RedactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactKeysFromEnv reads a comma-separated key list from the first of
+// names that is set, even to empty (which turns redaction off), falling back
+// to DefaultRedactKeys.
+func redactKeysFromEnv(names ...string) []string {
+	for _, name := range names {
+		if raw, ok := os.LookupEnv(name); ok {
+			return strings.Split(raw, ",")
+		}
+	}
+	return DefaultRedactKeys
+}
+
+var (
+	logRedactorOnce sync.Once
+	logRedactor     *Redactor
+)
+
+// LogRedactor masks NEUROEDGE_REDACT_KEYS (default DefaultRedactKeys) with
+// RedactedMask, for payloads written to logs.
+func LogRedactor() *Redactor {
+	logRedactorOnce.Do(func() {
+		logRedactor = NewRedactor(redactKeysFromEnv("NEUROEDGE_REDACT_KEYS"), nil)
+	})
+	return logRedactor
+}
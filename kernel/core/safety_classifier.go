@@ -0,0 +1,46 @@
+// kernel/core/safety_classifier.go
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// OrchestratorClassifier implements cognition.Classifier with an
+// orchestrator engine, normally its safety model. The engine receives
+// {"task": "..."} and must answer {"verdict": "...", "score": 0.0}.
+type OrchestratorClassifier struct {
+	Client pb.OrchestratorClient
+	Engine string
+}
+
+// Classify submits task to the classifier engine.
+func (oc OrchestratorClassifier) Classify(ctx context.Context, task string) (string, float64, error) {
+	input, err := json.Marshal(map[string]string{"task": task})
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := oc.Client.SubmitTask(ctx, &pb.TaskRequest{
+		EngineName: oc.Engine,
+		TaskId:     fmt.Sprintf("classify-%d", time.Now().UnixNano()),
+		InputData:  string(input),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.Status == "failed" {
+		return "", 0, fmt.Errorf("classifier engine %s failed: %s", oc.Engine, resp.OutputData)
+	}
+	var out struct {
+		Verdict string  `json:"verdict"`
+		Score   float64 `json:"score"`
+	}
+	if err := json.Unmarshal([]byte(resp.OutputData), &out); err != nil {
+		return "", 0, fmt.Errorf("decode classifier output: %w", err)
+	}
+	return out.Verdict, out.Score, nil
+}
@@ -19,7 +19,7 @@ type AgentProvider interface {
 
 // EngineProvider exposes engines for evaluation
 type EngineProvider interface {
-	GetAllEngines() []EngineInterface
+	GetAllEngines() []Engine
 }
 
 // SelfLearningLoop manages continuous improvement for NeuroEdge
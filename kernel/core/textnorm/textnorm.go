@@ -0,0 +1,53 @@
+// kernel/core/textnorm/textnorm.go
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps lowercase Cyrillic and Greek letters that render like
+// ASCII letters to the letter they imitate.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'ё': 'e', 'һ': 'h',
+	'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'т': 't', 'у': 'y', 'ԝ': 'w',
+	'х': 'x', 'ү': 'y',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ζ': 'z',
+}
+
+// Fold reduces s to a canonical form for deny-list matching: compatibility
+// decomposition (NFKD, so fullwidth and ligature forms become ASCII), then
+// combining marks and invisible format characters such as zero-width spaces
+// are dropped, the result is lowercased, lookalike letters are mapped to
+// ASCII and whitespace runs collapse to one space. Use it on both the
+// patterns and the input; log the original string, not the folded one.
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range norm.NFKD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cf, r):
+			continue
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		r = unicode.ToLower(r)
+		if ascii, ok := confusables[r]; ok {
+			r = ascii
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
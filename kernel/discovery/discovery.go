@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"sort"
 	"sync"
 
 	"neuroedge/kernel/core"
@@ -8,50 +9,60 @@ import (
 )
 
 var (
-	engineSnapshot = map[string]bool{}
+	engineRegistry *core.EngineRegistry
 	mu             sync.RWMutex
 )
 
-// Called once from main.go after engines are registered
+// Called once from main.go after engines are registered; node listings then
+// report each engine's live running state.
 func RegisterEngineSnapshot(registry *core.EngineRegistry) {
 	mu.Lock()
 	defer mu.Unlock()
-	for name := range registry.Engines {
-		engineSnapshot[name] = true
-	}
+	engineRegistry = registry
 }
 
 func EngineRegistrySnapshot() map[string]bool {
 	mu.RLock()
-	defer mu.RUnlock()
-
-	out := make(map[string]bool)
-	for k, v := range engineSnapshot {
-		out[k] = v
+	registry := engineRegistry
+	mu.RUnlock()
+	if registry == nil {
+		return map[string]bool{}
 	}
-	return out
+	return registry.Running()
 }
 
 func GetNodes() []types.KernelNode {
 	nodes := []types.KernelNode{
-		{ID: "kernel-1", Role: "kernel", Name: "NeuroEdge Kernel"},
+		{ID: "kernel-1", Role: "kernel", Name: "NeuroEdge Kernel", Status: "active"},
 	}
 
 	for _, a := range core.GetAllAgents() {
 		nodes = append(nodes, types.KernelNode{
-			ID:   "agent-" + a.Name(),
-			Role: "agent",
-			Name: a.Name(),
+			ID:     "agent-" + a.Name(),
+			Role:   "agent",
+			Name:   a.Name(),
+			Status: "active",
 		})
 	}
 
-	for name := range EngineRegistrySnapshot() {
+	for name, running := range EngineRegistrySnapshot() {
+		status := "active"
+		if !running {
+			status = "inactive"
+		}
 		nodes = append(nodes, types.KernelNode{
-			ID:   "engine-" + name,
-			Role: "engine",
-			Name: name,
+			ID:     "engine-" + name,
+			Role:   "engine",
+			Name:   name,
+			Status: status,
 		})
 	}
 
-	return nodes
+	// Stable order keeps offset-based pagination consistent between calls.
+	rest := nodes[1:]
+	sort.Slice(rest, func(i, j int) bool {
+		return rest[i].ID < rest[j].ID
+	})
+	// Self-registered mesh nodes follow the in-process ones.
+	return append(nodes, remoteNodeSnapshot()...)
 }
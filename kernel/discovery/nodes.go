@@ -0,0 +1,303 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/types"
+)
+
+// ErrUnknownNode is returned by Heartbeat and RemoveNode for a node that
+// never registered.
+var ErrUnknownNode = errors.New("unknown node")
+
+const (
+	defaultHeartbeatTimeout = 30 * time.Second
+	defaultNodeRetention    = 24 * time.Hour
+)
+
+// nodeMu serializes discovery's read-modify-write sequences on the store
+// within this process. Replicas sharing a store get its own semantics,
+// typically last write wins.
+var nodeMu sync.Mutex
+
+// HeartbeatTimeout reads NEUROEDGE_NODE_HEARTBEAT_TIMEOUT (duration, default
+// 30s): how long a node may go without a heartbeat before it is inactive.
+func HeartbeatTimeout() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("NEUROEDGE_NODE_HEARTBEAT_TIMEOUT")); err == nil && d > 0 {
+		return d
+	}
+	return defaultHeartbeatTimeout
+}
+
+// NodeRetention reads NEUROEDGE_NODE_RETENTION (duration, default 24h): how
+// long a node may go without a heartbeat before it is deleted outright,
+// rather than kept as inactive. It is never shorter than HeartbeatTimeout.
+func NodeRetention() time.Duration {
+	retention := defaultNodeRetention
+	if d, err := time.ParseDuration(os.Getenv("NEUROEDGE_NODE_RETENTION")); err == nil && d > 0 {
+		retention = d
+	}
+	if timeout := HeartbeatTimeout(); retention < timeout {
+		retention = timeout
+	}
+	return retention
+}
+
+// Node change event types sent to watchers.
+const (
+	// NodeAdded: a node registered, or came back after being inactive.
+	NodeAdded = "added"
+	// NodeRemoved: a node missed its heartbeats and is now inactive.
+	NodeRemoved = "removed"
+	// NodeUpdated: a registered node changed its address or capabilities.
+	NodeUpdated = "updated"
+)
+
+// NodeEvent is one change notification with the node as it is afterwards.
+type NodeEvent struct {
+	Type string           `json:"type"`
+	Node types.KernelNode `json:"node"`
+}
+
+const watchBuffer = 16
+
+var (
+	watchMu  sync.Mutex
+	watchers = map[chan NodeEvent]struct{}{}
+)
+
+// WatchNodes returns a channel of node changes and a function that stops the
+// watch and closes the channel. A watcher that falls watchBuffer events
+// behind misses events rather than stalling registration.
+func WatchNodes() (<-chan NodeEvent, func()) {
+	ch := make(chan NodeEvent, watchBuffer)
+	watchMu.Lock()
+	watchers[ch] = struct{}{}
+	watchMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			watchMu.Lock()
+			delete(watchers, ch)
+			close(ch)
+			watchMu.Unlock()
+		})
+	}
+}
+
+func notifyWatchers(events ...NodeEvent) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	for _, evt := range events {
+		for ch := range watchers {
+			select {
+			case ch <- evt:
+			default:
+			}
+		}
+	}
+}
+
+// RegisterNode adds or replaces a remote node and marks it active.
+func RegisterNode(id, address string, capabilities []string) (types.KernelNode, error) {
+	now := time.Now().UTC()
+	node := types.KernelNode{
+		ID:           id,
+		Role:         "node",
+		Name:         id,
+		Status:       "active",
+		Address:      address,
+		Capabilities: append([]string(nil), capabilities...),
+		LastSeen:     &now,
+	}
+
+	nodeMu.Lock()
+	st := currentStore()
+	prev, existed, err := st.Get(id)
+	if err == nil {
+		err = st.Upsert(node)
+	}
+	nodeMu.Unlock()
+	if err != nil {
+		return types.KernelNode{}, fmt.Errorf("store node %s: %w", id, err)
+	}
+
+	eventType := NodeAdded
+	if existed && prev.Status == "active" {
+		eventType = NodeUpdated
+		if prev.Address == node.Address && equalStrings(prev.Capabilities, node.Capabilities) {
+			eventType = ""
+		}
+	}
+	if eventType != "" {
+		notifyWatchers(NodeEvent{Type: eventType, Node: node})
+	}
+	return node, nil
+}
+
+// RemoveNode deletes a remote node, e.g. when it deregisters on shutdown.
+// Watchers see it as removed unless it was already inactive.
+func RemoveNode(id string) error {
+	nodeMu.Lock()
+	st := currentStore()
+	node, existed, err := st.Get(id)
+	if err == nil && existed {
+		err = st.Remove(id)
+	}
+	nodeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("remove node %s: %w", id, err)
+	}
+	if !existed {
+		return ErrUnknownNode
+	}
+	if node.Status == "active" {
+		node.Status = "inactive"
+		notifyWatchers(NodeEvent{Type: NodeRemoved, Node: node})
+	}
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Heartbeat records that node id is alive, reactivating it if the reaper
+// had marked it inactive.
+func Heartbeat(id string) (types.KernelNode, error) {
+	nodeMu.Lock()
+	st := currentStore()
+	node, ok, err := st.Get(id)
+	if err != nil || !ok {
+		nodeMu.Unlock()
+		if err != nil {
+			return types.KernelNode{}, fmt.Errorf("load node %s: %w", id, err)
+		}
+		return types.KernelNode{}, ErrUnknownNode
+	}
+	now := time.Now().UTC()
+	node.LastSeen = &now
+	revived := node.Status != "active"
+	node.Status = "active"
+	err = st.Upsert(node)
+	nodeMu.Unlock()
+	if err != nil {
+		return types.KernelNode{}, fmt.Errorf("store node %s: %w", id, err)
+	}
+
+	if revived {
+		notifyWatchers(NodeEvent{Type: NodeAdded, Node: node})
+	}
+	return node, nil
+}
+
+// reapStaleNodes marks nodes inactive once their last heartbeat is older
+// than timeout, and deletes them once it is older than retention, so nodes
+// that never come back don't accumulate in the store.
+func reapStaleNodes(now time.Time, timeout, retention time.Duration) {
+	var events []NodeEvent
+	nodeMu.Lock()
+	st := currentStore()
+	nodes, err := st.List()
+	if err != nil {
+		fmt.Printf("⚠️ Node reaper could not list nodes: %v\n", err)
+	}
+	for _, node := range nodes {
+		if node.LastSeen == nil {
+			continue
+		}
+		idle := now.Sub(*node.LastSeen)
+		if idle > retention {
+			if err := st.Remove(node.ID); err != nil {
+				fmt.Printf("⚠️ Node reaper could not delete %s: %v\n", node.ID, err)
+				continue
+			}
+			fmt.Printf("🧹 Node reaper deleted %s, unseen for %s\n", node.ID, idle.Round(time.Second))
+			if node.Status == "active" {
+				node.Status = "inactive"
+				events = append(events, NodeEvent{Type: NodeRemoved, Node: node})
+			}
+			continue
+		}
+		if node.Status != "active" || idle <= timeout {
+			continue
+		}
+		node.Status = "inactive"
+		if err := st.Upsert(node); err != nil {
+			fmt.Printf("⚠️ Node reaper could not mark %s inactive: %v\n", node.ID, err)
+			continue
+		}
+		events = append(events, NodeEvent{Type: NodeRemoved, Node: node})
+	}
+	nodeMu.Unlock()
+	notifyWatchers(events...)
+}
+
+// StartHeartbeatReaper checks for stale nodes every half heartbeat timeout
+// until the returned stop function is called.
+func StartHeartbeatReaper() (stop func()) {
+	timeout, retention := HeartbeatTimeout(), NodeRetention()
+	ticker := time.NewTicker(timeout / 2)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				reapStaleNodes(now, timeout, retention)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// NodesWithCapability returns the active registered nodes advertising
+// capability, sorted by ID, looked up through the store's capability index.
+func NodesWithCapability(capability string) ([]types.KernelNode, error) {
+	st := currentStore()
+	index, err := st.Capabilities()
+	if err != nil {
+		return nil, fmt.Errorf("read node capabilities: %w", err)
+	}
+	nodes := make([]types.KernelNode, 0, len(index[capability]))
+	for _, id := range index[capability] {
+		node, ok, err := st.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("load node %s: %w", id, err)
+		}
+		// Removed or reaped since the index was read.
+		if ok && node.Status == "active" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// remoteNodeSnapshot returns registered nodes sorted by ID. A store that
+// can't be read is logged and yields none, so in-process listings still work.
+func remoteNodeSnapshot() []types.KernelNode {
+	nodes, err := currentStore().List()
+	if err != nil {
+		fmt.Printf("⚠️ Could not list registered nodes: %v\n", err)
+		return []types.KernelNode{}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+// useMemoryStore gives a test an empty node store.
+func useMemoryStore(t *testing.T) Store {
+	t.Helper()
+	prev := currentStore()
+	st := NewMemoryStore()
+	SetStore(st)
+	t.Cleanup(func() { SetStore(prev) })
+	return st
+}
+
+func TestReapStaleNodes(t *testing.T) {
+	st := useMemoryStore(t)
+	for _, id := range []string{"n1", "n2"} {
+		if _, err := RegisterNode(id, id+":7000", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, stopWatch := WatchNodes()
+	defer stopWatch()
+
+	const timeout, retention = time.Minute, time.Hour
+	start := time.Now()
+	status := func(id string) (string, bool) {
+		node, ok, err := st.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node.Status, ok
+	}
+
+	reapStaleNodes(start.Add(timeout/2), timeout, retention)
+	if s, _ := status("n1"); s != "active" {
+		t.Fatalf("n1 = %s inside the timeout, want active", s)
+	}
+
+	reapStaleNodes(start.Add(2*timeout), timeout, retention)
+	if s, ok := status("n1"); !ok || s != "inactive" {
+		t.Fatalf("n1 = %s (stored %v) past the timeout, want inactive and kept", s, ok)
+	}
+	for i := 0; i < 2; i++ {
+		if e := <-events; e.Type != NodeRemoved {
+			t.Fatalf("event = %+v, want %s", e, NodeRemoved)
+		}
+	}
+
+	// Backdate n1 past retention; n2, inactive but within it, is kept.
+	n1, _, _ := st.Get("n1")
+	old := start.Add(-retention - time.Second)
+	n1.LastSeen = &old
+	if err := st.Upsert(n1); err != nil {
+		t.Fatal(err)
+	}
+	reapStaleNodes(start.Add(2*timeout), timeout, retention)
+	if _, ok := status("n1"); ok {
+		t.Fatal("n1 still stored past the retention period")
+	}
+	if s, ok := status("n2"); !ok || s != "inactive" {
+		t.Fatalf("n2 = %s (stored %v) within retention, want inactive and kept", s, ok)
+	}
+	select {
+	case e := <-events:
+		if e.Node.ID == "n1" {
+			t.Fatalf("deleting an already inactive node sent %+v", e)
+		}
+	default:
+	}
+}
+
+func TestNodeRetention(t *testing.T) {
+	tests := []struct {
+		retention, timeout string
+		want               time.Duration
+	}{
+		{want: defaultNodeRetention},
+		{retention: "2h", want: 2 * time.Hour},
+		{retention: "garbage", want: defaultNodeRetention},
+		{retention: "-1h", want: defaultNodeRetention},
+		// Never shorter than the heartbeat timeout.
+		{retention: "10s", timeout: "1m", want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Setenv("NEUROEDGE_NODE_RETENTION", tt.retention)
+		t.Setenv("NEUROEDGE_NODE_HEARTBEAT_TIMEOUT", tt.timeout)
+		if got := NodeRetention(); got != tt.want {
+			t.Fatalf("NodeRetention(%q, timeout %q) = %s, want %s", tt.retention, tt.timeout, got, tt.want)
+		}
+	}
+}
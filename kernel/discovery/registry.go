@@ -1,6 +1,8 @@
 package discovery
 
 import (
+	"fmt"
+
 	"neuroedge/kernel/core"
 	"neuroedge/kernel/types"
 )
@@ -17,8 +19,15 @@ func GetCapabilities() types.KernelCapabilities {
 		engines = append(engines, name)
 	}
 
+	nodes, err := currentStore().Capabilities()
+	if err != nil {
+		fmt.Printf("⚠️ Could not read node capabilities: %v\n", err)
+		nodes = nil
+	}
+
 	return types.KernelCapabilities{
 		Agents:  agents,
 		Engines: engines,
+		Nodes:   nodes,
 	}
 }
@@ -0,0 +1,124 @@
+// kernel/discovery/store.go
+package discovery
+
+import (
+	"sort"
+	"sync"
+
+	"neuroedge/kernel/types"
+)
+
+// Store keeps the self-registered mesh nodes. The default keeps them in
+// process memory; a shared implementation (a file, Redis, ...) lets several
+// kernel replicas see the same nodes and keeps them across restarts.
+// Implementations must be safe for concurrent use and must not hand out
+// slices the caller could mutate.
+type Store interface {
+	// Upsert saves node, replacing any node with the same ID.
+	Upsert(node types.KernelNode) error
+	// Remove deletes the node with id; removing an unknown id is not an error.
+	Remove(id string) error
+	// Get returns the node with id and whether it exists.
+	Get(id string) (types.KernelNode, bool, error)
+	// List returns every stored node, in any order.
+	List() ([]types.KernelNode, error)
+	// Capabilities maps each capability advertised by an active node to
+	// the IDs of the active nodes advertising it.
+	Capabilities() (map[string][]string, error)
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore()
+)
+
+// SetStore replaces the node store, e.g. with a shared one at startup.
+// Nodes in the previous store are not copied over. nil restores a fresh
+// in-memory store.
+func SetStore(s Store) {
+	if s == nil {
+		s = NewMemoryStore()
+	}
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func currentStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// MemoryStore is the default Store: nodes live in this process only.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	nodes map[string]types.KernelNode
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nodes: map[string]types.KernelNode{}}
+}
+
+func (s *MemoryStore) Upsert(node types.KernelNode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[node.ID] = copyNode(node)
+	return nil
+}
+
+func (s *MemoryStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.nodes, id)
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (types.KernelNode, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node, ok := s.nodes[id]
+	if !ok {
+		return types.KernelNode{}, false, nil
+	}
+	return copyNode(node), true, nil
+}
+
+func (s *MemoryStore) List() ([]types.KernelNode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]types.KernelNode, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		out = append(out, copyNode(node))
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Capabilities() (map[string][]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := map[string][]string{}
+	for id, node := range s.nodes {
+		if node.Status != "active" {
+			continue
+		}
+		for _, capability := range node.Capabilities {
+			out[capability] = append(out[capability], id)
+		}
+	}
+	for _, ids := range out {
+		sort.Strings(ids)
+	}
+	return out, nil
+}
+
+// copyNode detaches node's slice and pointer fields from the original.
+func copyNode(node types.KernelNode) types.KernelNode {
+	node.Capabilities = append([]string(nil), node.Capabilities...)
+	if node.LastSeen != nil {
+		seen := *node.LastSeen
+		node.LastSeen = &seen
+	}
+	return node
+}
@@ -1,31 +1,155 @@
 package engines
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"neuroedge/kernel/types"
 )
 
+const (
+	defaultOptimizeTopic = "compute:optimize"
+	// smoothingAlpha weights the newest sample in each topic's moving average.
+	smoothingAlpha = 0.3
+	// recentCapacity bounds the in-memory ring of recommendations served by LastN.
+	recentCapacity = 256
+)
+
+// smoothedMetrics holds the exponential moving averages for one topic.
+type smoothedMetrics struct {
+	values map[string]float64
+}
+
 type NeuroComputeOptimizer struct {
-	EventBus *types.EventBus
+	EventBus    *types.EventBus
+	Strategy    Strategy
+	Topics      []string
+	MinReplicas int
+	MaxReplicas int
+
+	mu       sync.Mutex
+	averages map[string]*smoothedMetrics
+	subs     []types.Subscription
+
+	logMu  sync.Mutex
+	logW   io.Writer
+	recent []map[string]interface{}
+	// logFile is set when the optimizer opened logW itself; see Close.
+	logFile *os.File
+
+	// Liveness for CheckHealth: the check fails when no event has been
+	// processed for staleAfter while running and not idle. Zero staleAfter
+	// turns the check off.
+	liveMu     sync.Mutex
+	startedAt  time.Time
+	lastEvent  time.Time
+	staleAfter time.Duration
+	idle       bool
+}
+
+// NewNeuroComputeOptimizer subscribes to topics, or to compute:optimize when none are given.
+func NewNeuroComputeOptimizer(bus *types.EventBus, topics ...string) *NeuroComputeOptimizer {
+	return NewNeuroComputeOptimizerWithStrategy(bus, ConservativeStrategy{}, topics...)
 }
 
-func NewNeuroComputeOptimizer(bus *types.EventBus) *NeuroComputeOptimizer {
+// NewNeuroComputeOptimizerWithStrategy builds an optimizer that delegates decisions to strategy.
+func NewNeuroComputeOptimizerWithStrategy(bus *types.EventBus, strategy Strategy, topics ...string) *NeuroComputeOptimizer {
+	if strategy == nil {
+		strategy = ConservativeStrategy{}
+	}
+	if len(topics) == 0 {
+		topics = []string{defaultOptimizeTopic}
+	}
 	return &NeuroComputeOptimizer{
-		EventBus: bus,
+		EventBus:    bus,
+		Strategy:    strategy,
+		Topics:      topics,
+		MinReplicas: readOptimizerIntEnv("NEUROEDGE_OPTIMIZER_MIN_REPLICAS", 1),
+		MaxReplicas: readOptimizerIntEnv("NEUROEDGE_OPTIMIZER_MAX_REPLICAS", 50),
+		averages:    make(map[string]*smoothedMetrics),
+		staleAfter:  readOptimizerDurationEnv("NEUROEDGE_OPTIMIZER_STALE_AFTER"),
 	}
 }
 
+// NewNeuroComputeOptimizerWithLog builds an optimizer that appends every
+// recommendation to w as a JSON line.
+func NewNeuroComputeOptimizerWithLog(bus *types.EventBus, w io.Writer) *NeuroComputeOptimizer {
+	n := NewNeuroComputeOptimizer(bus)
+	n.logW = w
+	return n
+}
+
+// NewNeuroComputeOptimizerFromEnv builds the optimizer the engine registry
+// runs. With NEUROEDGE_OPTIMIZER_AUDIT_LOG set, recommendations are also
+// appended to that file, which is created if missing; if it can't be opened
+// they are kept in memory only.
+func NewNeuroComputeOptimizerFromEnv(bus *types.EventBus) *NeuroComputeOptimizer {
+	path := strings.TrimSpace(os.Getenv("NEUROEDGE_OPTIMIZER_AUDIT_LOG"))
+	if path == "" {
+		return NewNeuroComputeOptimizer(bus)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Printf("⚠️ Optimizer audit log %s unavailable, keeping recommendations in memory: %v\n", path, err)
+		return NewNeuroComputeOptimizer(bus)
+	}
+	n := NewNeuroComputeOptimizerWithLog(bus, f)
+	n.logFile = f
+	return n
+}
+
+// Close closes the audit log file opened by NewNeuroComputeOptimizerFromEnv;
+// later recommendations are kept in memory only. A writer passed to
+// NewNeuroComputeOptimizerWithLog belongs to the caller and is left open.
+func (n *NeuroComputeOptimizer) Close() error {
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+	if n.logFile == nil {
+		return nil
+	}
+	err := n.logFile.Close()
+	n.logW, n.logFile = nil, nil
+	return err
+}
+
 func (n *NeuroComputeOptimizer) Start() {
 	fmt.Println("🚀 NeuroComputeOptimizer started")
 
-	n.EventBus.Subscribe("compute:optimize", func(evt types.Event) {
-		fmt.Println("[NeuroComputeOptimizer] Optimization Event:", evt.Data)
-		n.OptimizeCompute(evt.Data)
-	})
+	n.liveMu.Lock()
+	n.startedAt = time.Now()
+	n.liveMu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, topic := range n.Topics {
+		topic := topic
+		n.subs = append(n.subs, n.EventBus.Subscribe(topic, func(evt types.Event) {
+			fmt.Println("[NeuroComputeOptimizer] Optimization Event:", topic, evt.Data)
+			n.optimizeTopic(topic, evt.Data)
+		}))
+	}
 }
 
+// Stop unsubscribes the handlers registered by Start, so a restart does not
+// leave duplicates behind.
 func (n *NeuroComputeOptimizer) Stop() {
+	n.mu.Lock()
+	for _, sub := range n.subs {
+		n.EventBus.Unsubscribe(sub)
+	}
+	n.subs = nil
+	n.mu.Unlock()
+
+	n.liveMu.Lock()
+	n.startedAt = time.Time{}
+	n.liveMu.Unlock()
 	fmt.Println("🛑 NeuroComputeOptimizer stopped")
 }
 
@@ -33,36 +157,91 @@ func (n *NeuroComputeOptimizer) Name() string {
 	return "NeuroComputeOptimizer"
 }
 
+// SetStaleAfter sets how long the optimizer may go without processing an
+// event before CheckHealth fails; zero turns the check off.
+func (n *NeuroComputeOptimizer) SetStaleAfter(d time.Duration) {
+	n.liveMu.Lock()
+	defer n.liveMu.Unlock()
+	n.staleAfter = d
+}
+
+// SetIdle marks the optimizer as intentionally idle, e.g. while no workload
+// publishes metrics, so CheckHealth doesn't fail for lack of events.
+func (n *NeuroComputeOptimizer) SetIdle(idle bool) {
+	n.liveMu.Lock()
+	defer n.liveMu.Unlock()
+	n.idle = idle
+}
+
+// LastEvent returns when the optimizer last finished processing an event;
+// zero if it never has.
+func (n *NeuroComputeOptimizer) LastEvent() time.Time {
+	n.liveMu.Lock()
+	defer n.liveMu.Unlock()
+	return n.lastEvent
+}
+
+// CheckHealth fails when the optimizer is running, not idle and has
+// processed no event within the staleness window, counting from Start if
+// none arrived yet. It implements contracts.HealthCheck together with Name.
+func (n *NeuroComputeOptimizer) CheckHealth() error {
+	n.liveMu.Lock()
+	defer n.liveMu.Unlock()
+	if n.staleAfter <= 0 || n.idle || n.startedAt.IsZero() {
+		return nil
+	}
+	since, what := n.lastEvent, "last event"
+	if since.Before(n.startedAt) {
+		since, what = n.startedAt, "started"
+	}
+	if quiet := time.Since(since); quiet > n.staleAfter {
+		return fmt.Errorf("no event processed for %s (%s %s)", quiet.Round(time.Millisecond), what, since.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Ready reports whether Start has subscribed the optimizer to every topic
+// and those subscriptions are still on the bus. It implements
+// contracts.ReadinessCheck together with Name.
+func (n *NeuroComputeOptimizer) Ready() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.subs) < len(n.Topics) {
+		return fmt.Errorf("subscribed to %d of %d topics", len(n.subs), len(n.Topics))
+	}
+	for i, sub := range n.subs {
+		if !n.EventBus.Subscribed(sub) {
+			return fmt.Errorf("subscription to %s is gone", n.Topics[i])
+		}
+	}
+	return nil
+}
+
 func (n *NeuroComputeOptimizer) OptimizeCompute(data interface{}) {
+	n.optimizeTopic(defaultOptimizeTopic, data)
+}
+
+func (n *NeuroComputeOptimizer) optimizeTopic(topic string, data interface{}) {
 	fmt.Println("[NeuroComputeOptimizer] Running compute optimization...")
-	recommendation := map[string]interface{}{
-		"action":          "none",
-		"priority":        "low",
-		"reason":          "insufficient metrics",
-		"scale_factor":    1.0,
-		"target_queue_ms": 200,
-	}
-	if metrics, ok := data.(map[string]interface{}); ok {
-		cpu, _ := metrics["cpu_load"].(float64)
-		queue, _ := metrics["queue_ms"].(float64)
-		mem, _ := metrics["memory_load"].(float64)
-		if cpu > 0.85 || queue > 800 {
-			recommendation["action"] = "scale_up"
-			recommendation["priority"] = "high"
-			recommendation["reason"] = "high cpu/queue pressure"
-			recommendation["scale_factor"] = 1.5
-		} else if cpu < 0.2 && mem < 0.4 && queue < 100 {
-			recommendation["action"] = "scale_down"
-			recommendation["priority"] = "medium"
-			recommendation["reason"] = "sustained under-utilization"
-			recommendation["scale_factor"] = 0.8
-		} else {
-			recommendation["action"] = "rebalance"
-			recommendation["priority"] = "medium"
-			recommendation["reason"] = "maintain throughput with balanced load"
-		}
+	metrics, _ := data.(map[string]interface{})
+	recommendation := n.Strategy.Recommend(n.smooth(topic, metrics))
+	recommendation["strategy"] = strategyName(n.Strategy)
+	recommendation["topic"] = topic
+	if region := topicRegion(topic); region != "" {
+		recommendation["region"] = region
+	}
+	// Only emit a target when the caller told us where we are; a guessed
+	// baseline would make the event look actionable when it isn't.
+	if current, ok := replicaCount(metrics["current_replicas"]); ok {
+		factor, _ := recommendation["scale_factor"].(float64)
+		recommendation["current_replicas"] = current
+		recommendation["target_replicas"] = n.clampReplicas(int(math.Round(float64(current) * factor)))
 	}
 	fmt.Println("[NeuroComputeOptimizer] Optimization complete:", recommendation)
+	n.record(metrics, recommendation)
+	n.liveMu.Lock()
+	n.lastEvent = time.Now()
+	n.liveMu.Unlock()
 	if n.EventBus != nil {
 		n.EventBus.Publish(types.Event{
 			Name:   "compute:optimized",
@@ -71,3 +250,141 @@ func (n *NeuroComputeOptimizer) OptimizeCompute(data interface{}) {
 		})
 	}
 }
+
+// record appends the decision to the audit log and the recent ring. Holding
+// logMu across the write keeps concurrent events from interleaving lines.
+func (n *NeuroComputeOptimizer) record(metrics, decision map[string]interface{}) {
+	entry := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+		"metrics":   metrics,
+		"decision":  decision,
+	}
+
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+
+	n.recent = append(n.recent, entry)
+	if len(n.recent) > recentCapacity {
+		n.recent = n.recent[len(n.recent)-recentCapacity:]
+	}
+	if n.logW == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("⚠️ [NeuroComputeOptimizer] audit encode failed: %v\n", err)
+		return
+	}
+	if _, err := n.logW.Write(append(line, '\n')); err != nil {
+		fmt.Printf("⚠️ [NeuroComputeOptimizer] audit write failed: %v\n", err)
+	}
+}
+
+// LastN returns up to count of the most recent recommendations, oldest first.
+func (n *NeuroComputeOptimizer) LastN(count int) []map[string]interface{} {
+	n.logMu.Lock()
+	defer n.logMu.Unlock()
+	if count <= 0 || count > len(n.recent) {
+		count = len(n.recent)
+	}
+	out := make([]map[string]interface{}, count)
+	copy(out, n.recent[len(n.recent)-count:])
+	return out
+}
+
+// smooth folds a sample into the topic's moving averages and returns a copy of
+// metrics with the load fields replaced by their smoothed values. Topics never
+// share state, so one region's spike cannot skew another's trend.
+func (n *NeuroComputeOptimizer) smooth(topic string, metrics map[string]interface{}) map[string]interface{} {
+	if metrics == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	state, ok := n.averages[topic]
+	if !ok {
+		state = &smoothedMetrics{values: make(map[string]float64)}
+		n.averages[topic] = state
+	}
+	out := make(map[string]interface{}, len(metrics))
+	for k, v := range metrics {
+		out[k] = v
+	}
+	for _, key := range []string{"cpu_load", "queue_ms", "memory_load"} {
+		sample, ok := metrics[key].(float64)
+		if !ok {
+			continue
+		}
+		prev, seen := state.values[key]
+		if !seen {
+			prev = sample
+		}
+		avg := smoothingAlpha*sample + (1-smoothingAlpha)*prev
+		state.values[key] = avg
+		out[key] = avg
+	}
+	return out
+}
+
+// topicRegion extracts the region suffix from topics like compute:optimize:us-east.
+func topicRegion(topic string) string {
+	if !strings.HasPrefix(topic, defaultOptimizeTopic+":") {
+		return ""
+	}
+	return strings.TrimPrefix(topic, defaultOptimizeTopic+":")
+}
+
+// clampReplicas bounds a replica target to the configured min/max.
+func (n *NeuroComputeOptimizer) clampReplicas(target int) int {
+	if n.MinReplicas > 0 && target < n.MinReplicas {
+		target = n.MinReplicas
+	}
+	if n.MaxReplicas > 0 && target > n.MaxReplicas {
+		target = n.MaxReplicas
+	}
+	return target
+}
+
+// replicaCount accepts the numeric shapes current_replicas arrives in
+// (JSON-decoded float64 or in-process int) and rejects anything else.
+func replicaCount(raw interface{}) (int, bool) {
+	switch v := raw.(type) {
+	case int:
+		return v, v >= 0
+	case int64:
+		return int(v), v >= 0
+	case float64:
+		return int(math.Round(v)), v >= 0
+	default:
+		return 0, false
+	}
+}
+
+func readOptimizerIntEnv(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// readOptimizerDurationEnv parses key as a Go duration or whole seconds;
+// unset or invalid yields zero.
+func readOptimizerDurationEnv(key string) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package engines
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"neuroedge/kernel/types"
+)
+
+func TestOptimizerAuditLogFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "optimizer.jsonl")
+	t.Setenv("NEUROEDGE_OPTIMIZER_AUDIT_LOG", path)
+
+	n := NewNeuroComputeOptimizerFromEnv(types.NewEventBus())
+	n.record(map[string]interface{}{"cpu": 0.9}, map[string]interface{}{"action": "scale_up"})
+	n.record(map[string]interface{}{"cpu": 0.1}, map[string]interface{}{"action": "scale_down"})
+	if err := n.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// After Close recommendations stay in memory only.
+	n.record(map[string]interface{}{"cpu": 0.5}, map[string]interface{}{"action": "hold"})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var actions []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry struct {
+			Timestamp string                 `json:"timestamp"`
+			Decision  map[string]interface{} `json:"decision"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if entry.Timestamp == "" {
+			t.Fatalf("line %q has no timestamp", scanner.Text())
+		}
+		actions = append(actions, entry.Decision["action"].(string))
+	}
+	if len(actions) != 2 || actions[0] != "scale_up" || actions[1] != "scale_down" {
+		t.Fatalf("audit log actions = %v, want [scale_up scale_down]", actions)
+	}
+	if got := len(n.LastN(10)); got != 3 {
+		t.Fatalf("LastN holds %d recommendations, want 3", got)
+	}
+}
+
+func TestOptimizerAuditLogUnavailable(t *testing.T) {
+	t.Setenv("NEUROEDGE_OPTIMIZER_AUDIT_LOG", filepath.Join(t.TempDir(), "missing", "optimizer.jsonl"))
+	n := NewNeuroComputeOptimizerFromEnv(types.NewEventBus())
+	n.record(map[string]interface{}{}, map[string]interface{}{"action": "hold"})
+	if got := len(n.LastN(10)); got != 1 {
+		t.Fatalf("LastN holds %d recommendations, want 1", got)
+	}
+	if err := n.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,99 @@
+package engines
+
+import "fmt"
+
+// Strategy turns a metrics sample into a scaling recommendation.
+type Strategy interface {
+	Recommend(metrics map[string]interface{}) map[string]interface{}
+}
+
+// ConservativeStrategy only scales on clear pressure or sustained idleness.
+type ConservativeStrategy struct{}
+
+func (ConservativeStrategy) Name() string {
+	return "conservative"
+}
+
+func (ConservativeStrategy) Recommend(metrics map[string]interface{}) map[string]interface{} {
+	recommendation := baselineRecommendation()
+	if metrics == nil {
+		return recommendation
+	}
+	cpu, _ := metrics["cpu_load"].(float64)
+	queue, _ := metrics["queue_ms"].(float64)
+	mem, _ := metrics["memory_load"].(float64)
+	if cpu > 0.85 || queue > 800 {
+		recommendation["action"] = "scale_up"
+		recommendation["priority"] = "high"
+		recommendation["reason"] = "high cpu/queue pressure"
+		recommendation["scale_factor"] = 1.5
+	} else if cpu < 0.2 && mem < 0.4 && queue < 100 {
+		recommendation["action"] = "scale_down"
+		recommendation["priority"] = "medium"
+		recommendation["reason"] = "sustained under-utilization"
+		recommendation["scale_factor"] = 0.8
+	} else {
+		recommendation["action"] = "rebalance"
+		recommendation["priority"] = "medium"
+		recommendation["reason"] = "maintain throughput with balanced load"
+	}
+	return recommendation
+}
+
+// AggressiveStrategy reacts earlier and in larger steps, trading cost for latency headroom.
+type AggressiveStrategy struct{}
+
+func (AggressiveStrategy) Name() string {
+	return "aggressive"
+}
+
+func (AggressiveStrategy) Recommend(metrics map[string]interface{}) map[string]interface{} {
+	recommendation := baselineRecommendation()
+	if metrics == nil {
+		return recommendation
+	}
+	cpu, _ := metrics["cpu_load"].(float64)
+	queue, _ := metrics["queue_ms"].(float64)
+	mem, _ := metrics["memory_load"].(float64)
+	recommendation["target_queue_ms"] = 100
+	switch {
+	case cpu > 0.9 || queue > 1000:
+		recommendation["action"] = "scale_up"
+		recommendation["priority"] = "critical"
+		recommendation["reason"] = "severe cpu/queue pressure"
+		recommendation["scale_factor"] = 2.0
+	case cpu > 0.7 || queue > 400 || mem > 0.8:
+		recommendation["action"] = "scale_up"
+		recommendation["priority"] = "high"
+		recommendation["reason"] = "rising cpu/queue/memory pressure"
+		recommendation["scale_factor"] = 1.5
+	case cpu < 0.3 && mem < 0.5 && queue < 150:
+		recommendation["action"] = "scale_down"
+		recommendation["priority"] = "medium"
+		recommendation["reason"] = "under-utilization"
+		recommendation["scale_factor"] = 0.6
+	default:
+		recommendation["action"] = "rebalance"
+		recommendation["priority"] = "medium"
+		recommendation["reason"] = "maintain throughput with balanced load"
+	}
+	return recommendation
+}
+
+func baselineRecommendation() map[string]interface{} {
+	return map[string]interface{}{
+		"action":          "none",
+		"priority":        "low",
+		"reason":          "insufficient metrics",
+		"scale_factor":    1.0,
+		"target_queue_ms": 200,
+	}
+}
+
+// strategyName reports a strategy's Name when it has one, falling back to its type.
+func strategyName(s Strategy) string {
+	if named, ok := s.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", s)
+}
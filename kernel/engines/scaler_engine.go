@@ -0,0 +1,190 @@
+// kernel/engines/scaler_engine.go
+package engines
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"neuroedge/kernel/types"
+)
+
+const (
+	optimizedTopic = "compute:optimized"
+	// defaultScalerCooldown applies when NEUROEDGE_SCALER_COOLDOWN is unset.
+	defaultScalerCooldown = 2 * time.Minute
+	// scalerRecentCapacity bounds the ring of actions served by LastN.
+	scalerRecentCapacity = 256
+)
+
+// Scaler changes the replica count of whatever the optimizer is sizing,
+// e.g. by calling the orchestrator's scaling API.
+type Scaler interface {
+	Scale(target int) error
+}
+
+// logScaler is the default Scaler: it only logs, so recommendations are
+// visible before a real hook is wired in.
+type logScaler struct{}
+
+func (logScaler) Scale(target int) error {
+	fmt.Printf("[ScalerEngine] Would scale to %d replicas (no scaler configured)\n", target)
+	return nil
+}
+
+// ScalingAction is one scaling call made by the ScalerEngine.
+type ScalingAction struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Action          string    `json:"action"`
+	Topic           string    `json:"topic,omitempty"`
+	Region          string    `json:"region,omitempty"`
+	CurrentReplicas int       `json:"current_replicas"`
+	TargetReplicas  int       `json:"target_replicas"`
+	Reason          string    `json:"reason,omitempty"`
+	// Error is set when the Scaler failed; failed calls don't start the
+	// cooldown, so the next recommendation retries.
+	Error string `json:"error,omitempty"`
+}
+
+// ScalerEngine acts on the optimizer's compute:optimized recommendations:
+// scale_up and scale_down events carrying a target_replicas become
+// Scaler.Scale calls. After a successful call, further recommendations are
+// ignored for the cooldown (NEUROEDGE_SCALER_COOLDOWN, default 2m) so the
+// replica count doesn't follow every sample. Events without a target, or
+// whose target equals the current count, are skipped.
+type ScalerEngine struct {
+	EventBus *types.EventBus
+
+	// applyMu serializes cooldown checks with the Scale calls they guard.
+	applyMu     sync.Mutex
+	scaler      Scaler
+	cooldown    time.Duration
+	lastApplied time.Time
+
+	mu     sync.Mutex
+	sub    types.Subscription
+	recent []ScalingAction
+}
+
+// NewScalerEngine builds an engine that scales through scaler, or only logs
+// when scaler is nil.
+func NewScalerEngine(bus *types.EventBus, scaler Scaler) *ScalerEngine {
+	if scaler == nil {
+		scaler = logScaler{}
+	}
+	cooldown := readOptimizerDurationEnv("NEUROEDGE_SCALER_COOLDOWN")
+	if cooldown == 0 {
+		cooldown = defaultScalerCooldown
+	}
+	return &ScalerEngine{EventBus: bus, scaler: scaler, cooldown: cooldown}
+}
+
+func (s *ScalerEngine) Name() string {
+	return "ScalerEngine"
+}
+
+func (s *ScalerEngine) Start() {
+	fmt.Println("🚀 ScalerEngine started")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sub != 0 {
+		return
+	}
+	s.sub = s.EventBus.Subscribe(optimizedTopic, func(evt types.Event) {
+		recommendation, ok := evt.Data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		s.apply(recommendation)
+	})
+}
+
+func (s *ScalerEngine) Stop() {
+	s.mu.Lock()
+	if s.sub != 0 {
+		s.EventBus.Unsubscribe(s.sub)
+		s.sub = 0
+	}
+	s.mu.Unlock()
+	fmt.Println("🛑 ScalerEngine stopped")
+}
+
+// SetScaler replaces the scaling hook; nil restores the logging default.
+func (s *ScalerEngine) SetScaler(scaler Scaler) {
+	if scaler == nil {
+		scaler = logScaler{}
+	}
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	s.scaler = scaler
+}
+
+// SetCooldown sets the minimum time between applied scaling actions.
+func (s *ScalerEngine) SetCooldown(d time.Duration) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	s.cooldown = d
+}
+
+// apply turns one recommendation into a Scale call when it is actionable
+// and the cooldown has passed.
+func (s *ScalerEngine) apply(recommendation map[string]interface{}) {
+	action, _ := recommendation["action"].(string)
+	if action != "scale_up" && action != "scale_down" {
+		return
+	}
+	target, ok := replicaCount(recommendation["target_replicas"])
+	if !ok {
+		return
+	}
+	current, _ := replicaCount(recommendation["current_replicas"])
+	if target == current {
+		return
+	}
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	if !s.lastApplied.IsZero() && time.Since(s.lastApplied) < s.cooldown {
+		fmt.Printf("[ScalerEngine] Skipping %s to %d: cooling down until %s\n", action, target, s.lastApplied.Add(s.cooldown).UTC().Format(time.RFC3339))
+		return
+	}
+
+	entry := ScalingAction{
+		Timestamp:       time.Now().UTC(),
+		Action:          action,
+		CurrentReplicas: current,
+		TargetReplicas:  target,
+	}
+	entry.Topic, _ = recommendation["topic"].(string)
+	entry.Region, _ = recommendation["region"].(string)
+	entry.Reason, _ = recommendation["reason"].(string)
+	if err := s.scaler.Scale(target); err != nil {
+		entry.Error = err.Error()
+		fmt.Printf("⚠️ [ScalerEngine] %s to %d failed: %v\n", action, target, err)
+	} else {
+		s.lastApplied = entry.Timestamp
+		fmt.Printf("[ScalerEngine] Applied %s: %d -> %d replicas\n", action, current, target)
+	}
+	s.record(entry)
+}
+
+func (s *ScalerEngine) record(entry ScalingAction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recent = append(s.recent, entry)
+	if len(s.recent) > scalerRecentCapacity {
+		s.recent = s.recent[len(s.recent)-scalerRecentCapacity:]
+	}
+}
+
+// LastN returns up to count of the most recent scaling actions, oldest first.
+func (s *ScalerEngine) LastN(count int) []ScalingAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if count <= 0 || count > len(s.recent) {
+		count = len(s.recent)
+	}
+	out := make([]ScalingAction, count)
+	copy(out, s.recent[len(s.recent)-count:])
+	return out
+}
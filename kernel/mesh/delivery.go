@@ -0,0 +1,105 @@
+// kernel/mesh/delivery.go
+package mesh
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxDeadLetters caps RoutingDelivery's dead-letter list.
+const maxDeadLetters = 1000
+
+// DeadLetter is a message RoutingDelivery could not deliver.
+type DeadLetter struct {
+	NodeID    string    `json:"node_id,omitempty"`
+	Message   string    `json:"message"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RoutingDelivery routes messages and hands successful routes to Messaging,
+// so they can be read from the target's inbox.
+type RoutingDelivery struct {
+	routing   *Routing
+	messaging *Messaging
+
+	mu          sync.Mutex
+	deadLetters []DeadLetter
+}
+
+// NewRoutingDelivery connects routing to messaging.
+func NewRoutingDelivery(routing *Routing, messaging *Messaging) *RoutingDelivery {
+	return &RoutingDelivery{routing: routing, messaging: messaging}
+}
+
+// Deliver routes message to node and stores it in node's inbox. A nil or
+// inactive node, or a message Messaging refuses (oversize, throttled), is
+// dead-lettered instead; the returned outcome says which.
+func (d *RoutingDelivery) Deliver(node *Node, message string) MessageOutcome {
+	if node == nil {
+		d.deadLetter("", message, "node is nil")
+		return MessageInvalid
+	}
+	if !d.routing.RouteMessage(node, message) {
+		d.deadLetter(node.ID, message, "node is inactive")
+		return MessageInvalid
+	}
+	return d.store(node, message)
+}
+
+// DeliverByCapability routes message with Routing.RouteByCapability and
+// stores it in the chosen node's inbox. With no routable node it
+// dead-letters the message and returns a nil node.
+func (d *RoutingDelivery) DeliverByCapability(nodes []*Node, capability, message string) (*Node, MessageOutcome) {
+	node := d.routing.RouteByCapability(nodes, capability, message)
+	if node == nil {
+		d.deadLetter("", message, fmt.Sprintf("no active node with capability %q", capability))
+		return nil, MessageInactive
+	}
+	return node, d.store(node, message)
+}
+
+// store puts an already routed message in node's inbox.
+func (d *RoutingDelivery) store(node *Node, message string) MessageOutcome {
+	// The inbox expects the wire form, encrypted if Messaging encrypts.
+	wire, err := d.messaging.Seal(message)
+	if err != nil {
+		d.deadLetter(node.ID, message, err.Error())
+		return MessageInvalid
+	}
+	outcome := d.messaging.ReceiveMessage(node, wire)
+	if !outcome.Stored() {
+		d.deadLetter(node.ID, message, fmt.Sprintf("inbox %s", outcome))
+	}
+	return outcome
+}
+
+func (d *RoutingDelivery) deadLetter(nodeID, message, reason string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deadLetters = append(d.deadLetters, DeadLetter{
+		NodeID:    nodeID,
+		Message:   message,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+	if len(d.deadLetters) > maxDeadLetters {
+		d.deadLetters = d.deadLetters[len(d.deadLetters)-maxDeadLetters:]
+	}
+	fmt.Printf("🪦 Dead-lettered message for Node[%s]: %s\n", nodeID, reason)
+}
+
+// DeadLetters returns up to limit of the most recent undelivered messages,
+// oldest first; limit <= 0 returns all of them.
+func (d *RoutingDelivery) DeadLetters(limit int) []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	items := d.deadLetters
+	if limit > 0 && limit < len(items) {
+		items = items[len(items)-limit:]
+	}
+	out := make([]DeadLetter, len(items))
+	copy(out, items)
+	return out
+}
@@ -53,7 +53,7 @@ func (d *DiscoveryService) GetActiveNodes() []*Node {
 
 	active := []*Node{}
 	for _, node := range d.nodes {
-		if node.IsActive {
+		if node.Active() {
 			active = append(active, node)
 		}
 	}
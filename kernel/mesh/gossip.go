@@ -0,0 +1,300 @@
+// kernel/mesh/gossip.go
+package mesh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gossipPrefix marks Messaging payloads that carry a gossip digest.
+const gossipPrefix = "gossip:"
+
+// ErrNotGossip is returned by HandleMessage for payloads that aren't gossip.
+var ErrNotGossip = errors.New("not a gossip message")
+
+// GossipNode is one node as described in a gossip digest.
+type GossipNode struct {
+	ID           string    `json:"id"`
+	Address      string    `json:"address,omitempty"`
+	LastSeen     time.Time `json:"last_seen"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+}
+
+type gossipDigest struct {
+	From  GossipNode   `json:"from"`
+	Nodes []GossipNode `json:"nodes"`
+	// Reply marks the answer to a push, which is not answered again.
+	Reply bool `json:"reply,omitempty"`
+}
+
+// GossipTransport carries a payload to peer, already sealed (signed and
+// encrypted as configured) by Messaging; in a deployment it writes to the network, in-process it calls the
+// peer's HandleMessage.
+type GossipTransport func(peer *Node, payload string) error
+
+// Gossip spreads node liveness without a central registry. Each round the
+// local node refreshes its own LastSeen and swaps its known-node list with
+// up to fanout random active peers over Messaging; both sides keep the
+// newest LastSeen for every node and drop nodes unseen for longer than ttl.
+type Gossip struct {
+	self      *Node
+	registry  *NodeRegistry
+	messaging *Messaging
+
+	mu        sync.Mutex
+	fanout    int
+	ttl       time.Duration
+	transport GossipTransport
+	rng       *rand.Rand
+}
+
+// NewGossip gossips on behalf of self, keeping what it learns in registry
+// and sending through messaging. NEUROEDGE_MESH_GOSSIP_FANOUT (default 1)
+// and NEUROEDGE_MESH_GOSSIP_TTL (Go duration or seconds, default 60s) set
+// the fanout and TTL.
+func NewGossip(self *Node, registry *NodeRegistry, messaging *Messaging) *Gossip {
+	g := &Gossip{
+		self:      self,
+		registry:  registry,
+		messaging: messaging,
+		fanout:    1,
+		ttl:       time.Minute,
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_GOSSIP_FANOUT"))); err == nil && n > 0 {
+		g.fanout = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_GOSSIP_TTL")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			g.ttl = d
+		} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+			g.ttl = time.Duration(secs) * time.Second
+		}
+	}
+	registry.Register(self)
+	return g
+}
+
+// SetFanout sets how many peers each round contacts; n <= 0 is ignored.
+func (g *Gossip) SetFanout(n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n > 0 {
+		g.fanout = n
+	}
+}
+
+// SetTTL sets how long a node may go unseen before it is pruned; d <= 0 is
+// ignored.
+func (g *Gossip) SetTTL(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if d > 0 {
+		g.ttl = d
+	}
+}
+
+// SetTransport sets how digests reach peers. Without one, digests are only
+// recorded in the Messaging outbox.
+func (g *Gossip) SetTransport(t GossipTransport) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.transport = t
+}
+
+// Join adds a seed peer to gossip with until the mesh fills in the rest.
+func (g *Gossip) Join(seed *Node) {
+	if seed == nil || seed.ID == g.self.ID {
+		return
+	}
+	g.registry.add(seed)
+}
+
+// Round runs one gossip round: refresh self, prune, then push the digest to
+// up to fanout random active peers.
+func (g *Gossip) Round() {
+	g.self.MarkSeen()
+	g.prune(time.Now())
+
+	g.mu.Lock()
+	fanout := g.fanout
+	peers := g.peersLocked()
+	g.mu.Unlock()
+
+	if len(peers) > fanout {
+		peers = peers[:fanout]
+	}
+	for _, peer := range peers {
+		g.send(peer, false)
+	}
+}
+
+// StartGossip runs Round every interval until the returned stop function is
+// called.
+func (g *Gossip) StartGossip(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				g.Round()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// HandleMessage processes a payload received from another node: it merges
+// the digest, records the message in Messaging and answers a push with the
+// local digest.
+func (g *Gossip) HandleMessage(from *Node, payload string) error {
+	if from == nil {
+		return fmt.Errorf("gossip message has no sender")
+	}
+	// Check the signature before trusting the digest; ReceiveMessage
+	// records the rejection.
+	body, err := g.messaging.Open(from.ID, payload)
+	if err != nil {
+		g.messaging.ReceiveMessage(from, payload)
+		return fmt.Errorf("gossip from Node[%s]: %w", from.ID, err)
+	}
+	if !strings.HasPrefix(body, gossipPrefix) {
+		return ErrNotGossip
+	}
+	var digest gossipDigest
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(body, gossipPrefix)), &digest); err != nil {
+		return fmt.Errorf("decode gossip digest: %w", err)
+	}
+	if digest.From.ID != from.ID {
+		return fmt.Errorf("gossip digest sender does not match node")
+	}
+
+	g.merge(append(digest.Nodes, digest.From), time.Now())
+	sender, ok := g.registry.Get(from.ID)
+	if !ok {
+		return nil // already past the TTL
+	}
+	g.messaging.ReceiveMessage(sender, payload)
+	if !digest.Reply {
+		g.send(sender, true)
+	}
+	return nil
+}
+
+// KnownNodes returns the local view of the mesh, self included.
+func (g *Gossip) KnownNodes() []GossipNode {
+	nodes := g.registry.Nodes()
+	out := make([]GossipNode, 0, len(nodes))
+	for _, node := range nodes {
+		out = append(out, describeNode(node))
+	}
+	return out
+}
+
+// peersLocked returns the active peers in random order. Caller must hold
+// g.mu, which guards g.rng.
+func (g *Gossip) peersLocked() []*Node {
+	var peers []*Node
+	for _, node := range g.registry.ActiveNodes() {
+		if node.ID != g.self.ID {
+			peers = append(peers, node)
+		}
+	}
+	g.rng.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	return peers
+}
+
+func (g *Gossip) send(peer *Node, reply bool) {
+	data, err := json.Marshal(gossipDigest{From: describeNode(g.self), Nodes: g.KnownNodes(), Reply: reply})
+	if err != nil {
+		fmt.Printf("❌ Gossip encode failed: %v\n", err)
+		return
+	}
+	payload := gossipPrefix + string(data)
+	if !g.messaging.SendMessage(peer, payload).Stored() {
+		return
+	}
+
+	g.mu.Lock()
+	transport := g.transport
+	g.mu.Unlock()
+	if transport == nil {
+		return
+	}
+	wire, err := g.messaging.Seal(payload)
+	if err != nil {
+		fmt.Printf("❌ Gossip to Node[%s] failed: %v\n", peer.ID, err)
+		return
+	}
+	if err := transport(peer, wire); err != nil {
+		fmt.Printf("⚠️ Gossip to Node[%s] failed: %v\n", peer.ID, err)
+	}
+}
+
+// merge keeps the newest LastSeen for each reported node and adds nodes it
+// didn't know that are still within the TTL. A LastSeen in the future, from
+// clock skew or a lying peer, counts as now, or the node would never age
+// past the TTL.
+func (g *Gossip) merge(reported []GossipNode, now time.Time) {
+	g.mu.Lock()
+	ttl := g.ttl
+	g.mu.Unlock()
+
+	for _, info := range reported {
+		if info.ID == "" || info.ID == g.self.ID || now.Sub(info.LastSeen) > ttl {
+			continue
+		}
+		if info.LastSeen.After(now) {
+			info.LastSeen = now
+		}
+		if node, ok := g.registry.Get(info.ID); ok {
+			node.observe(info.LastSeen, info.Address, info.Capabilities)
+			continue
+		}
+		node := NewNode(info.ID, info.Address)
+		node.LastSeen = info.LastSeen
+		node.Capabilities = append([]string(nil), info.Capabilities...)
+		g.registry.add(node)
+		fmt.Printf("🗣️ Gossip learned Node[%s]\n", info.ID)
+	}
+}
+
+// prune forgets nodes unseen for longer than the TTL.
+func (g *Gossip) prune(now time.Time) {
+	g.mu.Lock()
+	ttl := g.ttl
+	g.mu.Unlock()
+
+	for _, node := range g.registry.Nodes() {
+		if node.ID == g.self.ID {
+			continue
+		}
+		if info := describeNode(node); now.Sub(info.LastSeen) > ttl {
+			g.registry.Remove(node.ID)
+			fmt.Printf("🗣️ Gossip pruned Node[%s]\n", node.ID)
+		}
+	}
+}
+
+func describeNode(n *Node) GossipNode {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return GossipNode{
+		ID:           n.ID,
+		Address:      n.Address,
+		LastSeen:     n.LastSeen,
+		Capabilities: append([]string(nil), n.Capabilities...),
+	}
+}
@@ -0,0 +1,124 @@
+package mesh
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestMesh returns one Gossip per id, wired to each other in-process.
+func newTestMesh(t *testing.T, ids ...string) map[string]*Gossip {
+	t.Helper()
+	mesh := map[string]*Gossip{}
+	for _, id := range ids {
+		g := NewGossip(NewNode(id, id+":7000"), NewNodeRegistry(time.Minute), newTestMessaging(t))
+		g.SetFanout(len(ids))
+		mesh[id] = g
+	}
+	for id, g := range mesh {
+		from := id
+		g.SetTransport(func(peer *Node, payload string) error {
+			target, ok := mesh[peer.ID]
+			if !ok {
+				return fmt.Errorf("no such peer %s", peer.ID)
+			}
+			return target.HandleMessage(NewNode(from, from+":7000"), payload)
+		})
+	}
+	return mesh
+}
+
+func knownIDs(g *Gossip) []string {
+	var ids []string
+	for _, n := range g.KnownNodes() {
+		ids = append(ids, n.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestGossipConverges(t *testing.T) {
+	mesh := newTestMesh(t, "a", "b", "c", "d")
+	// Everyone only knows the seed "a" to begin with.
+	for _, id := range []string{"b", "c", "d"} {
+		mesh[id].Join(mesh["a"].self)
+	}
+
+	for round := 0; round < 3; round++ {
+		for _, id := range []string{"a", "b", "c", "d"} {
+			mesh[id].Round()
+		}
+	}
+	for id, g := range mesh {
+		if got := knownIDs(g); fmt.Sprint(got) != "[a b c d]" {
+			t.Fatalf("%s knows %v, want every node", id, got)
+		}
+	}
+}
+
+func TestGossipMergeKeepsNewestAndSkipsExpired(t *testing.T) {
+	g := newTestMesh(t, "self")["self"]
+	now := time.Now()
+	old, newer := now.Add(-30*time.Second), now.Add(-10*time.Second)
+
+	g.merge([]GossipNode{
+		{ID: "peer", Address: "p:1", LastSeen: newer},
+		{ID: "expired", LastSeen: now.Add(-2 * time.Minute)},
+		{ID: "self", LastSeen: now.Add(-time.Hour)},
+	}, now)
+	g.merge([]GossipNode{{ID: "peer", Address: "p:2", LastSeen: old}}, now)
+
+	peer, ok := g.registry.Get("peer")
+	if !ok {
+		t.Fatal("peer not learned")
+	}
+	if info := describeNode(peer); !info.LastSeen.Equal(newer) || info.Address != "p:1" {
+		t.Fatalf("peer = %+v, an older report overwrote a newer one", info)
+	}
+	if _, ok := g.registry.Get("expired"); ok {
+		t.Fatal("node already past the TTL was learned")
+	}
+	if self := describeNode(g.self); self.LastSeen.Before(now.Add(-time.Minute)) {
+		t.Fatal("a peer's report overwrote our own LastSeen")
+	}
+}
+
+func TestGossipClampsFutureLastSeen(t *testing.T) {
+	g := newTestMesh(t, "self")["self"]
+	now := time.Now()
+	g.merge([]GossipNode{{ID: "liar", LastSeen: now.Add(24 * time.Hour)}}, now)
+
+	liar, ok := g.registry.Get("liar")
+	if !ok {
+		t.Fatal("node not learned")
+	}
+	if seen := describeNode(liar).LastSeen; seen.After(now) {
+		t.Fatalf("LastSeen = %s, want clamped to now (%s)", seen, now)
+	}
+
+	// A known node can't be pushed into the future either.
+	g.merge([]GossipNode{{ID: "liar", LastSeen: now.Add(24 * time.Hour)}}, now.Add(time.Second))
+	if seen := describeNode(liar).LastSeen; seen.After(now.Add(time.Second)) {
+		t.Fatalf("LastSeen = %s after a second future report", seen)
+	}
+
+	g.prune(now.Add(2 * time.Minute))
+	if _, ok := g.registry.Get("liar"); ok {
+		t.Fatal("node with a future LastSeen was never pruned")
+	}
+}
+
+func TestGossipPrune(t *testing.T) {
+	g := newTestMesh(t, "self")["self"]
+	now := time.Now()
+	g.merge([]GossipNode{
+		{ID: "fresh", LastSeen: now.Add(-10 * time.Second)},
+		{ID: "stale", LastSeen: now.Add(-50 * time.Second)},
+	}, now)
+
+	g.prune(now.Add(30 * time.Second))
+	if got := knownIDs(g); fmt.Sprint(got) != "[fresh self]" {
+		t.Fatalf("after prune know %v, want [fresh self]", got)
+	}
+}
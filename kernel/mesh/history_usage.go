@@ -0,0 +1,21 @@
+// kernel/mesh/history_usage.go
+package mesh
+
+// HistoryUsage describes a capped history buffer. Once Length reaches
+// Capacity every new record pushes the oldest out, counted in Dropped.
+type HistoryUsage struct {
+	Length   int `json:"length"`
+	Capacity int `json:"capacity"`
+	// Saturation is Length over Capacity, from 0 to 1.
+	Saturation float64 `json:"saturation"`
+	// Dropped is the number of records trimmed since startup.
+	Dropped uint64 `json:"dropped"`
+}
+
+func newHistoryUsage(length, capacity int, dropped uint64) HistoryUsage {
+	u := HistoryUsage{Length: length, Capacity: capacity, Dropped: dropped}
+	if capacity > 0 {
+		u.Saturation = float64(length) / float64(capacity)
+	}
+	return u
+}
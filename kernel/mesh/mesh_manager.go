@@ -9,18 +9,29 @@ import (
 // MeshManager coordinates all mesh subsystems
 type MeshManager struct {
 	Discovery     *DiscoveryService
+	Registry      *NodeRegistry
 	Routing       *Routing
 	Messaging     *Messaging
+	Delivery      *RoutingDelivery
 	Nodes         map[string]*Node
 	EncryptionKey []byte
 }
 
 // NewMeshManager creates a mesh manager instance
 func NewMeshManager(encryptionKey []byte) *MeshManager {
+	registry := NewNodeRegistry(0)
+	routing, messaging := NewRouting(), NewMessaging()
+	routing.SetRegistry(registry)
+	messaging.SetRegistry(registry)
+	if err := messaging.SetEncryptionKey(encryptionKey); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	}
 	return &MeshManager{
 		Discovery:     NewDiscoveryService(),
-		Routing:       NewRouting(),
-		Messaging:     NewMessaging(),
+		Registry:      registry,
+		Routing:       routing,
+		Messaging:     messaging,
+		Delivery:      NewRoutingDelivery(routing, messaging),
 		Nodes:         make(map[string]*Node),
 		EncryptionKey: encryptionKey,
 	}
@@ -29,6 +40,7 @@ func NewMeshManager(encryptionKey []byte) *MeshManager {
 // AddNode registers a node
 func (m *MeshManager) AddNode(node *Node) {
 	m.Discovery.RegisterNode(node)
+	m.Registry.Register(node)
 	m.Nodes[node.ID] = node
 	fmt.Printf("🌐 Node added: %s\n", node.ID)
 }
@@ -47,12 +59,15 @@ func (m *MeshManager) SendMessage(nodeID string, message string) {
 	}
 	encoded := base64.StdEncoding.EncodeToString(cipherText)
 	m.Routing.RouteMessage(node, encoded)
-	m.Messaging.SendMessage(node, encoded)
+	// Messaging encrypts with the same key itself.
+	if outcome := m.Messaging.SendMessage(node, message); !outcome.Stored() {
+		fmt.Printf("⚠️ Message to %s dropped: %s\n", nodeID, outcome)
+	}
 }
 
 // BroadcastMessage sends a message to all active nodes
 func (m *MeshManager) BroadcastMessage(message string) {
-	for _, node := range m.Discovery.GetActiveNodes() {
+	for _, node := range m.Registry.ActiveNodes() {
 		m.SendMessage(node.ID, message)
 	}
 }
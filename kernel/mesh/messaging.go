@@ -1,16 +1,89 @@
 package mesh
 
 import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
+// MessageOutcome says what SendMessage or ReceiveMessage did with a message.
+type MessageOutcome string
+
+const (
+	MessageAccepted  MessageOutcome = "accepted"
+	MessageTruncated MessageOutcome = "truncated"
+	// MessageRejected is an oversize message under OversizeReject.
+	MessageRejected  MessageOutcome = "rejected"
+	MessageThrottled MessageOutcome = "throttled"
+	// MessageInvalid is a call with a nil node.
+	MessageInvalid MessageOutcome = "invalid"
+	// MessageInactive is a node the registry considers inactive.
+	MessageInactive MessageOutcome = "inactive"
+	// MessageUnauthenticated is an inbound message that is unsigned, signed
+	// by someone else or tampered with, while signing is on.
+	MessageUnauthenticated MessageOutcome = "unauthenticated"
+	// MessageUndecryptable is an inbound message that is not encrypted, or
+	// fails to decrypt, while encryption is on.
+	MessageUndecryptable MessageOutcome = "undecryptable"
+)
+
+// Stored reports whether the message, possibly truncated, reached the box.
+func (o MessageOutcome) Stored() bool {
+	return o == MessageAccepted || o == MessageTruncated
+}
+
+// OversizePolicy decides what happens to a message over MaxMessageBytes.
+type OversizePolicy int
+
+const (
+	// OversizeReject drops the message and counts it in Rejections.
+	OversizeReject OversizePolicy = iota
+	// OversizeTruncate keeps the start and appends TruncatedMarker.
+	OversizeTruncate
+)
+
+// TruncatedMarker ends a message cut down to MaxMessageBytes.
+const TruncatedMarker = "[truncated]"
+
+// encryptedPrefix marks an encrypted message body: base64 of the AES-GCM
+// nonce and ciphertext.
+const encryptedPrefix = "enc:"
+
+// signedPrefix marks a signed message on the wire. The rest is a
+// signedEnvelope in JSON.
+const signedPrefix = "signed:"
+
+// signedEnvelope is the wire form of a signed message. Sig is the hex
+// HMAC-SHA256 of From, a NUL byte and Body.
+type signedEnvelope struct {
+	From string `json:"from"`
+	Body string `json:"body"`
+	Sig  string `json:"sig"`
+}
+
 type MessageRecord struct {
-	Direction string
-	NodeID    string
-	Message   string
-	Timestamp time.Time
+	Direction string `json:"direction"`
+	NodeID    string `json:"node_id"`
+	// Message is the stored text, or the reason for a drop.
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	// Outcome is accepted or truncated for stored messages. Throttled drops
+	// are recorded once per run, not per message, and other rejections only
+	// count towards Rejections, so a flood can't evict real history.
+	Outcome MessageOutcome `json:"outcome,omitempty"`
 }
 
 // Messaging handles sending and receiving messages
@@ -19,61 +92,492 @@ type Messaging struct {
 	inbox   map[string][]string
 	outbox  map[string][]string
 	history []MessageRecord
+	// historyDropped counts records trimmed off the history cap.
+	historyDropped uint64
+	// rejections counts messages refused for their content, by outcome.
+	rejections map[MessageOutcome]uint64
+
+	// rate (messages/second) and burst configure the per-node limit;
+	// rate 0 disables it. Inbound and outbound are limited separately.
+	rate   float64
+	burst  int
+	limits map[string]*nodeBucket
+	lastGC time.Time
+
+	// maxMessageBytes caps one message; 0 means no cap.
+	maxMessageBytes int
+	oversize        OversizePolicy
+
+	// registry, when set, refuses messages for inactive nodes.
+	registry *NodeRegistry
+
+	// secret, when set, signs outbound messages as localID and requires a
+	// valid signature on inbound ones.
+	secret  []byte
+	localID string
+
+	// encKey, when set, encrypts message bodies with AES-GCM. Boxes and
+	// history hold only ciphertext; ReadInbox decrypts.
+	encKey []byte
+}
+
+// nodeBucket is one node's token bucket for one direction.
+type nodeBucket struct {
+	tokens    float64
+	lastSeen  time.Time
+	throttled bool
 }
 
-// NewMessaging creates a messaging instance
+// NewMessaging creates a messaging instance. NEUROEDGE_MESH_MSG_RATE
+// (messages per second per node, default off) and NEUROEDGE_MESH_MSG_BURST
+// (default twice the rate) set the initial rate limit;
+// NEUROEDGE_MESH_MAX_MSG_BYTES and NEUROEDGE_MESH_OVERSIZE ("reject", the
+// default, or "truncate") set the size limit. NEUROEDGE_MESH_SECRET turns on
+// signing as NEUROEDGE_MESH_NODE_ID (default the hostname), and
+// NEUROEDGE_MESH_KEY (16, 24 or 32 bytes) turns on body encryption.
 func NewMessaging() *Messaging {
-	return &Messaging{
+	m := &Messaging{
 		inbox:   make(map[string][]string),
 		outbox:  make(map[string][]string),
 		history: make([]MessageRecord, 0, 512),
+		limits:  make(map[string]*nodeBucket),
+
+		rejections: make(map[MessageOutcome]uint64),
+	}
+	if rate, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_MSG_RATE")), 64); err == nil && rate > 0 {
+		burst, _ := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_MSG_BURST")))
+		m.SetRateLimit(rate, burst)
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_MAX_MSG_BYTES"))); err == nil && n > 0 {
+		policy := OversizeReject
+		if strings.EqualFold(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_OVERSIZE")), "truncate") {
+			policy = OversizeTruncate
+		}
+		m.SetMaxMessageBytes(n, policy)
+	}
+	if secret := os.Getenv("NEUROEDGE_MESH_SECRET"); secret != "" {
+		localID := strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_NODE_ID"))
+		if localID == "" {
+			localID, _ = os.Hostname()
+		}
+		m.SetSigningKey(localID, []byte(secret))
+	}
+	if key := os.Getenv("NEUROEDGE_MESH_KEY"); key != "" {
+		if err := m.SetEncryptionKey([]byte(key)); err != nil {
+			fmt.Printf("⚠️ Mesh message encryption disabled: %v\n", err)
+		}
+	}
+	return m
+}
+
+// SetEncryptionKey encrypts message bodies with AES-GCM under key, which
+// must be 16, 24 or 32 bytes. An empty key turns encryption off. Signing,
+// when on, covers the ciphertext.
+func (m *Messaging) SetEncryptionKey(key []byte) error {
+	if len(key) > 0 {
+		if _, err := aes.NewCipher(key); err != nil {
+			return fmt.Errorf("mesh encryption key must be 16, 24 or 32 bytes, got %d", len(key))
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(key) == 0 {
+		m.encKey = nil
+		return nil
+	}
+	m.encKey = append([]byte(nil), key...)
+	return nil
+}
+
+// Seal returns message in the wire form SendMessage stores: encrypted and
+// then signed, as far as each is on.
+func (m *Messaging) Seal(message string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body, err := m.encryptLocked(message)
+	if err != nil {
+		return "", err
+	}
+	return m.signLocked(body), nil
+}
+
+// Open reverses Seal for a wire message from fromID: it verifies, then
+// decrypts.
+func (m *Messaging) Open(fromID, wire string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body, err := m.verifyLocked(fromID, wire)
+	if err != nil {
+		return "", err
+	}
+	return m.decryptLocked(body)
+}
+
+func (m *Messaging) encryptLocked(plain string) (string, error) {
+	if m.encKey == nil {
+		return plain, nil
+	}
+	cipherText, err := Encrypt([]byte(plain), m.encKey)
+	if err != nil {
+		return "", fmt.Errorf("encrypt: %w", err)
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+func (m *Messaging) decryptLocked(body string) (string, error) {
+	if m.encKey == nil {
+		return body, nil
+	}
+	if !strings.HasPrefix(body, encryptedPrefix) {
+		return "", errors.New("not encrypted")
+	}
+	cipherText, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode ciphertext: %w", err)
+	}
+	plain, err := Decrypt(cipherText, m.encKey)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+// SetSigningKey signs outbound messages as localID with an HMAC keyed by
+// secret, and rejects inbound messages that don't carry a valid signature
+// from the node they arrive from. An empty secret turns signing off.
+func (m *Messaging) SetSigningKey(localID string, secret []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(secret) == 0 {
+		m.secret, m.localID = nil, ""
+		return
+	}
+	m.secret = append([]byte(nil), secret...)
+	m.localID = localID
+}
+
+// Sign returns message in the wire form SendMessage stores: a signed
+// envelope when signing is on, message itself otherwise.
+func (m *Messaging) Sign(message string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.signLocked(message)
+}
+
+// Verify checks a wire message from fromID and returns its body. With
+// signing off it returns wire unchanged.
+func (m *Messaging) Verify(fromID, wire string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.verifyLocked(fromID, wire)
+}
+
+func (m *Messaging) signLocked(message string) string {
+	if m.secret == nil {
+		return message
+	}
+	data, _ := json.Marshal(signedEnvelope{From: m.localID, Body: message, Sig: m.macLocked(m.localID, message)})
+	return signedPrefix + string(data)
+}
+
+func (m *Messaging) verifyLocked(fromID, wire string) (string, error) {
+	if m.secret == nil {
+		return wire, nil
+	}
+	if !strings.HasPrefix(wire, signedPrefix) {
+		return "", errors.New("unsigned")
+	}
+	var env signedEnvelope
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(wire, signedPrefix)), &env); err != nil {
+		return "", errors.New("malformed envelope")
+	}
+	if env.From != fromID {
+		return "", fmt.Errorf("signed by %q", env.From)
+	}
+	if !hmac.Equal([]byte(env.Sig), []byte(m.macLocked(env.From, env.Body))) {
+		return "", errors.New("bad signature")
+	}
+	return env.Body, nil
+}
+
+func (m *Messaging) macLocked(from, body string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(from))
+	mac.Write([]byte{0})
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetRegistry makes SendMessage and ReceiveMessage refuse nodes registry
+// considers inactive; nil accepts any node again.
+func (m *Messaging) SetRegistry(registry *NodeRegistry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registry = registry
+}
+
+// SetMaxMessageBytes caps each message at n bytes and sets what happens to
+// longer ones. A message of exactly n bytes is accepted; n <= 0 removes the
+// cap.
+func (m *Messaging) SetMaxMessageBytes(n int, policy OversizePolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	m.maxMessageBytes, m.oversize = n, policy
+}
+
+// fitLocked applies the size limit to message. Caller must hold m.mu.
+func (m *Messaging) fitLocked(message string) (string, MessageOutcome) {
+	if m.maxMessageBytes == 0 || len(message) <= m.maxMessageBytes {
+		return message, MessageAccepted
+	}
+	if m.oversize == OversizeReject {
+		return "", MessageRejected
+	}
+	keep := m.maxMessageBytes - len(TruncatedMarker)
+	if keep <= 0 {
+		return TruncatedMarker[:m.maxMessageBytes], MessageTruncated
+	}
+	// Back up to a rune boundary so the kept prefix stays valid UTF-8.
+	for keep > 0 && !utf8.RuneStart(message[keep]) {
+		keep--
+	}
+	return message[:keep] + TruncatedMarker, MessageTruncated
+}
+
+// SetRateLimit limits each node to perSecond messages in each direction,
+// allowing bursts of up to burst (<= 0 means twice perSecond, at least 1).
+// perSecond <= 0 removes the limit.
+func (m *Messaging) SetRateLimit(perSecond float64, burst int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if perSecond <= 0 {
+		m.rate, m.burst = 0, 0
+		m.limits = make(map[string]*nodeBucket)
+		return
+	}
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(2*perSecond)))
 	}
+	m.rate, m.burst = perSecond, burst
 }
 
-func (m *Messaging) pushHistory(direction, nodeID, message string) {
+// allowLocked spends a token from nodeID's bucket for direction. When it
+// refuses, it records a throttle event once per run of drops. Caller must
+// hold m.mu.
+func (m *Messaging) allowLocked(direction, nodeID string, now time.Time) bool {
+	if m.rate <= 0 {
+		return true
+	}
+	m.gcLocked(now)
+
+	key := direction + ":" + nodeID
+	b, ok := m.limits[key]
+	if !ok {
+		b = &nodeBucket{tokens: float64(m.burst), lastSeen: now}
+		m.limits[key] = b
+	}
+	b.tokens = math.Min(float64(m.burst), b.tokens+now.Sub(b.lastSeen).Seconds()*m.rate)
+	b.lastSeen = now
+	if b.tokens < 1 {
+		if !b.throttled {
+			b.throttled = true
+			m.history = append(m.history, MessageRecord{
+				Direction: direction,
+				NodeID:    nodeID,
+				Message:   fmt.Sprintf("throttled: over %.2f msg/s", m.rate),
+				Timestamp: now,
+				Outcome:   MessageThrottled,
+			})
+			m.trimHistoryLocked()
+		}
+		return false
+	}
+	b.tokens--
+	b.throttled = false
+	return true
+}
+
+// gcLocked drops buckets idle long enough to have refilled, at most once a
+// minute. Caller must hold m.mu.
+func (m *Messaging) gcLocked(now time.Time) {
+	if now.Sub(m.lastGC) < time.Minute {
+		return
+	}
+	m.lastGC = now
+	idle := time.Duration(float64(m.burst)/m.rate*float64(time.Second)) + time.Minute
+	for key, b := range m.limits {
+		if now.Sub(b.lastSeen) > idle {
+			delete(m.limits, key)
+		}
+	}
+}
+
+func (m *Messaging) pushHistory(direction, nodeID, message string, outcome MessageOutcome) {
 	m.history = append(m.history, MessageRecord{
 		Direction: direction,
 		NodeID:    nodeID,
 		Message:   message,
 		Timestamp: time.Now(),
+		Outcome:   outcome,
 	})
-	if len(m.history) > 10000 {
-		m.history = m.history[len(m.history)-10000:]
+	m.trimHistoryLocked()
+}
+
+// maxMessageHistory caps Messaging history, including imported records.
+const maxMessageHistory = 10000
+
+func (m *Messaging) trimHistoryLocked() {
+	if len(m.history) > maxMessageHistory {
+		m.historyDropped += uint64(len(m.history) - maxMessageHistory)
+		m.history = m.history[len(m.history)-maxMessageHistory:]
 	}
 }
 
-// SendMessage sends a message to a node
-func (m *Messaging) SendMessage(node *Node, message string) {
+// Rejections returns how many messages have been refused as oversize,
+// unauthenticated or undecryptable since startup, by outcome.
+func (m *Messaging) Rejections() map[MessageOutcome]uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[MessageOutcome]uint64, len(m.rejections))
+	for outcome, n := range m.rejections {
+		out[outcome] = n
+	}
+	return out
+}
+
+// HistoryUsage reports how full the message history is.
+func (m *Messaging) HistoryUsage() HistoryUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return newHistoryUsage(len(m.history), maxMessageHistory, m.historyDropped)
+}
+
+// SendMessage sends a message to a node and reports whether it was stored,
+// truncated or dropped.
+func (m *Messaging) SendMessage(node *Node, message string) MessageOutcome {
 	if node == nil {
 		fmt.Printf("⚠️ SendMessage skipped: node is nil\n")
-		return
+		return MessageInvalid
 	}
-	m.mu.Lock()
-	m.outbox[node.ID] = append(m.outbox[node.ID], message)
-	m.pushHistory("outbound", node.ID, message)
-	m.mu.Unlock()
-	fmt.Printf("📨 Sent message to Node[%s]: %s\n", node.ID, message)
+	if !m.registryAllows(node) {
+		fmt.Printf("⚠️ SendMessage skipped: Node[%s] is inactive\n", node.ID)
+		return MessageInactive
+	}
+	outcome := m.store("outbound", m.outbox, node.ID, message)
+	if outcome.Stored() {
+		fmt.Printf("📨 Sent message to Node[%s]: %s\n", node.ID, m.logText(message))
+	}
+	return outcome
 }
 
-// ReceiveMessage registers a received message from a node.
-func (m *Messaging) ReceiveMessage(node *Node, message string) {
+// ReceiveMessage registers a received message from a node and reports
+// whether it was stored, truncated or dropped.
+func (m *Messaging) ReceiveMessage(node *Node, message string) MessageOutcome {
 	if node == nil {
 		fmt.Printf("⚠️ ReceiveMessage skipped: node is nil\n")
-		return
+		return MessageInvalid
+	}
+	if !m.registryAllows(node) {
+		fmt.Printf("⚠️ ReceiveMessage skipped: Node[%s] is inactive\n", node.ID)
+		return MessageInactive
+	}
+	outcome := m.store("inbound", m.inbox, node.ID, message)
+	if outcome.Stored() {
+		fmt.Printf("📥 Received message from Node[%s]: %s\n", node.ID, m.logText(message))
 	}
+	return outcome
+}
+
+// logText is message as it may appear in logs: hidden when bodies are
+// encrypted.
+func (m *Messaging) logText(message string) string {
 	m.mu.Lock()
-	m.inbox[node.ID] = append(m.inbox[node.ID], message)
-	m.pushHistory("inbound", node.ID, message)
+	defer m.mu.Unlock()
+	if m.encKey != nil {
+		return fmt.Sprintf("<encrypted, %d bytes>", len(message))
+	}
+	return message
+}
+
+// registryAllows reports whether the registry, if any, considers node active.
+func (m *Messaging) registryAllows(node *Node) bool {
+	m.mu.Lock()
+	registry := m.registry
 	m.mu.Unlock()
-	fmt.Printf("📥 Received message from Node[%s]: %s\n", node.ID, message)
+	return registry == nil || registry.IsActive(node)
 }
 
+// store applies the rate and size limits, then appends message to box.
+// The rate limit comes first, so every message from a node costs a token
+// whatever becomes of it and a flood of bad ones is throttled like any
+// other. Oversize, unauthenticated and undecryptable messages are counted
+// in Rejections, not recorded in history. With signing on, inbound messages
+// are verified and stored as their body, and outbound ones are stored
+// signed. With encryption on, bodies are stored encrypted. The size limit
+// applies to the plaintext either way.
+func (m *Messaging) store(direction string, box map[string][]string, nodeID, message string) MessageOutcome {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.allowLocked(direction, nodeID, time.Now()) {
+		return MessageThrottled
+	}
+	if direction == "inbound" {
+		body, err := m.verifyLocked(nodeID, message)
+		if err != nil {
+			fmt.Printf("⚠️ Rejected message from Node[%s]: %v\n", nodeID, err)
+			m.rejections[MessageUnauthenticated]++
+			return MessageUnauthenticated
+		}
+		plain, err := m.decryptLocked(body)
+		if err != nil {
+			fmt.Printf("⚠️ Rejected message from Node[%s]: %v\n", nodeID, err)
+			m.rejections[MessageUndecryptable]++
+			return MessageUndecryptable
+		}
+		message = plain
+	}
+	fitted, outcome := m.fitLocked(message)
+	if outcome == MessageRejected {
+		fmt.Printf("⚠️ Rejected %s message for Node[%s]: %d bytes over %d byte limit\n", direction, nodeID, len(message), m.maxMessageBytes)
+		m.rejections[MessageRejected]++
+		return MessageRejected
+	}
+	stored, err := m.encryptLocked(fitted)
+	if err != nil {
+		fmt.Printf("❌ Encryption failed for Node[%s]: %v\n", nodeID, err)
+		return MessageInvalid
+	}
+	if direction == "outbound" {
+		stored = m.signLocked(stored)
+	}
+	box[nodeID] = append(box[nodeID], stored)
+	m.pushHistory(direction, nodeID, stored, outcome)
+	return outcome
+}
+
+// undecryptableMarker stands in for an inbox entry ReadInbox can't decrypt,
+// e.g. after the key changed.
+const undecryptableMarker = "[undecryptable]"
+
+// ReadInbox returns the messages received from nodeID, decrypted when
+// encryption is on.
 func (m *Messaging) ReadInbox(nodeID string) []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	items := m.inbox[nodeID]
 	out := make([]string, len(items))
-	copy(out, items)
+	for i, item := range items {
+		plain, err := m.decryptLocked(item)
+		if err != nil {
+			fmt.Printf("⚠️ Inbox message from Node[%s] unreadable: %v\n", nodeID, err)
+			plain = undecryptableMarker
+		}
+		out[i] = plain
+	}
 	return out
 }
 
@@ -86,6 +590,28 @@ func (m *Messaging) ReadOutbox(nodeID string) []string {
 	return out
 }
 
+// InboxSizes returns how many messages are pending in each node's inbox.
+func (m *Messaging) InboxSizes() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return boxSizes(m.inbox)
+}
+
+// OutboxSizes returns how many messages are pending in each node's outbox.
+func (m *Messaging) OutboxSizes() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return boxSizes(m.outbox)
+}
+
+func boxSizes(box map[string][]string) map[string]int {
+	out := make(map[string]int, len(box))
+	for nodeID, items := range box {
+		out[nodeID] = len(items)
+	}
+	return out
+}
+
 func (m *Messaging) History(limit int) []MessageRecord {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -99,3 +625,24 @@ func (m *Messaging) History(limit int) []MessageRecord {
 	copy(out, m.history[start:])
 	return out
 }
+
+// ExportHistory writes the whole message history to w as a JSON array,
+// oldest first.
+func (m *Messaging) ExportHistory(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m.History(0))
+}
+
+// ImportHistory appends records from a JSON array written by ExportHistory.
+// Older records fall off if the result exceeds the history cap; inboxes and
+// outboxes are not touched.
+func (m *Messaging) ImportHistory(r io.Reader) error {
+	var records []MessageRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return fmt.Errorf("decode message history: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history = append(m.history, records...)
+	m.trimHistoryLocked()
+	return nil
+}
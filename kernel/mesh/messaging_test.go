@@ -0,0 +1,151 @@
+package mesh
+
+import (
+	"strings"
+	"testing"
+)
+
+// newTestMessaging returns a Messaging with no limits, signing or
+// encryption, whatever the environment says.
+func newTestMessaging(t *testing.T) *Messaging {
+	t.Helper()
+	for _, key := range []string{"NEUROEDGE_MESH_MSG_RATE", "NEUROEDGE_MESH_MAX_MSG_BYTES", "NEUROEDGE_MESH_SECRET", "NEUROEDGE_MESH_KEY"} {
+		t.Setenv(key, "")
+	}
+	return NewMessaging()
+}
+
+func TestForgedFloodIsThrottledAndKeptOutOfHistory(t *testing.T) {
+	m := newTestMessaging(t)
+	m.SetSigningKey("local", []byte("secret"))
+	m.SetRateLimit(1, 3)
+	sender := NewNode("peer", "addr")
+
+	// Seed some real history that the flood must not evict.
+	m.SendMessage(sender, "hello")
+
+	outcomes := map[MessageOutcome]int{}
+	for i := 0; i < 1000; i++ {
+		outcomes[m.ReceiveMessage(sender, "forged")]++
+	}
+
+	if outcomes[MessageUnauthenticated] != 3 || outcomes[MessageThrottled] != 997 {
+		t.Fatalf("outcomes = %v, want 3 unauthenticated (the burst) and the rest throttled", outcomes)
+	}
+	if got := m.Rejections()[MessageUnauthenticated]; got != 3 {
+		t.Fatalf("Rejections()[unauthenticated] = %d, want 3", got)
+	}
+
+	history := m.History(0)
+	if len(history) != 2 {
+		t.Fatalf("history has %d records, want the sent message and one throttle record: %+v", len(history), history)
+	}
+	if history[0].Direction != "outbound" || history[1].Outcome != MessageThrottled {
+		t.Fatalf("history = %+v", history)
+	}
+}
+
+func TestRejectionsAreCountedNotRecorded(t *testing.T) {
+	m := newTestMessaging(t)
+	m.SetMaxMessageBytes(4, OversizeReject)
+	node := NewNode("n1", "addr")
+
+	if got := m.SendMessage(node, strings.Repeat("x", 5)); got != MessageRejected {
+		t.Fatalf("oversize send = %s, want %s", got, MessageRejected)
+	}
+	if got := m.ReceiveMessage(node, strings.Repeat("x", 5)); got != MessageRejected {
+		t.Fatalf("oversize receive = %s, want %s", got, MessageRejected)
+	}
+	if got := m.Rejections()[MessageRejected]; got != 2 {
+		t.Fatalf("Rejections()[rejected] = %d, want 2", got)
+	}
+	if h := m.History(0); len(h) != 0 {
+		t.Fatalf("rejections reached the history: %+v", h)
+	}
+}
+
+func TestSignedMessagesRejectTamperingAndWrongSender(t *testing.T) {
+	secret := []byte("shared")
+	alice := newTestMessaging(t)
+	alice.SetSigningKey("alice", secret)
+	local := newTestMessaging(t)
+	local.SetSigningKey("local", secret)
+
+	wire := alice.Sign("deploy v2")
+	tampered := strings.Replace(wire, "deploy v2", "deploy v3", 1)
+	if tampered == wire {
+		t.Fatal("test setup: body not found in the wire form")
+	}
+
+	tests := []struct {
+		name string
+		from string
+		wire string
+		want MessageOutcome
+	}{
+		{name: "valid", from: "alice", wire: wire, want: MessageAccepted},
+		{name: "tampered body", from: "alice", wire: tampered, want: MessageUnauthenticated},
+		{name: "wrong sender", from: "mallory", wire: wire, want: MessageUnauthenticated},
+		{name: "unsigned", from: "alice", wire: "deploy v2", want: MessageUnauthenticated},
+		{name: "other secret", from: "alice", wire: func() string {
+			other := newTestMessaging(t)
+			other.SetSigningKey("alice", []byte("guessed"))
+			return other.Sign("deploy v2")
+		}(), want: MessageUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := local.ReceiveMessage(NewNode(tt.from, "addr"), tt.wire); got != tt.want {
+				t.Fatalf("ReceiveMessage = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if inbox := local.ReadInbox("alice"); len(inbox) != 1 || inbox[0] != "deploy v2" {
+		t.Fatalf("alice inbox = %q, want only the verified body", inbox)
+	}
+	if inbox := local.ReadInbox("mallory"); len(inbox) != 0 {
+		t.Fatalf("mallory inbox = %q, want empty", inbox)
+	}
+}
+
+func TestMaxMessageBytes(t *testing.T) {
+	const limit = 16
+	tests := []struct {
+		name    string
+		policy  OversizePolicy
+		message string
+		want    MessageOutcome
+		stored  string
+	}{
+		{name: "under limit", policy: OversizeReject, message: "short", want: MessageAccepted, stored: "short"},
+		{name: "exactly at limit", policy: OversizeReject, message: strings.Repeat("a", limit), want: MessageAccepted, stored: strings.Repeat("a", limit)},
+		{name: "one over, reject", policy: OversizeReject, message: strings.Repeat("a", limit+1), want: MessageRejected},
+		{name: "exactly at limit, truncate", policy: OversizeTruncate, message: strings.Repeat("a", limit), want: MessageAccepted, stored: strings.Repeat("a", limit)},
+		{name: "one over, truncate", policy: OversizeTruncate, message: strings.Repeat("a", limit+1), want: MessageTruncated, stored: "aaaaa" + TruncatedMarker},
+		// "é" is two bytes; the cut must not split it.
+		{name: "truncate on rune boundary", policy: OversizeTruncate, message: "aaaaé" + strings.Repeat("b", limit), want: MessageTruncated, stored: "aaaa" + TruncatedMarker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMessaging(t)
+			m.SetMaxMessageBytes(limit, tt.policy)
+			node := NewNode("n1", "addr")
+			if got := m.ReceiveMessage(node, tt.message); got != tt.want {
+				t.Fatalf("ReceiveMessage = %s, want %s", got, tt.want)
+			}
+			inbox := m.ReadInbox("n1")
+			if tt.stored == "" {
+				if len(inbox) != 0 {
+					t.Fatalf("inbox = %q, want nothing stored", inbox)
+				}
+				return
+			}
+			if len(inbox) != 1 || inbox[0] != tt.stored {
+				t.Fatalf("inbox = %q, want [%q]", inbox, tt.stored)
+			}
+			if len(inbox[0]) > limit {
+				t.Fatalf("stored %d bytes, over the %d byte limit", len(inbox[0]), limit)
+			}
+		})
+	}
+}
@@ -9,12 +9,13 @@ import (
 
 // Node represents a single device/node in the mesh
 type Node struct {
-	ID       string
-	Address  string
-	LastSeen time.Time
-	IsActive bool
-	Metadata map[string]string
-	mu       sync.Mutex
+	ID           string
+	Address      string
+	LastSeen     time.Time
+	IsActive     bool
+	Capabilities []string
+	Metadata     map[string]string
+	mu           sync.Mutex
 }
 
 // NewNode creates a new mesh node
@@ -28,12 +29,78 @@ func NewNode(id, addr string) *Node {
 	}
 }
 
+// MarkSeen records a heartbeat: LastSeen becomes now and the node is active.
+func (n *Node) MarkSeen() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.LastSeen = time.Now()
+	n.IsActive = true
+}
+
 // UpdateHeartbeat refreshes the last seen timestamp
 func (n *Node) UpdateHeartbeat() {
+	n.MarkSeen()
+}
+
+// Active reports IsActive under the node's lock.
+func (n *Node) Active() bool {
 	n.mu.Lock()
 	defer n.mu.Unlock()
-	n.LastSeen = time.Now()
+	return n.IsActive
+}
+
+// HasCapability reports whether the node advertises capability.
+func (n *Node) HasCapability(capability string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, c := range n.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// WithCapability returns the nodes in nodes that advertise capability.
+func WithCapability(nodes []*Node, capability string) []*Node {
+	var out []*Node
+	for _, node := range nodes {
+		if node != nil && node.HasCapability(capability) {
+			out = append(out, node)
+		}
+	}
+	return out
+}
+
+// observe applies a sighting reported by another node. It only moves
+// LastSeen forward and reports whether it did.
+func (n *Node) observe(seen time.Time, address string, capabilities []string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !seen.After(n.LastSeen) {
+		return false
+	}
+	n.LastSeen = seen
 	n.IsActive = true
+	if address != "" {
+		n.Address = address
+	}
+	if capabilities != nil {
+		n.Capabilities = append([]string(nil), capabilities...)
+	}
+	return true
+}
+
+// expireIfStale marks the node inactive if it hasn't been seen within
+// timeout of now, and reports whether that changed anything.
+func (n *Node) expireIfStale(now time.Time, timeout time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.IsActive || now.Sub(n.LastSeen) <= timeout {
+		return false
+	}
+	n.IsActive = false
+	return true
 }
 
 // MarkInactive sets node as inactive
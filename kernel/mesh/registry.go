@@ -0,0 +1,158 @@
+// kernel/mesh/registry.go
+package mesh
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultNodeTimeout matches DiscoveryService's heartbeat checker.
+const defaultNodeTimeout = 30 * time.Second
+
+// NodeRegistry tracks mesh nodes and marks them inactive once their last
+// heartbeat is older than the timeout. Routing and Messaging consult it,
+// when set, instead of trusting Node.IsActive alone.
+type NodeRegistry struct {
+	mu      sync.Mutex
+	nodes   map[string]*Node
+	timeout time.Duration
+}
+
+// NewNodeRegistry creates a registry with the given heartbeat timeout;
+// timeout <= 0 uses NEUROEDGE_MESH_NODE_TIMEOUT (Go duration or seconds)
+// or 30s.
+func NewNodeRegistry(timeout time.Duration) *NodeRegistry {
+	if timeout <= 0 {
+		timeout = defaultNodeTimeout
+		if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_NODE_TIMEOUT")); raw != "" {
+			if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+				timeout = d
+			} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+				timeout = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return &NodeRegistry{nodes: make(map[string]*Node), timeout: timeout}
+}
+
+// Register adds or replaces node, counting registration as a heartbeat.
+func (r *NodeRegistry) Register(node *Node) {
+	if node == nil {
+		return
+	}
+	node.MarkSeen()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[node.ID] = node
+}
+
+// add stores node without touching its LastSeen, for nodes learned second
+// hand.
+func (r *NodeRegistry) add(node *Node) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodes[node.ID] = node
+}
+
+// Nodes returns every registered node, active or not.
+func (r *NodeRegistry) Nodes() []*Node {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nodes := make([]*Node, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Remove forgets nodeID.
+func (r *NodeRegistry) Remove(nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.nodes, nodeID)
+}
+
+// Get returns the registered node with nodeID.
+func (r *NodeRegistry) Get(nodeID string) (*Node, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	node, ok := r.nodes[nodeID]
+	return node, ok
+}
+
+// MarkSeen records a heartbeat for nodeID, reactivating it if it had timed
+// out. It reports false for an unknown node.
+func (r *NodeRegistry) MarkSeen(nodeID string) bool {
+	node, ok := r.Get(nodeID)
+	if ok {
+		node.MarkSeen()
+	}
+	return ok
+}
+
+// IsActive reports whether node is registered and has been seen within the
+// timeout. A node found stale is marked inactive on the spot, so callers
+// never act on an expired node between sweeps.
+func (r *NodeRegistry) IsActive(node *Node) bool {
+	if node == nil {
+		return false
+	}
+	registered, ok := r.Get(node.ID)
+	if !ok || registered != node {
+		return false
+	}
+	if node.expireIfStale(time.Now(), r.timeout) {
+		fmt.Printf("[Registry] Node %s marked inactive\n", node.ID)
+	}
+	return node.Active()
+}
+
+// ActiveNodes returns the registered nodes that are currently active.
+func (r *NodeRegistry) ActiveNodes() []*Node {
+	nodes := r.Nodes()
+	active := nodes[:0]
+	for _, node := range nodes {
+		if r.IsActive(node) {
+			active = append(active, node)
+		}
+	}
+	return active
+}
+
+// Sweep marks every node not seen within the timeout of now inactive and
+// returns the IDs it changed.
+func (r *NodeRegistry) Sweep(now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []string
+	for id, node := range r.nodes {
+		if node.expireIfStale(now, r.timeout) {
+			expired = append(expired, id)
+			fmt.Printf("[Registry] Node %s marked inactive\n", id)
+		}
+	}
+	return expired
+}
+
+// Start sweeps every interval until the returned stop function is called.
+func (r *NodeRegistry) Start(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				r.Sweep(now)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,95 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+// backdate makes node look last seen ago.
+func backdate(node *Node, ago time.Duration) {
+	node.mu.Lock()
+	node.LastSeen = time.Now().Add(-ago)
+	node.mu.Unlock()
+}
+
+func TestRegistrySweepExpiresStaleNodes(t *testing.T) {
+	r := NewNodeRegistry(time.Minute)
+	fresh, stale := NewNode("fresh", "a"), NewNode("stale", "b")
+	r.Register(fresh)
+	r.Register(stale)
+	backdate(stale, 2*time.Minute)
+
+	expired := r.Sweep(time.Now())
+	if len(expired) != 1 || expired[0] != "stale" {
+		t.Fatalf("Sweep expired %v, want [stale]", expired)
+	}
+	if stale.Active() || !fresh.Active() {
+		t.Fatalf("after sweep: stale active=%v, fresh active=%v", stale.Active(), fresh.Active())
+	}
+	// A second sweep reports only changes.
+	if again := r.Sweep(time.Now()); len(again) != 0 {
+		t.Fatalf("second Sweep expired %v, want none", again)
+	}
+
+	// A heartbeat brings it back.
+	if !r.MarkSeen("stale") || !r.IsActive(stale) {
+		t.Fatal("MarkSeen did not reactivate the node")
+	}
+	if r.MarkSeen("unknown") {
+		t.Fatal("MarkSeen accepted an unknown node")
+	}
+}
+
+func TestRegistryIsActiveExpiresBetweenSweeps(t *testing.T) {
+	r := NewNodeRegistry(time.Minute)
+	node := NewNode("n1", "a")
+	r.Register(node)
+	if !r.IsActive(node) {
+		t.Fatal("freshly registered node is inactive")
+	}
+
+	// Just inside the timeout the node is still active.
+	backdate(node, time.Minute-time.Second)
+	if !r.IsActive(node) {
+		t.Fatal("node inside the timeout is inactive")
+	}
+	backdate(node, time.Minute+time.Second)
+	if r.IsActive(node) || node.Active() {
+		t.Fatal("node past the timeout is still active")
+	}
+	if got := r.ActiveNodes(); len(got) != 0 {
+		t.Fatalf("ActiveNodes = %v, want none", got)
+	}
+
+	// An unregistered node, or a different node with the same ID, is never active.
+	if r.IsActive(NewNode("other", "b")) || r.IsActive(NewNode("n1", "a")) {
+		t.Fatal("node not in the registry reported active")
+	}
+}
+
+func TestRoutingAndMessagingConsultRegistry(t *testing.T) {
+	r := NewNodeRegistry(time.Minute)
+	node := NewNode("n1", "a")
+	r.Register(node)
+
+	routing := NewRouting()
+	routing.SetRegistry(r)
+	messaging := newTestMessaging(t)
+	messaging.SetRegistry(r)
+
+	if !routing.RouteMessage(node, "hi") {
+		t.Fatal("route to an active node failed")
+	}
+	if got := messaging.SendMessage(node, "hi"); got != MessageAccepted {
+		t.Fatalf("send to an active node = %s", got)
+	}
+
+	// IsActive is still true on the node itself; only the registry knows it timed out.
+	backdate(node, 2*time.Minute)
+	if routing.RouteMessage(node, "hi") {
+		t.Fatal("routed to a timed-out node")
+	}
+	if got := messaging.SendMessage(node, "hi"); got != MessageInactive {
+		t.Fatalf("send to a timed-out node = %s, want %s", got, MessageInactive)
+	}
+}
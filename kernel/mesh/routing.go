@@ -1,39 +1,266 @@
 package mesh
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 type RouteRecord struct {
-	NodeID    string
-	Message   string
-	Timestamp time.Time
+	NodeID    string    `json:"node_id"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
+// maxRouteHistory caps Routing history, including imported records.
+const maxRouteHistory = 5000
+
 // Routing handles message delivery across nodes
 type Routing struct {
 	mu      sync.Mutex
 	history []RouteRecord
+	// historyDropped counts records trimmed off the history cap.
+	historyDropped uint64
+
+	// nodes holds RouteBalanced's per-node weight and health. A node leaves
+	// the rotation after failureThreshold consecutive failures and returns
+	// once cooldown has passed.
+	nodes            map[string]*routeState
+	failureThreshold int
+	cooldown         time.Duration
+
+	// registry, when set, decides whether a node is active.
+	registry *NodeRegistry
+}
+
+// routeState is one node's place in the weighted rotation.
+type routeState struct {
+	weight    int
+	current   int // smooth weighted round-robin counter
+	failures  int
+	downUntil time.Time
 }
 
-// NewRouting creates a routing instance
+// RotationEntry describes one node's place in the balanced rotation.
+type RotationEntry struct {
+	NodeID    string     `json:"node_id"`
+	Weight    int        `json:"weight"`
+	Failures  int        `json:"failures"`
+	Healthy   bool       `json:"healthy"`
+	DownUntil *time.Time `json:"down_until,omitempty"`
+}
+
+// NewRouting creates a routing instance. NEUROEDGE_MESH_ROUTE_FAILURES
+// (default 3) and NEUROEDGE_MESH_ROUTE_COOLDOWN (Go duration or seconds,
+// default 30s) set when RouteBalanced takes a node out of rotation.
 func NewRouting() *Routing {
-	return &Routing{
-		history: make([]RouteRecord, 0, 256),
+	r := &Routing{
+		history:          make([]RouteRecord, 0, 256),
+		nodes:            make(map[string]*routeState),
+		failureThreshold: 3,
+		cooldown:         30 * time.Second,
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_ROUTE_FAILURES"))); err == nil && n > 0 {
+		r.failureThreshold = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("NEUROEDGE_MESH_ROUTE_COOLDOWN")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			r.cooldown = d
+		} else if secs, err := strconv.Atoi(raw); err == nil && secs > 0 {
+			r.cooldown = time.Duration(secs) * time.Second
+		}
+	}
+	return r
+}
+
+// SetRegistry makes RouteMessage ask registry whether a node is active
+// instead of reading Node.IsActive; nil restores the field check.
+func (r *Routing) SetRegistry(registry *NodeRegistry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registry = registry
+}
+
+// isActive reports whether node may be routed to.
+func (r *Routing) isActive(node *Node) bool {
+	r.mu.Lock()
+	registry := r.registry
+	r.mu.Unlock()
+	if registry != nil {
+		return registry.IsActive(node)
+	}
+	return node.Active()
+}
+
+// SetWeight sets nodeID's share of RouteBalanced traffic relative to other
+// nodes. Nodes default to weight 1; weight <= 0 resets to the default.
+func (r *Routing) SetWeight(nodeID string, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stateLocked(nodeID).weight = weight
+}
+
+// SetHealthPolicy takes a node out of rotation after threshold consecutive
+// failures, for cooldown. Non-positive values keep the current setting.
+func (r *Routing) SetHealthPolicy(threshold int, cooldown time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if threshold > 0 {
+		r.failureThreshold = threshold
+	}
+	if cooldown > 0 {
+		r.cooldown = cooldown
+	}
+}
+
+// ReportFailure counts a failed delivery to nodeID against its health, for
+// failures noticed after RouteBalanced returned.
+func (r *Routing) ReportFailure(nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failLocked(nodeID, time.Now())
+}
+
+// ReportSuccess clears nodeID's failure count.
+func (r *Routing) ReportSuccess(nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stateLocked(nodeID).failures = 0
+}
+
+// stateLocked returns nodeID's rotation state, creating it at weight 1.
+// Caller must hold r.mu.
+func (r *Routing) stateLocked(nodeID string) *routeState {
+	st, ok := r.nodes[nodeID]
+	if !ok {
+		st = &routeState{weight: 1}
+		r.nodes[nodeID] = st
+	}
+	return st
+}
+
+// failLocked records a failure and starts the cooldown when the threshold
+// is reached. Caller must hold r.mu.
+func (r *Routing) failLocked(nodeID string, now time.Time) {
+	st := r.stateLocked(nodeID)
+	st.failures++
+	if st.failures >= r.failureThreshold && !now.Before(st.downUntil) {
+		st.downUntil = now.Add(r.cooldown)
+		fmt.Printf("⚠️ Node[%s] out of routing rotation for %s after %d failures\n", nodeID, r.cooldown, st.failures)
+	}
+}
+
+// pickLocked chooses among candidates by smooth weighted round-robin,
+// skipping nodes in cooldown and those in tried. A node whose cooldown has
+// ended rejoins with a clean slate. Caller must hold r.mu.
+func (r *Routing) pickLocked(candidates []*Node, tried map[string]bool, now time.Time) *Node {
+	var best *Node
+	var bestState *routeState
+	total := 0
+	for _, node := range candidates {
+		if node == nil || tried[node.ID] {
+			continue
+		}
+		st := r.stateLocked(node.ID)
+		if now.Before(st.downUntil) {
+			continue
+		}
+		if !st.downUntil.IsZero() {
+			st.downUntil, st.failures, st.current = time.Time{}, 0, 0
+		}
+		st.current += st.weight
+		total += st.weight
+		if bestState == nil || st.current > bestState.current {
+			best, bestState = node, st
+		}
+	}
+	if bestState != nil {
+		bestState.current -= total
+	}
+	return best
+}
+
+// RouteBalanced routes message to one of nodes, chosen in proportion to
+// their weights among nodes not in cooldown. A node that can't be routed to
+// counts a failure and the next pick is tried. It returns the node used, or
+// nil when none was routable.
+func (r *Routing) RouteBalanced(nodes []*Node, message string) *Node {
+	tried := make(map[string]bool, len(nodes))
+	for {
+		r.mu.Lock()
+		node := r.pickLocked(nodes, tried, time.Now())
+		r.mu.Unlock()
+		if node == nil {
+			fmt.Printf("⚠️ Balanced routing found no healthy node\n")
+			return nil
+		}
+		tried[node.ID] = true
+		if r.RouteMessage(node, message) {
+			r.ReportSuccess(node.ID)
+			return node
+		}
+		r.ReportFailure(node.ID)
+	}
+}
+
+// SelectBalanced picks from nodes as RouteBalanced would and advances the
+// rotation, but routes nothing and doesn't check activity; callers pass
+// nodes they know to be live. It returns nil when all are in cooldown.
+func (r *Routing) SelectBalanced(nodes []*Node) *Node {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pickLocked(nodes, nil, time.Now())
+}
+
+// RouteByCapability is RouteBalanced over the nodes advertising capability.
+// It returns nil when none of them was routable.
+func (r *Routing) RouteByCapability(nodes []*Node, capability, message string) *Node {
+	candidates := WithCapability(nodes, capability)
+	if len(candidates) == 0 {
+		fmt.Printf("⚠️ No node advertises capability %q\n", capability)
+		return nil
 	}
+	return r.RouteBalanced(candidates, message)
 }
 
-// RouteMessage routes a message to an active target node and records the route history.
-func (r *Routing) RouteMessage(node *Node, message string) {
+// Rotation returns every node RouteBalanced has seen with its weight and
+// health, sorted by node ID.
+func (r *Routing) Rotation() []RotationEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	out := make([]RotationEntry, 0, len(r.nodes))
+	for id, st := range r.nodes {
+		entry := RotationEntry{NodeID: id, Weight: st.weight, Failures: st.failures, Healthy: !now.Before(st.downUntil)}
+		if !entry.Healthy {
+			until := st.downUntil
+			entry.DownUntil = &until
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// RouteMessage routes a message to an active target node and records the
+// route history. It reports whether the node was routable.
+func (r *Routing) RouteMessage(node *Node, message string) bool {
 	if node == nil {
 		fmt.Printf("⚠️ Routing skipped: node is nil\n")
-		return
+		return false
 	}
-	if !node.IsActive {
+	if !r.isActive(node) {
 		fmt.Printf("⚠️ Routing skipped: Node[%s] is inactive\n", node.ID)
-		return
+		return false
 	}
 
 	record := RouteRecord{
@@ -43,12 +270,25 @@ func (r *Routing) RouteMessage(node *Node, message string) {
 	}
 	r.mu.Lock()
 	r.history = append(r.history, record)
-	if len(r.history) > 5000 {
-		r.history = r.history[len(r.history)-5000:]
-	}
+	r.trimHistoryLocked()
 	r.mu.Unlock()
 
 	fmt.Printf("➡️ Routing message to Node[%s]: %s\n", node.ID, message)
+	return true
+}
+
+func (r *Routing) trimHistoryLocked() {
+	if len(r.history) > maxRouteHistory {
+		r.historyDropped += uint64(len(r.history) - maxRouteHistory)
+		r.history = r.history[len(r.history)-maxRouteHistory:]
+	}
+}
+
+// HistoryUsage reports how full the route history is.
+func (r *Routing) HistoryUsage() HistoryUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return newHistoryUsage(len(r.history), maxRouteHistory, r.historyDropped)
 }
 
 func (r *Routing) History(limit int) []RouteRecord {
@@ -64,3 +304,23 @@ func (r *Routing) History(limit int) []RouteRecord {
 	copy(out, r.history[start:])
 	return out
 }
+
+// ExportHistory writes the whole route history to w as a JSON array, oldest
+// first.
+func (r *Routing) ExportHistory(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.History(0))
+}
+
+// ImportHistory appends records from a JSON array written by ExportHistory.
+// Older records fall off if the result exceeds the history cap.
+func (r *Routing) ImportHistory(rd io.Reader) error {
+	var records []RouteRecord
+	if err := json.NewDecoder(rd).Decode(&records); err != nil {
+		return fmt.Errorf("decode route history: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = append(r.history, records...)
+	r.trimHistoryLocked()
+	return nil
+}
@@ -0,0 +1,99 @@
+// kernel/mesh/topology.go
+package mesh
+
+import (
+	"sort"
+	"time"
+)
+
+// TopologyVertex is one node in the mesh graph.
+type TopologyVertex struct {
+	ID           string     `json:"id"`
+	Address      string     `json:"address,omitempty"`
+	Active       bool       `json:"active"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
+	Capabilities []string   `json:"capabilities,omitempty"`
+	// Local marks the kernel itself, the source of every routed edge.
+	Local bool `json:"local,omitempty"`
+	// Registered is false for route targets no longer in the registry.
+	Registered bool `json:"registered"`
+}
+
+// TopologyEdge aggregates the routes recorded from one node to another.
+type TopologyEdge struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Topology is the mesh as a graph: registered nodes plus every node routed
+// to since the window start, and one edge per routed pair.
+type Topology struct {
+	Vertices []TopologyVertex `json:"vertices"`
+	Edges    []TopologyEdge   `json:"edges"`
+}
+
+// Topology builds the graph from the registry and the route history at or
+// after since (zero for all retained history). Routing only records
+// successful routes, each sent by this kernel, so edges run from localID
+// to the target. Vertices are sorted by ID and edges by most recent.
+func (m *MeshManager) Topology(localID string, since time.Time) Topology {
+	vertices := map[string]*TopologyVertex{
+		localID: {ID: localID, Active: true, Local: true, Registered: true},
+	}
+	for _, node := range m.Registry.Nodes() {
+		active := m.Registry.IsActive(node)
+		d := describeNode(node)
+		seen := d.LastSeen
+		vertices[d.ID] = &TopologyVertex{
+			ID:           d.ID,
+			Address:      d.Address,
+			Active:       active,
+			LastSeen:     &seen,
+			Capabilities: d.Capabilities,
+			Registered:   true,
+		}
+	}
+
+	// A registry entry for the kernel itself is still the local vertex.
+	vertices[localID].Local = true
+
+	edges := map[string]*TopologyEdge{}
+	for _, rec := range m.Routing.History(0) {
+		if rec.Timestamp.Before(since) {
+			continue
+		}
+		e, ok := edges[rec.NodeID]
+		if !ok {
+			e = &TopologyEdge{From: localID, To: rec.NodeID}
+			edges[rec.NodeID] = e
+		}
+		e.Count++
+		if rec.Timestamp.After(e.LastSeen) {
+			e.LastSeen = rec.Timestamp
+		}
+		if _, ok := vertices[rec.NodeID]; !ok {
+			vertices[rec.NodeID] = &TopologyVertex{ID: rec.NodeID}
+		}
+	}
+
+	out := Topology{
+		Vertices: make([]TopologyVertex, 0, len(vertices)),
+		Edges:    make([]TopologyEdge, 0, len(edges)),
+	}
+	for _, v := range vertices {
+		out.Vertices = append(out.Vertices, *v)
+	}
+	for _, e := range edges {
+		out.Edges = append(out.Edges, *e)
+	}
+	sort.Slice(out.Vertices, func(i, j int) bool { return out.Vertices[i].ID < out.Vertices[j].ID })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if !out.Edges[i].LastSeen.Equal(out.Edges[j].LastSeen) {
+			return out.Edges[i].LastSeen.After(out.Edges[j].LastSeen)
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	return out
+}
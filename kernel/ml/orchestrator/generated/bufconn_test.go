@@ -0,0 +1,145 @@
+package generated_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"neuroedge/kernel/core"
+	pb "neuroedge/kernel/ml/orchestrator/generated"
+)
+
+// rawCodec hands the server the bytes as they came off the wire, so the
+// fake orchestrator below decodes them independently of the client's codec.
+type rawCodec struct{}
+
+func (rawCodec) Name() string { return "proto" }
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+// stringFields decodes a message whose fields are all strings.
+func stringFields(t *testing.T, b []byte) map[protowire.Number]string {
+	t.Helper()
+	out := map[protowire.Number]string{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || typ != protowire.BytesType {
+			t.Errorf("unexpected field %d type %d", num, typ)
+			return out
+		}
+		b = b[n:]
+		s, n := protowire.ConsumeString(b)
+		if n < 0 {
+			t.Errorf("bad string in field %d", num)
+			return out
+		}
+		out[num] = s
+		b = b[n:]
+	}
+	return out
+}
+
+func appendStrings(fields ...string) []byte {
+	var b []byte
+	for i, s := range fields {
+		b = protowire.AppendTag(b, protowire.Number(i+1), protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+// startOrchestrator serves the orchestrator.Orchestrator service on an
+// in-memory listener and returns a connection to it and the number of
+// SubmitTask calls it has answered.
+func startOrchestrator(t *testing.T) (*grpc.ClientConn, *int32) {
+	t.Helper()
+	var submits int32
+	unary := func(handle func(in map[protowire.Number]string) []byte) grpc.MethodHandler {
+		return func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+			var in []byte
+			if err := dec(&in); err != nil {
+				return nil, err
+			}
+			out := handle(stringFields(t, in))
+			return &out, nil
+		}
+	}
+	desc := grpc.ServiceDesc{
+		ServiceName: "orchestrator.Orchestrator",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "SubmitTask", Handler: unary(func(in map[protowire.Number]string) []byte {
+				atomic.AddInt32(&submits, 1)
+				output := fmt.Sprintf(`{"engine":%q,"input":%s}`, in[1], in[3])
+				return appendStrings(in[2], output, "success")
+			})},
+			{MethodName: "CancelTask", Handler: unary(func(in map[protowire.Number]string) []byte {
+				status := "not_found"
+				if in[1] == "running" {
+					status = "cancelled"
+				}
+				return appendStrings(in[1], status)
+			})},
+		},
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}))
+	srv.RegisterService(&desc, struct{}{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, &submits
+}
+
+func TestPythonClientUsesGRPC(t *testing.T) {
+	conn, submits := startOrchestrator(t)
+	pc := core.NewPythonClientFromConn(conn)
+	defer pc.Close()
+	if pc.Mode() != core.ModeGRPC {
+		t.Fatalf("mode = %s, want %s", pc.Mode(), core.ModeGRPC)
+	}
+
+	resp, err := pc.SubmitTask(context.Background(), &pb.TaskRequest{EngineName: "chat", TaskId: "t-1", InputData: `{"message":"hi"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Over HTTP this address can't be reached, and an unreachable HTTP
+	// orchestrator yields a "failed" task rather than the server's output.
+	want := pb.TaskResponse{TaskId: "t-1", Status: "success", OutputData: `{"engine":"chat","input":{"message":"hi"}}`}
+	if *resp != want {
+		t.Fatalf("response = %+v, want %+v", *resp, want)
+	}
+	if n := atomic.LoadInt32(submits); n != 1 {
+		t.Fatalf("server saw %d SubmitTask calls, want 1", n)
+	}
+
+	if err := pc.CancelTask(context.Background(), "running"); err != nil {
+		t.Fatalf("CancelTask(running) = %v", err)
+	}
+	if err := pc.CancelTask(context.Background(), "gone"); !errors.Is(err, core.ErrTaskNotCancellable) {
+		t.Fatalf("CancelTask(gone) = %v, want ErrTaskNotCancellable", err)
+	}
+}
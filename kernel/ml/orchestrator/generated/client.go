@@ -7,3 +7,8 @@ import "context"
 type OrchestratorClient interface {
 	SubmitTask(ctx context.Context, req *TaskRequest) (*TaskResponse, error)
 }
+
+// TaskCancelClient defines the interface for cancelling submitted tasks
+type TaskCancelClient interface {
+	CancelTask(ctx context.Context, req *CancelTaskRequest) (*CancelTaskResponse, error)
+}
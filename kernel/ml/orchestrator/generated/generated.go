@@ -14,3 +14,14 @@ type TaskResponse struct {
 	Status     string
 	OutputData string
 }
+
+// CancelTaskRequest asks the orchestrator to stop a submitted task
+type CancelTaskRequest struct {
+	TaskId string
+}
+
+// CancelTaskResponse reports the outcome of a cancellation
+type CancelTaskResponse struct {
+	TaskId string
+	Status string
+}
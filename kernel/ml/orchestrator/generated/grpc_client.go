@@ -0,0 +1,132 @@
+// kernel/ml/orchestrator/generated/grpc_client.go
+package generated
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// SubmitTaskMethod is the full gRPC method name from
+// ml/orchestrator/grpc_server/orchestrator.proto.
+const SubmitTaskMethod = "/orchestrator.Orchestrator/SubmitTask"
+
+// CancelTaskMethod is the full gRPC method name of the cancellation RPC.
+const CancelTaskMethod = "/orchestrator.Orchestrator/CancelTask"
+
+type grpcOrchestratorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewOrchestratorClient returns an OrchestratorClient that calls the Python
+// orchestrator's gRPC service over cc.
+func NewOrchestratorClient(cc grpc.ClientConnInterface) OrchestratorClient {
+	return &grpcOrchestratorClient{cc: cc}
+}
+
+// NewTaskCancelClient returns a TaskCancelClient over cc.
+func NewTaskCancelClient(cc grpc.ClientConnInterface) TaskCancelClient {
+	return &grpcOrchestratorClient{cc: cc}
+}
+
+func (c *grpcOrchestratorClient) CancelTask(ctx context.Context, req *CancelTaskRequest) (*CancelTaskResponse, error) {
+	out := new(CancelTaskResponse)
+	if err := c.cc.Invoke(ctx, CancelTaskMethod, req, out, grpc.ForceCodec(wireCodec{})); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *grpcOrchestratorClient) SubmitTask(ctx context.Context, req *TaskRequest) (*TaskResponse, error) {
+	out := new(TaskResponse)
+	if err := c.cc.Invoke(ctx, SubmitTaskMethod, req, out, grpc.ForceCodec(wireCodec{})); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// wireCodec encodes the orchestrator messages in protobuf wire format, field
+// numbers matching orchestrator.proto, so the plain structs in this package
+// interoperate with the Python server.
+type wireCodec struct{}
+
+func (wireCodec) Name() string {
+	return "proto"
+}
+
+func (wireCodec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case *TaskRequest:
+		var b []byte
+		b = appendString(b, 1, m.EngineName)
+		b = appendString(b, 2, m.TaskId)
+		b = appendString(b, 3, m.InputData)
+		return b, nil
+	case *TaskResponse:
+		var b []byte
+		b = appendString(b, 1, m.TaskId)
+		b = appendString(b, 2, m.OutputData)
+		b = appendString(b, 3, m.Status)
+		return b, nil
+	case *CancelTaskRequest:
+		return appendString(nil, 1, m.TaskId), nil
+	case *CancelTaskResponse:
+		var b []byte
+		b = appendString(b, 1, m.TaskId)
+		b = appendString(b, 2, m.Status)
+		return b, nil
+	default:
+		return nil, fmt.Errorf("wireCodec: unsupported type %T", v)
+	}
+}
+
+func (wireCodec) Unmarshal(data []byte, v any) error {
+	var fields map[protowire.Number]*string
+	switch m := v.(type) {
+	case *TaskRequest:
+		fields = map[protowire.Number]*string{1: &m.EngineName, 2: &m.TaskId, 3: &m.InputData}
+	case *TaskResponse:
+		fields = map[protowire.Number]*string{1: &m.TaskId, 2: &m.OutputData, 3: &m.Status}
+	case *CancelTaskRequest:
+		fields = map[protowire.Number]*string{1: &m.TaskId}
+	case *CancelTaskResponse:
+		fields = map[protowire.Number]*string{1: &m.TaskId, 2: &m.Status}
+	default:
+		return fmt.Errorf("wireCodec: unsupported type %T", v)
+	}
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if dst, ok := fields[num]; ok && typ == protowire.BytesType {
+			s, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			*dst = s
+			data = data[n:]
+			continue
+		}
+		// Skip fields this client does not know about.
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// appendString writes a proto3 string field, omitting the default empty value.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
@@ -0,0 +1,104 @@
+package generated
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestWireCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		in, into any
+	}{
+		{name: "task request", in: &TaskRequest{EngineName: "vision", TaskId: "t-1", InputData: `{"x":1}`}, into: &TaskRequest{}},
+		{name: "task response", in: &TaskResponse{TaskId: "t-1", Status: "done", OutputData: "ünïcode"}, into: &TaskResponse{}},
+		{name: "cancel request", in: &CancelTaskRequest{TaskId: "t-2"}, into: &CancelTaskRequest{}},
+		{name: "cancel response", in: &CancelTaskResponse{TaskId: "t-2", Status: "cancelled"}, into: &CancelTaskResponse{}},
+		{name: "empty fields omitted", in: &TaskResponse{TaskId: "t-3"}, into: &TaskResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := wireCodec{}.Marshal(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := (wireCodec{}).Unmarshal(b, tt.into); err != nil {
+				t.Fatal(err)
+			}
+			if !equalMessage(tt.in, tt.into) {
+				t.Fatalf("round trip = %+v, want %+v", tt.into, tt.in)
+			}
+		})
+	}
+}
+
+func equalMessage(a, b any) bool {
+	switch a := a.(type) {
+	case *TaskRequest:
+		return *a == *b.(*TaskRequest)
+	case *TaskResponse:
+		return *a == *b.(*TaskResponse)
+	case *CancelTaskRequest:
+		return *a == *b.(*CancelTaskRequest)
+	case *CancelTaskResponse:
+		return *a == *b.(*CancelTaskResponse)
+	}
+	return false
+}
+
+// A newer server may add fields; the client must skip them rather than fail.
+func TestWireCodecSkipsUnknownFields(t *testing.T) {
+	var b []byte
+	b = protowire.AppendTag(b, 9, protowire.VarintType)
+	b = protowire.AppendVarint(b, 42)
+	b = appendString(b, 1, "t-1")
+	b = protowire.AppendTag(b, 10, protowire.BytesType)
+	b = protowire.AppendString(b, "extra")
+	b = protowire.AppendTag(b, 11, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, 7)
+	b = appendString(b, 3, "done")
+	// A known field number with an unexpected wire type is skipped too.
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+
+	var got TaskResponse
+	if err := (wireCodec{}).Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (TaskResponse{TaskId: "t-1", Status: "done"}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWireCodecRejectsTruncatedInput(t *testing.T) {
+	full, err := wireCodec{}.Marshal(&TaskRequest{EngineName: "vision", TaskId: "t-1", InputData: "payload"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cutting inside a tag or a string value must fail; a cut on a field
+	// boundary is a valid shorter message.
+	boundaries := map[int]bool{0: true, 8: true, 13: true, len(full): true}
+	for i := 1; i < len(full); i++ {
+		var m TaskRequest
+		err := wireCodec{}.Unmarshal(full[:i], &m)
+		if boundaries[i] {
+			if err != nil {
+				t.Fatalf("cut at field boundary %d: %v", i, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Fatalf("truncated at %d of %d bytes decoded without error: %+v", i, len(full), m)
+		}
+	}
+}
+
+func TestWireCodecUnsupportedType(t *testing.T) {
+	if _, err := (wireCodec{}).Marshal("nope"); err == nil {
+		t.Fatal("Marshal accepted an unsupported type")
+	}
+	if err := (wireCodec{}).Unmarshal(nil, new(int)); err == nil {
+		t.Fatal("Unmarshal accepted an unsupported type")
+	}
+}
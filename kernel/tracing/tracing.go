@@ -0,0 +1,112 @@
+// kernel/tracing/tracing.go
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "neuroedge/kernel"
+
+// Init installs the W3C trace-context propagator and, when OTEL_TRACES_EXPORTER
+// selects one, a tracer provider. The default ("none") leaves the global no-op
+// provider in place, so spans cost nothing. Service name, resource attributes
+// and sampling follow the standard OTEL_* variables read by the SDK.
+//
+// Supported exporters: none, console (JSON lines on stderr). Any other value,
+// including otlp, is an error rather than a silent fallback, so a deployment
+// that expects traces doesn't start without them.
+func Init() (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	noop := func(context.Context) error { return nil }
+	exporter := strings.ToLower(strings.TrimSpace(os.Getenv("OTEL_TRACES_EXPORTER")))
+	switch exporter {
+	case "", "none":
+		return noop, nil
+	case "console":
+		tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(newConsoleExporter(os.Stderr)))
+		otel.SetTracerProvider(tp)
+		return tp.Shutdown, nil
+	default:
+		return noop, fmt.Errorf("OTEL_TRACES_EXPORTER=%s is not supported by this build (use none or console)", exporter)
+	}
+}
+
+// Tracer returns the kernel tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// consoleExporter writes finished spans as JSON lines.
+type consoleExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newConsoleExporter(w io.Writer) *consoleExporter {
+	return &consoleExporter{w: w}
+}
+
+type consoleSpan struct {
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"`
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	ParentID   string            `json:"parent_span_id,omitempty"`
+	Start      string            `json:"start"`
+	DurationMS float64           `json:"duration_ms"`
+	Status     string            `json:"status"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func (e *consoleExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range spans {
+		out := consoleSpan{
+			Name:       s.Name(),
+			Kind:       s.SpanKind().String(),
+			TraceID:    s.SpanContext().TraceID().String(),
+			SpanID:     s.SpanContext().SpanID().String(),
+			Start:      s.StartTime().UTC().Format(time.RFC3339Nano),
+			DurationMS: float64(s.EndTime().Sub(s.StartTime()).Microseconds()) / 1000,
+			Status:     s.Status().Code.String(),
+		}
+		if s.Parent().IsValid() {
+			out.ParentID = s.Parent().SpanID().String()
+		}
+		if attrs := s.Attributes(); len(attrs) > 0 {
+			out.Attributes = make(map[string]string, len(attrs))
+			for _, kv := range attrs {
+				out.Attributes[string(kv.Key)] = kv.Value.Emit()
+			}
+		}
+		line, err := json.Marshal(out)
+		if err != nil {
+			return fmt.Errorf("encode span: %w", err)
+		}
+		if _, err := e.w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *consoleExporter) Shutdown(context.Context) error {
+	return nil
+}
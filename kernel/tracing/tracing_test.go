@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInitExporters(t *testing.T) {
+	tests := []struct {
+		exporter string
+		wantErr  bool
+	}{
+		{exporter: "", wantErr: false},
+		{exporter: "none", wantErr: false},
+		{exporter: " NONE ", wantErr: false},
+		{exporter: "otlp", wantErr: true},
+		{exporter: "jaeger", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exporter, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_EXPORTER", tt.exporter)
+			shutdown, err := Init()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), strings.TrimSpace(tt.exporter)) {
+				t.Fatalf("error %q does not name the exporter", err)
+			}
+			if shutdown == nil {
+				t.Fatal("Init() returned a nil shutdown func")
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+		})
+	}
+}
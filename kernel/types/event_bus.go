@@ -3,51 +3,387 @@ package types
 
 import (
 	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
 )
 
 // Event represents a single message or event in the system
 type Event struct {
+	// ID identifies the event when its publisher assigned one, as
+	// EventIngestHandler does for bridge events. Empty otherwise.
+	ID     string
 	Name   string
 	Data   interface{}
 	Source string
+	// CorrelationID ties replies to the event that asked for them; see
+	// PublishAndCollect. Empty for ordinary events.
+	CorrelationID string
 }
 
 // Subscriber function type
 type Subscriber func(Event)
 
-// EventBus handles message passing between agents & core
+// Subscription identifies one registered handler; pass it to Unsubscribe.
+type Subscription uint64
+
+// OverflowPolicy decides what an async bus does when a subscriber's queue
+// is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards the event for that subscriber only.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock makes Publish wait for room in the queue.
+	OverflowBlock
+)
+
+type subscriberEntry struct {
+	id    Subscription
+	topic string
+	fn    Subscriber
+	queue *subscriberQueue
+}
+
+// subscriberQueue is the bounded queue an async bus keeps per subscriber,
+// drained by its worker goroutines; see SubscribeOptions.
+type subscriberQueue struct {
+	mu     sync.RWMutex
+	closed bool
+	// chans holds one channel shared by every worker, or one channel per
+	// worker when events are partitioned by key.
+	chans   []chan Event
+	key     func(Event) string
+	workers int
+}
+
+// EventBus handles message passing between agents & core.
+//
+// Subscriptions match event names exactly, except that a name ending in "*"
+// matches every event starting with the text before it: "compute:optimize:*"
+// receives "compute:optimize:us-east" and "compute:optimize:eu:1", and "*"
+// receives everything. "*" anywhere else is an ordinary character.
 type EventBus struct {
-	subscribers map[string][]Subscriber
+	subscribers map[string][]subscriberEntry
+	prefixes    map[string][]subscriberEntry
+	nextID      Subscription
 	mu          sync.RWMutex
+
+	// bufferSize > 0 selects queued delivery; see NewAsyncEventBus.
+	bufferSize int
+	overflow   OverflowPolicy
+	pending    sync.WaitGroup
+
+	onPanic func(topic string, rec interface{})
+
+	// historySize > 0 keeps that many recent events per topic for
+	// SubscribeWithReplay.
+	historySize int
+	history     map[string][]retainedEvent
+	historySeq  uint64
+}
+
+type retainedEvent struct {
+	seq   uint64
+	event Event
 }
 
 // NewEventBus creates a new event bus
 func NewEventBus() *EventBus {
 	return &EventBus{
-		subscribers: make(map[string][]Subscriber),
+		subscribers: make(map[string][]subscriberEntry),
+		prefixes:    make(map[string][]subscriberEntry),
+	}
+}
+
+// NewAsyncEventBus creates a bus that gives each subscriber its own queue of
+// bufferSize events and a worker goroutine, so Publish never waits on a
+// handler. Full queues drop events unless SetOverflowPolicy says otherwise.
+func NewAsyncEventBus(bufferSize int) *EventBus {
+	if bufferSize <= 0 {
+		bufferSize = 1
 	}
+	eb := NewEventBus()
+	eb.bufferSize = bufferSize
+	return eb
+}
+
+// SetOverflowPolicy sets how an async bus handles full subscriber queues.
+func (eb *EventBus) SetOverflowPolicy(policy OverflowPolicy) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.overflow = policy
+}
+
+// SetErrorHandler registers a hook called with the topic and recovered value
+// whenever a subscriber panics, e.g. to count failures in metrics.
+func (eb *EventBus) SetErrorHandler(fn func(topic string, rec interface{})) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.onPanic = fn
 }
 
-// Subscribe adds a new subscriber to an event
-func (eb *EventBus) Subscribe(eventName string, subscriber Subscriber) {
+// SetHistorySize keeps the last n events of every topic for replay to
+// SubscribeWithReplay subscribers. Zero, the default, disables history.
+func (eb *EventBus) SetHistorySize(n int) {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	eb.historySize = n
+	if n == 0 {
+		eb.history = nil
+		return
+	}
+	if eb.history == nil {
+		eb.history = make(map[string][]retainedEvent)
+	}
+	for topic, events := range eb.history {
+		if len(events) > n {
+			eb.history[topic] = append([]retainedEvent(nil), events[len(events)-n:]...)
+		}
+	}
+}
+
+// SubscribeWithReplay subscribes like Subscribe, then hands the subscriber
+// the retained events for every matching topic, oldest first, before any
+// live event reaches it. The replay runs before SubscribeWithReplay returns.
+func (eb *EventBus) SubscribeWithReplay(eventName string, subscriber Subscriber) Subscription {
+	ready := make(chan struct{})
+	gated := func(event Event) {
+		<-ready
+		subscriber(event)
+	}
+
+	eb.mu.Lock()
+	id := eb.subscribeLocked(eventName, gated, SubscribeOptions{})
+	var replay []retainedEvent
+	prefix, wildcard := strings.CutSuffix(eventName, "*")
+	for topic, events := range eb.history {
+		if topic == eventName || (wildcard && strings.HasPrefix(topic, prefix)) {
+			replay = append(replay, events...)
+		}
+	}
+	eb.mu.Unlock()
+
+	sort.Slice(replay, func(i, j int) bool { return replay[i].seq < replay[j].seq })
+	for _, r := range replay {
+		eb.safeDeliver(subscriber, r.event)
+	}
+	close(ready)
+	return id
+}
 
-	eb.subscribers[eventName] = append(eb.subscribers[eventName], subscriber)
+// Drain waits until every queued event has been handled. Stop publishing
+// before calling it during shutdown.
+func (eb *EventBus) Drain() {
+	eb.pending.Wait()
+}
+
+// Subscribe adds a new subscriber to an event, or to every event with a
+// given prefix when eventName ends in "*", and returns its handle
+func (eb *EventBus) Subscribe(eventName string, subscriber Subscriber) Subscription {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return eb.subscribeLocked(eventName, subscriber, SubscribeOptions{})
+}
+
+// subscribeLocked registers subscriber. Caller must hold the write lock.
+func (eb *EventBus) subscribeLocked(eventName string, subscriber Subscriber, opts SubscribeOptions) Subscription {
+	eb.nextID++
+	entry := subscriberEntry{id: eb.nextID, topic: eventName, fn: subscriber}
+	if eb.bufferSize > 0 {
+		entry.queue = newSubscriberQueue(eb.bufferSize, opts)
+		for i := 0; i < entry.queue.workers; i++ {
+			go eb.runQueue(entry, entry.queue.chans[i%len(entry.queue.chans)])
+		}
+	}
+	if prefix, ok := strings.CutSuffix(eventName, "*"); ok {
+		eb.prefixes[prefix] = append(eb.prefixes[prefix], entry)
+	} else {
+		eb.subscribers[eventName] = append(eb.subscribers[eventName], entry)
+	}
 	fmt.Println("[EventBus] Subscriber added to:", eventName)
+	return entry.id
+}
+
+// Unsubscribe removes exactly the handler registered under sub. The slice is
+// replaced rather than edited in place, so a Publish already iterating the
+// old one neither skips nor repeats other handlers.
+func (eb *EventBus) Unsubscribe(sub Subscription) {
+	eb.mu.Lock()
+	entry, ok := removeSubscriber(eb.subscribers, sub)
+	if !ok {
+		entry, ok = removeSubscriber(eb.prefixes, sub)
+	}
+	eb.mu.Unlock()
+
+	if ok && entry.queue != nil {
+		// Closed outside eb.mu: a blocked Publish holds the queue lock and
+		// needs the worker, which may itself be publishing, to make room.
+		// The workers still handle what was queued before they exit.
+		entry.queue.mu.Lock()
+		entry.queue.closed = true
+		for _, ch := range entry.queue.chans {
+			close(ch)
+		}
+		entry.queue.mu.Unlock()
+	}
 }
 
-// Publish sends an event to all subscribers
-func (eb *EventBus) Publish(event Event) {
+// Subscribed reports whether sub is still registered, so a subscriber can
+// check that its handlers are live.
+func (eb *EventBus) Subscribed(sub Subscription) bool {
 	eb.mu.RLock()
 	defer eb.mu.RUnlock()
+	for _, index := range []map[string][]subscriberEntry{eb.subscribers, eb.prefixes} {
+		for _, subs := range index {
+			for _, entry := range subs {
+				if entry.id == sub {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
 
-	if subs, ok := eb.subscribers[event.Name]; ok {
-		for _, sub := range subs {
-			go sub(event) // async delivery
+// removeSubscriber drops sub from whichever key holds it and returns the
+// removed entry. Caller must hold the write lock.
+func removeSubscriber(index map[string][]subscriberEntry, sub Subscription) (subscriberEntry, bool) {
+	for name, subs := range index {
+		for i, entry := range subs {
+			if entry.id != sub {
+				continue
+			}
+			kept := make([]subscriberEntry, 0, len(subs)-1)
+			kept = append(kept, subs[:i]...)
+			kept = append(kept, subs[i+1:]...)
+			if len(kept) == 0 {
+				delete(index, name)
+			} else {
+				index[name] = kept
+			}
+			return entry, true
+		}
+	}
+	return subscriberEntry{}, false
+}
+
+// Publish sends an event to all subscribers and returns how many it was
+// handed to. Delivery is asynchronous, so the count says a subscriber will
+// see the event, not that it has handled it; subscribers whose queue was
+// full and dropped it are not counted. Zero means nobody is listening.
+func (eb *EventBus) Publish(event Event) int {
+	eb.mu.RLock()
+	if eb.historySize > 0 {
+		// Recording and matching happen under one write lock so a concurrent
+		// SubscribeWithReplay sees each event either in history or live.
+		eb.mu.RUnlock()
+		eb.mu.Lock()
+		eb.retainLocked(event)
+		subs, overflow := eb.matchLocked(event.Name), eb.overflow
+		eb.mu.Unlock()
+		return eb.deliver(subs, event, overflow)
+	}
+	subs, overflow := eb.matchLocked(event.Name), eb.overflow
+	eb.mu.RUnlock()
+	return eb.deliver(subs, event, overflow)
+}
+
+// matchLocked returns the exact and prefix subscribers for name. Caller must
+// hold the lock.
+func (eb *EventBus) matchLocked(name string) []subscriberEntry {
+	subs := eb.subscribers[name]
+	for prefix, matched := range eb.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			subs = append(subs[:len(subs):len(subs)], matched...)
+		}
+	}
+	return subs
+}
+
+// retainLocked appends event to its topic's history ring. Caller must hold
+// the write lock.
+func (eb *EventBus) retainLocked(event Event) {
+	// Reply topics are single-use; keeping them would grow history forever.
+	if eb.historySize == 0 || strings.HasPrefix(event.Name, replyTopicPrefix) {
+		return
+	}
+	eb.historySeq++
+	events := append(eb.history[event.Name], retainedEvent{seq: eb.historySeq, event: event})
+	if len(events) > eb.historySize {
+		events = events[len(events)-eb.historySize:]
+	}
+	eb.history[event.Name] = events
+}
+
+func (eb *EventBus) deliver(subs []subscriberEntry, event Event, overflow OverflowPolicy) int {
+	delivered := 0
+	for _, sub := range subs {
+		if sub.queue == nil {
+			go eb.safeDeliver(sub.fn, event) // async delivery
+			delivered++
+			continue
+		}
+		if eb.enqueue(sub.queue, event, overflow) {
+			delivered++
 		}
 	}
 
 	fmt.Printf("[EventBus] Event published: %s from %s\n", event.Name, event.Source)
+	return delivered
+}
+
+// enqueue reports whether event made it into q.
+func (eb *EventBus) enqueue(q *subscriberQueue, event Event, overflow OverflowPolicy) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+	ch := q.pick(event)
+	eb.pending.Add(1)
+	if overflow == OverflowBlock {
+		ch <- event
+		return true
+	}
+	select {
+	case ch <- event:
+		return true
+	default:
+		eb.pending.Done()
+		log.Printf("[EventBus] Subscriber queue full, dropped: %s", event.Name)
+		return false
+	}
+}
+
+func (eb *EventBus) runQueue(entry subscriberEntry, ch <-chan Event) {
+	for event := range ch {
+		eb.safeDeliver(entry.fn, event)
+		eb.pending.Done()
+	}
+}
+
+// safeDeliver runs one handler, containing its panic so other subscribers
+// keep receiving events. The panic is logged with its stack and passed to
+// the SetErrorHandler hook.
+func (eb *EventBus) safeDeliver(fn Subscriber, event Event) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		log.Printf("[EventBus] Subscriber panic on %s from %s: %v\n%s", event.Name, event.Source, rec, debug.Stack())
+		eb.mu.RLock()
+		hook := eb.onPanic
+		eb.mu.RUnlock()
+		if hook != nil {
+			hook(event.Name, rec)
+		}
+	}()
+	fn(event)
 }
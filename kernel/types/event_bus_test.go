@@ -0,0 +1,102 @@
+package types
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+// recorder collects the event names a subscriber sees.
+type recorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *recorder) handle(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, e.Name)
+}
+
+func (r *recorder) got() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := append([]string(nil), r.names...)
+	sort.Strings(names)
+	return names
+}
+
+func TestEventBusMatching(t *testing.T) {
+	published := []string{"compute:optimize", "compute:optimize:us-east", "compute:optimize:eu:1", "compute:scale", "a*b", "ab"}
+	tests := []struct {
+		topic string
+		want  []string
+	}{
+		{topic: "compute:optimize", want: []string{"compute:optimize"}},
+		{topic: "compute:optimize:*", want: []string{"compute:optimize:eu:1", "compute:optimize:us-east"}},
+		{topic: "compute:*", want: []string{"compute:optimize", "compute:optimize:eu:1", "compute:optimize:us-east", "compute:scale"}},
+		{topic: "*", want: []string{"a*b", "ab", "compute:optimize", "compute:optimize:eu:1", "compute:optimize:us-east", "compute:scale"}},
+		// "*" is only a wildcard at the end.
+		{topic: "a*b", want: []string{"a*b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			eb := NewAsyncEventBus(16)
+			rec := &recorder{}
+			eb.Subscribe(tt.topic, rec.handle)
+			for _, name := range published {
+				eb.Publish(Event{Name: name})
+			}
+			eb.Drain()
+			if got := rec.got(); !equalStrings(got, tt.want) {
+				t.Fatalf("%q received %v, want %v", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	eb := NewAsyncEventBus(16)
+	exact, prefix, kept := &recorder{}, &recorder{}, &recorder{}
+	exactSub := eb.Subscribe("node:added", exact.handle)
+	prefixSub := eb.Subscribe("node:*", prefix.handle)
+	// Same topic as exactSub: only the handle decides which one goes.
+	keptSub := eb.Subscribe("node:added", kept.handle)
+
+	eb.Publish(Event{Name: "node:added"})
+	eb.Drain()
+	eb.Unsubscribe(exactSub)
+	eb.Unsubscribe(prefixSub)
+	if eb.Subscribed(exactSub) || eb.Subscribed(prefixSub) || !eb.Subscribed(keptSub) {
+		t.Fatal("Subscribed disagrees with Unsubscribe")
+	}
+	if n := eb.Publish(Event{Name: "node:added"}); n != 1 {
+		t.Fatalf("Publish reached %d subscribers, want 1", n)
+	}
+	eb.Drain()
+
+	if got := exact.got(); len(got) != 1 {
+		t.Fatalf("unsubscribed exact handler saw %v", got)
+	}
+	if got := prefix.got(); len(got) != 1 {
+		t.Fatalf("unsubscribed prefix handler saw %v", got)
+	}
+	if got := kept.got(); len(got) != 2 {
+		t.Fatalf("remaining handler saw %v, want both events", got)
+	}
+
+	// Unsubscribing twice is harmless.
+	eb.Unsubscribe(exactSub)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+// kernel/types/event_reply.go
+package types
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replyTopicPrefix starts every reply topic; see ReplyTopic.
+const replyTopicPrefix = "reply:"
+
+var correlationSeq uint64
+
+// ReplyTopic is the topic replies to the event with correlationID are
+// published on.
+func ReplyTopic(correlationID string) string {
+	return replyTopicPrefix + correlationID
+}
+
+// Reply answers request, an event published through PublishAndCollect, by
+// publishing data on its reply topic. Events without a CorrelationID expect
+// no answer, so Reply ignores them and returns false.
+func (eb *EventBus) Reply(request Event, source string, data interface{}) bool {
+	if request.CorrelationID == "" {
+		return false
+	}
+	eb.Publish(Event{
+		Name:          ReplyTopic(request.CorrelationID),
+		Data:          data,
+		Source:        source,
+		CorrelationID: request.CorrelationID,
+	})
+	return true
+}
+
+// PublishAndCollect publishes event and gathers the replies subscribers
+// send back with Reply until timeout elapses, then returns them in arrival
+// order. The wait always lasts the full timeout, because the bus cannot
+// know how many subscribers will answer; a subscriber that answers late or
+// not at all simply leaves its reply out, so callers must treat the result
+// as partial and may get an empty slice. Replies arriving after the
+// timeout are discarded.
+//
+// event.CorrelationID is filled in when empty. The temporary reply
+// subscription is registered before publishing and removed before
+// returning.
+func (eb *EventBus) PublishAndCollect(event Event, timeout time.Duration) []Event {
+	if event.CorrelationID == "" {
+		event.CorrelationID = fmt.Sprintf("corr-%d-%d", time.Now().UnixNano(), atomic.AddUint64(&correlationSeq, 1))
+	}
+
+	var (
+		mu      sync.Mutex
+		done    bool
+		replies []Event
+	)
+	sub := eb.Subscribe(ReplyTopic(event.CorrelationID), func(reply Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !done {
+			replies = append(replies, reply)
+		}
+	})
+	eb.Publish(event)
+
+	time.Sleep(timeout)
+	eb.Unsubscribe(sub)
+
+	mu.Lock()
+	defer mu.Unlock()
+	done = true
+	return append([]Event{}, replies...)
+}
@@ -0,0 +1,94 @@
+// kernel/types/event_workers.go
+package types
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// SubscribeOptions tunes how an async bus delivers to one subscriber. On a
+// synchronous bus every event already runs on its own goroutine, so the
+// options have no effect there.
+type SubscribeOptions struct {
+	// Workers is how many events the subscriber may handle at once. Zero or
+	// one keeps the single worker, which handles events in publish order.
+	// With more workers and no Key, events are handled in any order.
+	Workers int
+	// Key, used with Workers > 1, sends events with the same key to the
+	// same worker, so those are handled in publish order while different
+	// keys run in parallel. Each worker then gets an equal share of the
+	// bus's buffer size, so one hot key can fill its share while others
+	// still have room.
+	Key func(Event) string
+}
+
+// SubscribeWithOptions is Subscribe with per-subscription worker settings;
+// see SubscribeOptions.
+func (eb *EventBus) SubscribeWithOptions(eventName string, opts SubscribeOptions, subscriber Subscriber) Subscription {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return eb.subscribeLocked(eventName, subscriber, opts)
+}
+
+func newSubscriberQueue(bufferSize int, opts SubscribeOptions) *subscriberQueue {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	q := &subscriberQueue{workers: workers}
+	if opts.Key == nil || workers == 1 {
+		q.chans = []chan Event{make(chan Event, bufferSize)}
+		return q
+	}
+	q.key = opts.Key
+	share := (bufferSize + workers - 1) / workers
+	for i := 0; i < workers; i++ {
+		q.chans = append(q.chans, make(chan Event, share))
+	}
+	return q
+}
+
+// pick returns the channel event goes to: its key's partition when keyed.
+func (q *subscriberQueue) pick(event Event) chan Event {
+	if len(q.chans) == 1 {
+		return q.chans[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(q.key(event)))
+	return q.chans[h.Sum32()%uint32(len(q.chans))]
+}
+
+// SubscriptionQueue is the backlog of one subscriber on an async bus.
+type SubscriptionQueue struct {
+	ID       Subscription `json:"id"`
+	Topic    string       `json:"topic"`
+	Workers  int          `json:"workers"`
+	Depth    int          `json:"depth"`
+	Capacity int          `json:"capacity"`
+}
+
+// QueueDepths reports how many events wait in each subscriber's queue,
+// ordered by subscription. A synchronous bus has no queues and returns
+// none.
+func (eb *EventBus) QueueDepths() []SubscriptionQueue {
+	eb.mu.RLock()
+	var out []SubscriptionQueue
+	for _, index := range []map[string][]subscriberEntry{eb.subscribers, eb.prefixes} {
+		for _, subs := range index {
+			for _, entry := range subs {
+				if entry.queue == nil {
+					continue
+				}
+				sq := SubscriptionQueue{ID: entry.id, Topic: entry.topic, Workers: entry.queue.workers}
+				for _, ch := range entry.queue.chans {
+					sq.Depth += len(ch)
+					sq.Capacity += cap(ch)
+				}
+				out = append(out, sq)
+			}
+		}
+	}
+	eb.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
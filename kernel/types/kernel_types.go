@@ -4,18 +4,39 @@ import "time"
 
 type KernelHealth struct {
 	Component string    `json:"component"`
+	Status    string    `json:"status"`
 	Healthy   bool      `json:"healthy"`
 	LastCheck time.Time `json:"last_check"`
-	Error     string    `json:"error,omitempty"`
+	// LastDurationMs is how long the most recent check took.
+	LastDurationMs float64 `json:"last_duration_ms"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// KernelHealthSummary is the /kernel/health?fields=summary body: the
+// worst-of rollup, whether the kernel is serving, and which components are
+// unhealthy.
+type KernelHealthSummary struct {
+	Status    string   `json:"status"`
+	Healthy   bool     `json:"healthy"`
+	Unhealthy []string `json:"unhealthy"`
 }
 
 type KernelNode struct {
-	ID        string `json:"id"`
-	Role      string `json:"role"` // kernel | agent | engine
-	Name      string `json:"name"`
+	ID     string `json:"id"`
+	Role   string `json:"role"` // kernel | agent | engine | node
+	Name   string `json:"name"`
+	Status string `json:"status"` // active | inactive
+
+	// Set for self-registered mesh nodes only.
+	Address      string     `json:"address,omitempty"`
+	Capabilities []string   `json:"capabilities,omitempty"`
+	LastSeen     *time.Time `json:"last_seen,omitempty"`
 }
 
 type KernelCapabilities struct {
 	Agents  []string `json:"agents"`
 	Engines []string `json:"engines"`
+	// Nodes maps each capability of an active self-registered node to the
+	// IDs of the nodes advertising it.
+	Nodes map[string][]string `json:"nodes,omitempty"`
 }
@@ -0,0 +1,31 @@
+// kernel/types/typed_events.go
+package types
+
+import (
+	"log"
+	"reflect"
+)
+
+// TypedSubscribe subscribes fn to eventName (with the same "prefix*"
+// matching as Subscribe) and hands it each event's Data as a T. Events whose
+// Data is not a T, including nil Data, are logged and skipped instead of
+// panicking in a failed type assertion. The returned Subscription is
+// removed with bus.Unsubscribe as usual.
+func TypedSubscribe[T any](bus *EventBus, eventName string, fn func(T)) Subscription {
+	return bus.Subscribe(eventName, func(event Event) {
+		data, ok := event.Data.(T)
+		if !ok {
+			// Via reflect so interface types are named rather than "<nil>".
+			want := reflect.TypeOf((*T)(nil)).Elem()
+			log.Printf("[EventBus] Skipped %s from %s for %s subscriber: data is %T, want %v", event.Name, event.Source, eventName, event.Data, want)
+			return
+		}
+		fn(data)
+	})
+}
+
+// TypedPublish publishes data as eventName's Data, so the payload type is
+// checked where the event is produced and matches TypedSubscribe[T].
+func TypedPublish[T any](bus *EventBus, eventName, source string, data T) {
+	bus.Publish(Event{Name: eventName, Data: data, Source: source})
+}
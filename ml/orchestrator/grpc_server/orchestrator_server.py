@@ -1,21 +1,32 @@
+from collections import OrderedDict
 from concurrent import futures
 import grpc
 import json
+import threading
 import time
 
 from ml.orchestrator import orchestrator
 from generated import orchestrator_pb2, orchestrator_pb2_grpc
 
+# How many finished task IDs CancelTask remembers to answer "completed".
+FINISHED_TASKS_KEPT = 1024
+
 class OrchestratorServicer(orchestrator_pb2_grpc.OrchestratorServicer):
     def __init__(self, orchestrator_instance):
         self.orch = orchestrator_instance
+        self.lock = threading.Lock()
+        self.running = set()
+        self.cancelled = set()
+        self.finished = OrderedDict()
 
     def SubmitTask(self, request, context):
         engine_name = request.engine_name
         task_id = request.task_id
-        input_data = json.loads(request.input_data)
+        with self.lock:
+            self.running.add(task_id)
 
         try:
+            input_data = json.loads(request.input_data)
             # Run task synchronously in Python orchestrator
             result = self.orch.engines[engine_name].run(input_data)
             response = orchestrator_pb2.TaskResponse(
@@ -29,8 +40,31 @@ class OrchestratorServicer(orchestrator_pb2_grpc.OrchestratorServicer):
                 output_data=json.dumps({"error": str(e)}),
                 status="error"
             )
+
+        with self.lock:
+            self.running.discard(task_id)
+            self.finished[task_id] = True
+            while len(self.finished) > FINISHED_TASKS_KEPT:
+                self.finished.popitem(last=False)
+            if task_id in self.cancelled:
+                self.cancelled.discard(task_id)
+                response = orchestrator_pb2.TaskResponse(task_id=task_id, output_data="{}", status="cancelled")
         return response
 
+    def CancelTask(self, request, context):
+        # Engines run synchronously and can't be interrupted, so a running
+        # task is cancelled by discarding its result when it finishes.
+        task_id = request.task_id
+        with self.lock:
+            if task_id in self.running:
+                self.cancelled.add(task_id)
+                status = "cancelled"
+            elif task_id in self.finished:
+                status = "completed"
+            else:
+                status = "not_found"
+        return orchestrator_pb2.CancelTaskResponse(task_id=task_id, status=status)
+
 def serve():
     orch_instance = orchestrator.NeuroEdgeOrchestrator()
     server = grpc.server(futures.ThreadPoolExecutor(max_workers=10))
@@ -25,6 +25,7 @@ export interface KernelResponse {
 
 export interface KernelHealth {
   component: string;
+  status?: "healthy" | "unknown" | "degraded" | "unhealthy";
   healthy: boolean;
   lastCheck: string;
   error?: string;
@@ -73,7 +74,10 @@ export class KernelClient {
   /* -------------------- Health -------------------- */
   async getHealth(): Promise<KernelHealth[]> {
     try {
-      const resp = await this.client.get("/kernel/health");
+      // An unhealthy kernel answers 503 with the same body.
+      const resp = await this.client.get("/kernel/health", {
+        validateStatus: (status) => status === 200 || status === 503,
+      });
       return resp.data;
     } catch (err) {
       console.error("[KernelClient] Health check failed:", err);
@@ -100,7 +104,7 @@ export class KernelClient {
   async getNodes(): Promise<KernelNode[]> {
     try {
       const resp = await this.client.get("/kernel/nodes");
-      return resp.data;
+      return resp.data?.items ?? resp.data;
     } catch (err) {
       console.error("[KernelClient] Fetch nodes failed:", err);
       return [];
@@ -111,7 +115,7 @@ export class KernelClient {
   async getCapabilities(): Promise<KernelCapabilities> {
     try {
       const resp = await this.client.get("/kernel/capabilities");
-      return resp.data;
+      return resp.data?.items ?? resp.data;
     } catch (err) {
       console.error("[KernelClient] Fetch capabilities failed:", err);
       return { version: "unknown", capabilities: [], status: "offline", nodes: [] };